@@ -11,6 +11,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/krmcbride/mcp-grafana/internal/prompts"
 	"github.com/krmcbride/mcp-grafana/internal/resources"
 	"github.com/krmcbride/mcp-grafana/internal/tools"
@@ -59,6 +60,20 @@ func main() {
 		os.Exit(0)
 	}
 
+	grafana.SetVersion(version)
+
+	// Optionally check Grafana and prime caches before serving, so misconfiguration fails fast
+	warmupCtx, warmupCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	n, err := grafana.Warmup(warmupCtx)
+	warmupCancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Startup warmup failed: %v\n", err)
+		os.Exit(1)
+	}
+	if n > 0 {
+		fmt.Fprintf(os.Stderr, "Warmup found %d datasources\n", n)
+	}
+
 	// Initialize the MCP server
 	s := server.NewMCPServer(
 		serverName,
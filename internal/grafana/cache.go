@@ -0,0 +1,122 @@
+package grafana
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+)
+
+// cacheMaxEntries bounds each cache; the least recently used entry is evicted to make room.
+// Set with GRAFANA_CACHE_MAX_ENTRIES.
+var cacheMaxEntries = IntFromEnv("GRAFANA_CACHE_MAX_ENTRIES", 1000)
+
+// caches holds every cache created with NewCache, so ClearCaches can reach them all.
+var caches = struct {
+	mu     sync.Mutex
+	byName map[string]clearer
+}{byName: make(map[string]clearer)}
+
+// clearer is a cache that can be emptied.
+type clearer interface {
+	clear() int
+}
+
+// Cache is a concurrency-safe LRU cache holding at most GRAFANA_CACHE_MAX_ENTRIES entries.
+// Entries older than its TTL are treated as missing; a zero TTL keeps them until evicted or cleared.
+type Cache[V any] struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu    sync.Mutex
+	order *list.List // Front is the most recently used
+	byKey map[string]*list.Element
+}
+
+// cacheEntry is one cached value with the key it was stored under.
+type cacheEntry[V any] struct {
+	key      string
+	value    V
+	storedAt time.Time
+}
+
+// NewCache creates a cache and registers it under name for ClearCaches.
+func NewCache[V any](name string, ttl time.Duration) *Cache[V] {
+	c := &Cache[V]{
+		ttl:        ttl,
+		maxEntries: cacheMaxEntries,
+		order:      list.New(),
+		byKey:      make(map[string]*list.Element),
+	}
+	caches.mu.Lock()
+	caches.byName[name] = c
+	caches.mu.Unlock()
+	return c
+}
+
+// Get returns the value stored under key, unless it is missing or older than the TTL.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.byKey[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*cacheEntry[V])
+	if c.ttl > 0 && time.Since(entry.storedAt) >= c.ttl {
+		c.order.Remove(elem)
+		delete(c.byKey, key)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry if the cache is full.
+func (c *Cache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.byKey[key]; ok {
+		elem.Value = &cacheEntry[V]{key: key, value: value, storedAt: time.Now()}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.byKey[key] = c.order.PushFront(&cacheEntry[V]{key: key, value: value, storedAt: time.Now()})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.byKey, oldest.Value.(*cacheEntry[V]).key)
+	}
+}
+
+// clear empties the cache and returns how many entries it held.
+func (c *Cache[V]) clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.order.Len()
+	c.order.Init()
+	c.byKey = make(map[string]*list.Element)
+	return n
+}
+
+// ClearedCache reports how many entries ClearCaches dropped from one cache.
+type ClearedCache struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+}
+
+// ClearCaches empties every cache, so the next lookups go to Grafana and the datasources again.
+func ClearCaches() []ClearedCache {
+	caches.mu.Lock()
+	defer caches.mu.Unlock()
+
+	cleared := make([]ClearedCache, 0, len(caches.byName))
+	for name, c := range caches.byName {
+		cleared = append(cleared, ClearedCache{Name: name, Entries: c.clear()})
+	}
+	sort.Slice(cleared, func(i, j int) bool { return cleared[i].Name < cleared[j].Name })
+	return cleared
+}
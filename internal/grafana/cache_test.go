@@ -0,0 +1,60 @@
+package grafana
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCache[int]("test_lru", 0)
+	c.maxEntries = 2
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, ok := c.Get("a"); !ok { // a is now more recently used than b
+		t.Fatal("expected a to be cached")
+	}
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b, the least recently used entry, to be evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if got, ok := c.Get(key); !ok || got != want {
+			t.Errorf("Get(%q) = %d, %v; want %d, true", key, got, ok, want)
+		}
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := NewCache[string]("test_ttl", time.Minute)
+	c.Set("fresh", "x")
+	c.Set("stale", "y")
+	c.byKey["stale"].Value.(*cacheEntry[string]).storedAt = time.Now().Add(-2 * time.Minute)
+
+	if _, ok := c.Get("fresh"); !ok {
+		t.Error("expected the fresh entry to be cached")
+	}
+	if _, ok := c.Get("stale"); ok {
+		t.Error("expected the expired entry to be treated as missing")
+	}
+}
+
+func TestClearCaches(t *testing.T) {
+	c := NewCache[bool]("test_clear", 0)
+	c.Set("a", true)
+	c.Set("b", true)
+
+	var entries int
+	for _, cleared := range ClearCaches() {
+		if cleared.Name == "test_clear" {
+			entries = cleared.Entries
+		}
+	}
+	if entries != 2 {
+		t.Errorf("ClearCaches() reported %d entries for test_clear, want 2", entries)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected the cache to be empty after ClearCaches")
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // Uint64String unmarshals a JSON string into a uint64.
@@ -36,8 +36,10 @@ func (u *Uint64String) UnmarshalJSON(b []byte) error {
 
 // GetHTTPClientForGrafana creates an authenticated HTTP client for Grafana API calls.
 // It reads configuration from environment variables:
-//   - GRAFANA_URL: Base URL of the Grafana instance (e.g., http://localhost:3000)
+//   - GRAFANA_URL: Base URL of the Grafana instance (e.g., http://localhost:3000), including any
+//     subpath Grafana is served under (e.g., https://host/grafana)
 //   - GRAFANA_API_KEY: Service account token or API key for authentication
+//   - GRAFANA_API_KEY_FILE: Alternatively, a file holding the token, re-read so rotated tokens are picked up
 //
 // Returns:
 //   - An *http.Client configured with Bearer token authentication
@@ -45,8 +47,11 @@ func (u *Uint64String) UnmarshalJSON(b []byte) error {
 //   - An error if required environment variables are missing
 //
 // The returned client is configured with:
-//   - 30 second timeout
+//   - 30 second timeout (override with GRAFANA_TIMEOUT, e.g., "60s")
 //   - Bearer token authentication via custom transport
+//   - User-Agent mcp-grafana/<version> (override with GRAFANA_USER_AGENT)
+//   - Concurrent identical GET requests collapsed into a single upstream call
+//   - Requests counted by backend and status class (see Stats)
 //
 // Example usage:
 //
@@ -63,18 +68,23 @@ func GetHTTPClientForGrafana() (*http.Client, string, error) {
 		)
 	}
 
-	apiKey := os.Getenv("GRAFANA_API_KEY")
-	if apiKey == "" {
+	// Callers append absolute API paths like /api/..., so the base must be a bare scheme://host[/subpath]
+	u, err := url.Parse(grafanaURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
 		return nil, "", enhanceConfigError(
-			fmt.Errorf("GRAFANA_API_KEY environment variable not set"),
+			fmt.Errorf("GRAFANA_URL must be an absolute http(s) URL without a query string, got %q", grafanaURL),
 		)
 	}
 
+	if _, err := apiKeys.token(false); err != nil {
+		return nil, "", enhanceConfigError(err)
+	}
+
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout: defaultTimeout,
 		Transport: &bearerAuthTransport{
-			apiKey:    apiKey,
-			transport: http.DefaultTransport,
+			apiKeys:   apiKeys,
+			transport: sharedTransport,
 		},
 	}
 
@@ -82,21 +92,57 @@ func GetHTTPClientForGrafana() (*http.Client, string, error) {
 }
 
 // bearerAuthTransport is an http.RoundTripper that injects Bearer token authentication.
-// It wraps an underlying transport and adds the Authorization header to all requests.
+// It wraps an underlying transport and adds the Authorization and User-Agent headers to all requests.
+// When the token comes from a file, a 401 re-reads the file and retries the request once with the new token.
 type bearerAuthTransport struct {
-	apiKey    string
+	apiKeys   *apiKeySource
 	transport http.RoundTripper
 }
 
 // RoundTrip implements http.RoundTripper by adding Bearer token authentication to requests.
 func (t *bearerAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.send(req, false)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !t.apiKeys.fromFile() {
+		return resp, err
+	}
+
+	// The token may have been rotated; retry once if the request body can be replayed
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	return t.send(req, true)
+}
+
+// send issues a clone of req with the current token, re-reading a file-backed token when refresh is set.
+func (t *bearerAuthTransport) send(req *http.Request, refresh bool) (*http.Response, error) {
+	apiKey, err := t.apiKeys.token(refresh)
+	if err != nil {
+		return nil, err
+	}
+
 	// Clone the request to avoid modifying the original
-	req = req.Clone(req.Context())
-	req.Header.Set("Authorization", "Bearer "+t.apiKey)
-	return t.transport.RoundTrip(req)
+	clone := req.Clone(req.Context())
+	if refresh && req.GetBody != nil {
+		if clone.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("replaying request body: %w", err)
+		}
+	}
+	clone.Header.Set("Authorization", "Bearer "+apiKey)
+	if clone.Header.Get("User-Agent") == "" {
+		clone.Header.Set("User-Agent", UserAgent())
+	}
+	if rec := dryRunFrom(req.Context()); rec != nil {
+		rec.record(clone)
+		return nil, ErrDryRun
+	}
+
+	resp, err := t.transport.RoundTrip(clone)
+	stats.record(clone, resp, err)
+	return resp, err
 }
 
 // enhanceConfigError wraps configuration errors with helpful guidance for users.
 func enhanceConfigError(err error) error {
-	return fmt.Errorf("%w\n\nPlease ensure the following environment variables are set:\n  GRAFANA_URL       - Base URL of your Grafana instance (e.g., http://localhost:3000)\n  GRAFANA_API_KEY   - Service account token for authentication\n\nTo create a service account token:\n  1. In Grafana, go to Administration → Service accounts\n  2. Click 'Add service account'\n  3. Set a display name and assign the 'Viewer' role\n  4. Click 'Add token' and copy the generated token", err)
+	return fmt.Errorf("%w\n\nPlease ensure the following environment variables are set:\n  GRAFANA_URL       - Base URL of your Grafana instance (e.g., http://localhost:3000)\n  GRAFANA_API_KEY   - Service account token for authentication\n  (or GRAFANA_API_KEY_FILE - Path to a file holding the token)\n\nTo create a service account token:\n  1. In Grafana, go to Administration → Service accounts\n  2. Click 'Add service account'\n  3. Set a display name and assign the 'Viewer' role\n  4. Click 'Add token' and copy the generated token", err)
 }
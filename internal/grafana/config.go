@@ -0,0 +1,85 @@
+package grafana
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BoolFromEnv reports whether the named environment variable is set to "1" or "true".
+func BoolFromEnv(name string) bool {
+	v := strings.ToLower(os.Getenv(name))
+	return v == "1" || v == "true"
+}
+
+// SetFromEnv reads a comma-separated list from the named environment variable into a set,
+// ignoring surrounding whitespace and empty entries.
+func SetFromEnv(name string) map[string]bool {
+	set := make(map[string]bool)
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
+
+// DurationFromEnv reads a Go duration (e.g., "15m", "6h") from the named environment variable.
+// Returns fallback if the variable is unset, or if it is invalid or not positive,
+// in which case a warning is logged to stderr.
+func DurationFromEnv(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q (expected a positive duration like 15m or 6h), using %s\n", name, raw, fallback)
+		return fallback
+	}
+
+	return d
+}
+
+// FormatDuration formats d compactly for tool descriptions and messages, dropping zero trailing
+// units (e.g., "1h" rather than "1h0m0s", "1h30m", "45s").
+func FormatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// IntFromEnv reads a positive integer from the named environment variable.
+// Returns fallback if the variable is unset, or if it is invalid or not positive,
+// in which case a warning is logged to stderr.
+func IntFromEnv(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil || v <= 0 {
+		fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q (expected a positive integer), using %d\n", name, raw, fallback)
+		return fallback
+	}
+
+	return v
+}
+
+// maxConcurrency bounds how many backend requests a single fan-out tool call runs at once.
+// Override with GRAFANA_MAX_CONCURRENCY.
+var maxConcurrency = IntFromEnv("GRAFANA_MAX_CONCURRENCY", 4)
+
+// MaxConcurrency returns the per-call concurrency cap for tools that fan out requests.
+func MaxConcurrency() int {
+	return maxConcurrency
+}
@@ -0,0 +1,75 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// numericIDs caches the UIDs that numeric datasource IDs resolved to. IDs never change for a
+// datasource, so entries don't expire.
+var numericIDs = NewCache[string]("datasource_ids", 0)
+
+// isNumericID reports whether s is all digits, as the numeric datasource IDs older tools and URLs use.
+func isNumericID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveNumericID returns the UID of the datasource with the numeric ID id. A datasource whose UID
+// really is id wins, so the value is returned unchanged if /api/datasources/uid/{id} finds one.
+func resolveNumericID(ctx context.Context, id string) (string, error) {
+	if uid, ok := numericIDs.Get(id); ok {
+		return uid, nil
+	}
+
+	httpClient, grafanaURL, err := GetHTTPClientForGrafana()
+	if err != nil {
+		return "", err
+	}
+
+	// The lookup only reads datasource metadata, so send it even under a dry run
+	ctx = context.WithValue(ctx, dryRunKey{}, (*dryRunRecorder)(nil))
+
+	statusCode, bodyBytes, err := Do(ctx, httpClient, Request{Method: "GET", URL: grafanaURL + "/api/datasources/uid/" + url.PathEscape(id)})
+	if err != nil {
+		return "", err
+	}
+	if statusCode == http.StatusOK {
+		numericIDs.Set(id, id)
+		return id, nil
+	}
+
+	statusCode, bodyBytes, err = Do(ctx, httpClient, Request{Method: "GET", URL: grafanaURL + "/api/datasources/" + id})
+	if err != nil {
+		return "", err
+	}
+	if statusCode == http.StatusNotFound {
+		return "", fmt.Errorf("datasourceUid %q looks like a numeric datasource ID, but no datasource has that UID or ID", id)
+	}
+	if statusCode != http.StatusOK {
+		return "", fmt.Errorf("datasourceUid %q looks like a numeric datasource ID and resolving it failed: %w", id, StatusError("API", statusCode, bodyBytes))
+	}
+
+	var ds struct {
+		UID string `json:"uid"`
+	}
+	if err := json.Unmarshal(bodyBytes, &ds); err != nil {
+		return "", fmt.Errorf("unmarshalling datasource: %w", err)
+	}
+	if ds.UID == "" {
+		return "", fmt.Errorf("datasource with ID %s has no UID", id)
+	}
+
+	numericIDs.Set(id, ds.UID)
+	return ds.UID, nil
+}
@@ -0,0 +1,32 @@
+package grafana
+
+import "fmt"
+
+// datasourceAllowlist, when non-empty, is the only set of datasource UIDs tools may query.
+// Set with GRAFANA_DATASOURCE_ALLOWLIST (comma-separated UIDs).
+var datasourceAllowlist = SetFromEnv("GRAFANA_DATASOURCE_ALLOWLIST")
+
+// datasourceDenylist is a set of datasource UIDs tools may never query, even if allowlisted.
+// Set with GRAFANA_DATASOURCE_DENYLIST (comma-separated UIDs).
+var datasourceDenylist = SetFromEnv("GRAFANA_DATASOURCE_DENYLIST")
+
+// DatasourcePermitted reports whether the datasource allowlist and denylist permit the given UID.
+func DatasourcePermitted(uid string) bool {
+	if datasourceDenylist[uid] {
+		return false
+	}
+	return len(datasourceAllowlist) == 0 || datasourceAllowlist[uid]
+}
+
+// CheckDatasource returns an error if the datasource allowlist or denylist forbids the given UID.
+func CheckDatasource(uid string) error {
+	if !DatasourcePermitted(uid) {
+		return fmt.Errorf("datasource not permitted: %q is excluded by GRAFANA_DATASOURCE_ALLOWLIST/GRAFANA_DATASOURCE_DENYLIST", uid)
+	}
+	return nil
+}
+
+// DatasourceRestricted reports whether an allowlist or denylist is configured.
+func DatasourceRestricted() bool {
+	return len(datasourceAllowlist) > 0 || len(datasourceDenylist) > 0
+}
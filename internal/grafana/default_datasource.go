@@ -0,0 +1,133 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultDatasourceTTL is how long an auto-resolved default datasource is reused before /api/datasources is asked again.
+const defaultDatasourceTTL = time.Minute
+
+// resolvedDefaults caches auto-resolved defaults by datasource type, so a call without a datasourceUid
+// doesn't cost an extra request each time.
+var resolvedDefaults = NewCache[string]("default_datasources", defaultDatasourceTTL)
+
+// ResolveDatasourceUID returns uid unchanged when it is set, except that an all-digit uid naming no
+// datasource is treated as a numeric datasource ID and resolved to that datasource's UID. Otherwise it falls back to the
+// GRAFANA_DEFAULT_<TYPE>_UID environment variable for the first of types (e.g.,
+// GRAFANA_DEFAULT_PROMETHEUS_UID), then to the permitted datasource of one of types that Grafana
+// marks as default, then to the only permitted datasource of those types if there is exactly one.
+// Auto-resolved UIDs are cached for defaultDatasourceTTL.
+func ResolveDatasourceUID(ctx context.Context, uid string, types ...string) (string, error) {
+	if isNumericID(uid) {
+		return resolveNumericID(ctx, uid)
+	}
+	if uid != "" {
+		return uid, nil
+	}
+
+	envName := "GRAFANA_DEFAULT_" + strings.ToUpper(types[0]) + "_UID"
+	if v := strings.TrimSpace(os.Getenv(envName)); v != "" {
+		return v, nil
+	}
+
+	if cached, ok := resolvedDefaults.Get(types[0]); ok {
+		return cached, nil
+	}
+
+	// The lookup only reads datasource metadata, so send it even under a dry run
+	ctx = context.WithValue(ctx, dryRunKey{}, (*dryRunRecorder)(nil))
+	datasources, err := listDatasourcesOfType(ctx, types)
+	if err != nil {
+		return "", fmt.Errorf("datasourceUid not given and looking up the default %s datasource failed: %w", types[0], err)
+	}
+
+	if resolved := pickDefault(datasources); resolved != "" {
+		resolvedDefaults.Set(types[0], resolved)
+		return resolved, nil
+	}
+
+	if len(datasources) == 0 {
+		return "", fmt.Errorf("datasourceUid not given and no %s datasource found; pass datasourceUid or set %s", types[0], envName)
+	}
+	uids := make([]string, len(datasources))
+	for i, ds := range datasources {
+		uids[i] = ds.UID
+	}
+	return "", fmt.Errorf("datasourceUid not given and there are %d %s datasources (%s); pass datasourceUid or set %s",
+		len(datasources), types[0], strings.Join(uids, ", "), envName)
+}
+
+// defaultCandidate is the subset of a Grafana datasource ResolveDatasourceUID needs.
+type defaultCandidate struct {
+	UID       string `json:"uid"`
+	Type      string `json:"type"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// pickDefault returns the UID of the datasource marked as default, or of the only datasource, or "".
+func pickDefault(datasources []defaultCandidate) string {
+	for _, ds := range datasources {
+		if ds.IsDefault {
+			return ds.UID
+		}
+	}
+	if len(datasources) == 1 {
+		return datasources[0].UID
+	}
+	return ""
+}
+
+// listDatasourcesOfType fetches the permitted datasources whose plugin type is one of types.
+func listDatasourcesOfType(ctx context.Context, types []string) ([]defaultCandidate, error) {
+	datasources, err := fetchDatasources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []defaultCandidate
+	for _, ds := range datasources {
+		for _, t := range types {
+			if ds.Type == t {
+				matched = append(matched, ds)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// fetchDatasources fetches the datasources the tools are permitted to query.
+func fetchDatasources(ctx context.Context) ([]defaultCandidate, error) {
+	httpClient, grafanaURL, err := GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	statusCode, bodyBytes, err := Do(ctx, httpClient, Request{Method: "GET", URL: grafanaURL + "/api/datasources"})
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, StatusError("API", statusCode, bodyBytes)
+	}
+
+	var raw []defaultCandidate
+	if err := json.Unmarshal(bodyBytes, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshalling datasources: %w", err)
+	}
+
+	permitted := make([]defaultCandidate, 0, len(raw))
+	for _, ds := range raw {
+		if DatasourcePermitted(ds.UID) {
+			permitted = append(permitted, ds)
+		}
+	}
+	return permitted, nil
+}
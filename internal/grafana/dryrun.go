@@ -0,0 +1,99 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrDryRun is returned in place of a response for requests made under WithDryRun.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// DryRunRequest is an upstream request a query tool would have sent, with credentials redacted.
+type DryRunRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Params  map[string]any    `json:"params,omitempty"` // Decoded query string, e.g., Unix-nanosecond start/end
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// dryRunRecorder collects the requests intercepted under one dry-run context.
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	requests []DryRunRequest
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context under which Grafana requests are recorded instead of sent;
+// each fails with ErrDryRun. Retrieve the recorded requests with DryRunResult.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, &dryRunRecorder{})
+}
+
+// dryRunFrom returns the recorder of a dry-run context, or nil.
+func dryRunFrom(ctx context.Context) *dryRunRecorder {
+	rec, _ := ctx.Value(dryRunKey{}).(*dryRunRecorder)
+	return rec
+}
+
+// record stores a redacted description of req.
+func (r *dryRunRecorder) record(req *http.Request) {
+	u := *req.URL
+	params := make(map[string]any)
+	for name, values := range u.Query() {
+		if len(values) == 1 {
+			params[name] = values[0]
+		} else {
+			params[name] = values
+		}
+	}
+	u.RawQuery, u.User = "", nil
+
+	headers := make(map[string]string, len(req.Header))
+	for name := range req.Header {
+		headers[name] = req.Header.Get(name)
+	}
+	if _, ok := headers["Authorization"]; ok {
+		headers["Authorization"] = "Bearer [REDACTED]"
+	}
+
+	dr := DryRunRequest{Method: req.Method, URL: u.String(), Params: params, Headers: headers}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(io.LimitReader(body, 1024*1024))
+			dr.Body = string(b)
+		}
+	}
+	if len(dr.Params) == 0 {
+		dr.Params = nil
+	}
+
+	r.mu.Lock()
+	r.requests = append(r.requests, dr)
+	r.mu.Unlock()
+}
+
+// DryRunResult returns the requests recorded under a WithDryRun context as a tool result.
+func DryRunResult(ctx context.Context) *mcp.CallToolResult {
+	rec := dryRunFrom(ctx)
+	if rec == nil {
+		return mcp.NewToolResultError("not a dry run")
+	}
+
+	rec.mu.Lock()
+	requests := append([]DryRunRequest{}, rec.requests...)
+	rec.mu.Unlock()
+
+	jsonData, err := MarshalJSON(map[string]any{"dryRun": true, "requests": requests})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err))
+	}
+	return mcp.NewToolResultText(string(jsonData))
+}
@@ -0,0 +1,46 @@
+package grafana
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxErrorBodyLen caps how much of an error response body is quoted in an error message.
+const maxErrorBodyLen = 1024
+
+// htmlTitlePattern extracts the title of an HTML error page, e.g., "502 Bad Gateway".
+var htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// StatusError describes a non-OK response from api (e.g., "API", "loki API"). JSON and plain-text
+// bodies are quoted, truncated to maxErrorBodyLen. HTML pages, typically from a load balancer
+// in front of the datasource, are reduced to their title instead of flooding the message.
+func StatusError(api string, statusCode int, body []byte) error {
+	body = bytes.TrimSpace(body)
+	if isHTML(body) {
+		msg := fmt.Sprintf("%s returned a non-JSON error page (status %d); the datasource or proxy is likely unavailable", api, statusCode)
+		if m := htmlTitlePattern.FindSubmatch(body); m != nil {
+			if title := strings.Join(strings.Fields(string(m[1])), " "); title != "" {
+				msg += ": " + title
+			}
+		}
+		return fmt.Errorf("%s", msg)
+	}
+
+	text := string(body)
+	if len(text) > maxErrorBodyLen {
+		text = strings.ToValidUTF8(text[:maxErrorBodyLen], "") + fmt.Sprintf("... (%d bytes truncated)", len(body)-maxErrorBodyLen)
+	}
+	return fmt.Errorf("%s returned status %d: %s", api, statusCode, text)
+}
+
+// isHTML reports whether a response body looks like an HTML document rather than an API error.
+func isHTML(body []byte) bool {
+	if len(body) == 0 || body[0] != '<' {
+		return false
+	}
+	head := strings.ToLower(string(body[:min(len(body), 512)]))
+	return strings.HasPrefix(head, "<!doctype html") || strings.Contains(head, "<html") ||
+		strings.Contains(head, "<head") || strings.Contains(head, "<body")
+}
@@ -0,0 +1,47 @@
+package grafana
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// FilteredValues is the output of a list tool whose values were narrowed by a regex.
+type FilteredValues struct {
+	TotalCount   int      `json:"totalCount"` // Before filtering
+	MatchedCount int      `json:"matchedCount"`
+	Values       []string `json:"values"`
+}
+
+// FilterByRegex returns the values matching pattern. Matching is unanchored, as with regexp.MatchString.
+func FilterByRegex(values []string, pattern string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+
+	filtered := make([]string, 0)
+	for _, v := range values {
+		if re.MatchString(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered, nil
+}
+
+// TruncatedList is returned in place of a plain list when a limit dropped entries, so callers
+// know the list is incomplete instead of reasoning as if they had every value. TotalBeforeLimit
+// is omitted when the full count is unknown, e.g., when the backend applied the limit itself.
+type TruncatedList[T any] struct {
+	Values           []T  `json:"values"`
+	Truncated        bool `json:"_truncated"`
+	TotalBeforeLimit int  `json:"_totalBeforeLimit,omitempty"`
+}
+
+// LimitList caps values at limit. It returns the values unchanged when they fit, or a TruncatedList
+// holding the first limit values when entries were dropped. A limit <= 0 means no limit.
+func LimitList[T any](values []T, limit int) any {
+	if limit <= 0 || len(values) <= limit {
+		return values
+	}
+	return TruncatedList[T]{Values: values[:limit], Truncated: true, TotalBeforeLimit: len(values)}
+}
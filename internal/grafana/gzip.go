@@ -0,0 +1,84 @@
+package grafana
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxDecompressedBytes caps how much a gzip-encoded response may expand to, so a small compressed
+// response must not be able to expand without bound for a caller that reads it uncapped.
+const MaxDecompressedBytes = 256 * 1024 * 1024
+
+// gzipTransport is an http.RoundTripper that asks for gzip-encoded responses and decompresses them,
+// failing the read once the body exceeds MaxDecompressedBytes. Log and metric responses compress well, so wide-window queries spend
+// much less time on the wire. Requests that set their own Accept-Encoding get the raw response.
+type gzipTransport struct {
+	transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper, adding Accept-Encoding: gzip and decoding a gzip response body.
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Method == http.MethodHead {
+		return t.transport.RoundTrip(req)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := t.transport.RoundTrip(clone)
+	if err != nil || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp, err
+	}
+
+	resp.Body = &gzipBody{body: resp.Body, limit: MaxDecompressedBytes}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody decompresses a response body on first read, so an empty body (e.g., a 204) is not an error.
+// Reading more than limit bytes of decompressed data fails rather than silently truncating the body.
+type gzipBody struct {
+	body   io.ReadCloser
+	reader io.Reader
+	limit  int64
+	read   int64
+	err    error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		gz, err := gzip.NewReader(b.body)
+		if err != nil {
+			b.err = err
+		} else {
+			b.reader = gz
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	// Read one byte past the cap, so a body of exactly the cap still ends cleanly
+	remaining := b.limit - b.read
+	if int64(len(p)) > remaining+1 {
+		p = p[:remaining+1]
+	}
+	n, err := b.reader.Read(p)
+	if int64(n) > remaining {
+		n = int(remaining)
+		b.err = fmt.Errorf("response exceeds %d bytes after decompression", b.limit)
+		err = b.err
+	}
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
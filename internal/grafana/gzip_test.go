@@ -0,0 +1,46 @@
+package grafana
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGzipBodyLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "under the limit", body: "hello", limit: 10},
+		{name: "exactly the limit", body: strings.Repeat("x", 10), limit: 10},
+		{name: "over the limit", body: strings.Repeat("x", 11), limit: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var compressed bytes.Buffer
+			gz := gzip.NewWriter(&compressed)
+			_, _ = gz.Write([]byte(tt.body))
+			_ = gz.Close()
+
+			body := &gzipBody{body: io.NopCloser(&compressed), limit: tt.limit}
+			got, err := io.ReadAll(body)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "exceeds 10 bytes after decompression") {
+					t.Fatalf("ReadAll() error = %v, want the decompression limit error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadAll() error: %v", err)
+			}
+			if string(got) != tt.body {
+				t.Errorf("body = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package grafana
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// TenantHeader selects the tenant in multi-tenant Loki, Mimir, and Tempo deployments.
+const TenantHeader = "X-Scope-OrgID"
+
+// reservedHeaders may not be overridden by tool callers; Grafana's proxy or the transport owns them.
+var reservedHeaders = map[string]bool{
+	"Authorization":  true,
+	"Cookie":         true,
+	"Host":           true,
+	"Content-Length": true,
+	"Connection":     true,
+}
+
+// RequestHeaders builds the extra headers a tool should send through the datasource proxy
+// from a caller-supplied header map and an optional tenant ID shortcut for X-Scope-OrgID.
+// Returns nil when neither is set.
+func RequestHeaders(headers map[string]string, tenantID string) (http.Header, error) {
+	if len(headers) == 0 && tenantID == "" {
+		return nil, nil
+	}
+
+	h := make(http.Header, len(headers)+1)
+	for name, value := range headers {
+		if reservedHeaders[http.CanonicalHeaderKey(name)] {
+			return nil, fmt.Errorf("header %s cannot be set", name)
+		}
+		h.Set(name, value)
+	}
+	if tenantID != "" {
+		h.Set(TenantHeader, tenantID)
+	}
+	return h, nil
+}
@@ -0,0 +1,54 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// precheckHealth enables the datasource health pre-check for query tools by default.
+// Set with GRAFANA_PRECHECK_HEALTH; a tool call's precheckHealth param overrides it.
+var precheckHealth = BoolFromEnv("GRAFANA_PRECHECK_HEALTH")
+
+// PrecheckHealth calls the datasource's health endpoint before a query, when enabled by the
+// per-call override or GRAFANA_PRECHECK_HEALTH, and returns a "datasource X is unhealthy" error
+// if Grafana reports the datasource as down. Health endpoints that are unavailable or return an
+// unrecognised response do not block the query. It is skipped under WithDryRun.
+func PrecheckHealth(ctx context.Context, httpClient *http.Client, datasourceUID string, override *bool) error {
+	enabled := precheckHealth
+	if override != nil {
+		enabled = *override
+	}
+	// A dry run should show the query request, not the health check
+	if !enabled || dryRunFrom(ctx) != nil {
+		return nil
+	}
+
+	_, grafanaURL, err := GetHTTPClientForGrafana()
+	if err != nil {
+		return err
+	}
+
+	reqURL := fmt.Sprintf("%s/api/datasources/uid/%s/health", grafanaURL, url.PathEscape(datasourceUID))
+	_, bodyBytes, err := Do(ctx, httpClient, Request{Method: "GET", URL: reqURL, MaxBytes: 1024 * 1024})
+	if errors.Is(err, ErrResponseTooLarge) {
+		return nil // Not a health response we recognise
+	}
+	if err != nil {
+		return fmt.Errorf("checking datasource %s health: %w", datasourceUID, err)
+	}
+
+	// Grafana answers 200 with status OK, or 400 with status ERROR and the plugin's message
+	var health struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(bodyBytes, &health); err != nil || health.Status != "ERROR" {
+		return nil
+	}
+
+	return fmt.Errorf("datasource %s is unhealthy: %s", datasourceUID, health.Message)
+}
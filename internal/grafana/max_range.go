@@ -0,0 +1,59 @@
+package grafana
+
+import (
+	"fmt"
+	"time"
+)
+
+// strictMaxRange makes a time range longer than a backend's maximum an error instead of clamping it.
+var strictMaxRange = BoolFromEnv("GRAFANA_STRICT_MAX_RANGE")
+
+// RangeLimit caps how far back from its end time a query against one backend may reach,
+// so a miscomputed start (now-1y instead of now-1h) cannot turn into a multi-year scan.
+type RangeLimit struct {
+	envName string
+	max     time.Duration // Zero means no limit
+}
+
+// NewRangeLimit reads the maximum range from the named environment variable (e.g., LOKI_MAX_RANGE).
+// Unset means no limit.
+func NewRangeLimit(envName string) RangeLimit {
+	return RangeLimit{envName: envName, max: DurationFromEnv(envName, 0)}
+}
+
+// Clamp enforces the limit on a resolved time range. A longer range has its start moved forward
+// to end minus the maximum and comes back with a notice saying so (for the caller to report with WithWarnings), or, when GRAFANA_STRICT_MAX_RANGE
+// is set, is rejected with an error. The notice is empty when start is unchanged.
+func (l RangeLimit) Clamp(start, end time.Time) (time.Time, string, error) {
+	if l.max <= 0 || end.Sub(start) <= l.max {
+		return start, "", nil
+	}
+
+	if strictMaxRange {
+		return start, "", fmt.Errorf("time range of %s exceeds %s=%s; narrow startRfc3339/endRfc3339", end.Sub(start), l.envName, l.max)
+	}
+
+	clamped := end.Add(-l.max)
+	notice := fmt.Sprintf("Requested time range of %s exceeds %s=%s; start was clamped from %s to %s.",
+		end.Sub(start), l.envName, l.max, start.UTC().Format(time.RFC3339), clamped.UTC().Format(time.RFC3339))
+	return clamped, notice, nil
+}
+
+// ClampRFC3339 is Clamp for RFC3339 start and end times. Times that don't parse are returned
+// unchanged, leaving the caller's own parsing to report them.
+func (l RangeLimit) ClampRFC3339(startRFC3339, endRFC3339 string) (string, string, error) {
+	start, err := time.Parse(time.RFC3339, startRFC3339)
+	if err != nil {
+		return startRFC3339, "", nil
+	}
+	end, err := time.Parse(time.RFC3339, endRFC3339)
+	if err != nil {
+		return startRFC3339, "", nil
+	}
+
+	clamped, notice, err := l.Clamp(start, end)
+	if err != nil || notice == "" {
+		return startRFC3339, "", err
+	}
+	return clamped.Format(time.RFC3339), notice, nil
+}
@@ -0,0 +1,66 @@
+package grafana
+
+import (
+	"encoding/json"
+)
+
+// compactJSON switches tool output from indented to compact JSON. Indentation is pure
+// overhead for machine consumers and inflates token counts on large results.
+var compactJSON = BoolFromEnv("MCP_COMPACT_JSON")
+
+// envelopeResults wraps query tool results in a ResultEnvelope.
+var envelopeResults = BoolFromEnv("MCP_RESULT_ENVELOPE")
+
+// ResultEnvelope makes a successful query unambiguous, so an empty result reads as
+// "no data in range" rather than something that looks like a failed call.
+type ResultEnvelope struct {
+	Status      string   `json:"status"`
+	ResultCount int      `json:"resultCount"`
+	Result      any      `json:"result"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// WrapResult wraps a successful query result in a ResultEnvelope when MCP_RESULT_ENVELOPE
+// is enabled, and returns it unchanged otherwise to keep the original output shape.
+func WrapResult(result any, count int) any {
+	if !envelopeResults {
+		return result
+	}
+	return ResultEnvelope{Status: "ok", ResultCount: count, Result: result}
+}
+
+// WarnedResult pairs a result with warnings about how it was produced, such as a clamped time range.
+type WarnedResult struct {
+	Result   any      `json:"result"`
+	Warnings []string `json:"warnings"`
+}
+
+// WithWarnings attaches the non-empty warnings to a result, keeping them in the same JSON text as the
+// data so they survive callers that nest one tool's output in another's. A ResultEnvelope takes them
+// in its warnings field; any other result is wrapped in a WarnedResult. With no warnings the result
+// is returned unchanged.
+func WithWarnings(result any, warnings ...string) any {
+	var nonEmpty []string
+	for _, w := range warnings {
+		if w != "" {
+			nonEmpty = append(nonEmpty, w)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return result
+	}
+	if env, ok := result.(ResultEnvelope); ok {
+		env.Warnings = append(env.Warnings, nonEmpty...)
+		return env
+	}
+	return WarnedResult{Result: result, Warnings: nonEmpty}
+}
+
+// MarshalJSON marshals a tool or resource result, indented with two spaces by default
+// or compact when MCP_COMPACT_JSON is enabled.
+func MarshalJSON(v any) ([]byte, error) {
+	if compactJSON {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
@@ -0,0 +1,51 @@
+package grafana
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWithWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   any
+		warnings []string
+		want     string
+	}{
+		{
+			name:   "no warnings keeps result",
+			result: []string{"a"},
+			want:   `["a"]`,
+		},
+		{
+			name:     "empty warnings are dropped",
+			result:   []string{"a"},
+			warnings: []string{"", ""},
+			want:     `["a"]`,
+		},
+		{
+			name:     "plain result is wrapped",
+			result:   []string{"a"},
+			warnings: []string{"clamped", ""},
+			want:     `{"result":["a"],"warnings":["clamped"]}`,
+		},
+		{
+			name:     "envelope takes warnings",
+			result:   ResultEnvelope{Status: "ok", ResultCount: 1, Result: []string{"a"}},
+			warnings: []string{"clamped"},
+			want:     `{"status":"ok","resultCount":1,"result":["a"],"warnings":["clamped"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(WithWarnings(tt.result, tt.warnings...))
+			if err != nil {
+				t.Fatalf("marshalling: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProjectFields reduces v to the requested fields before it is returned to the client.
+// Each field is a top-level key or a dotted path (e.g., "data.model.expr"); paths descend
+// through arrays by applying the remaining path to every element. Fields that do not exist
+// are silently omitted. If fields is empty, v is returned unchanged.
+func ProjectFields(v any, fields []string) (any, error) {
+	var paths [][]string
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		paths = append(paths, strings.Split(field, "."))
+	}
+	if len(paths) == 0 {
+		return v, nil
+	}
+
+	// Round-trip through JSON so projection works on the same shape the client would see
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling value for projection: %w", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("unmarshalling value for projection: %w", err)
+	}
+
+	return project(decoded, paths), nil
+}
+
+// project keeps only the parts of src addressed by paths.
+func project(src any, paths [][]string) any {
+	for _, p := range paths {
+		if len(p) == 0 {
+			return src
+		}
+	}
+
+	switch v := src.(type) {
+	case map[string]any:
+		byKey := make(map[string][][]string)
+		for _, p := range paths {
+			byKey[p[0]] = append(byKey[p[0]], p[1:])
+		}
+		out := make(map[string]any, len(byKey))
+		for key, rest := range byKey {
+			if child, ok := v[key]; ok {
+				out[key] = project(child, rest)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			out = append(out, project(item, paths))
+		}
+		return out
+	default:
+		// The path goes deeper than the value; keep the value as is
+		return v
+	}
+}
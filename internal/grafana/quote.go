@@ -0,0 +1,19 @@
+package grafana
+
+import "strings"
+
+var labelValueEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// QuoteLabelValue renders s as a double-quoted PromQL/LogQL/TraceQL string, e.g. for a label matcher
+// value, a line filter, or a span attribute value. Backslashes, quotes, and line breaks are escaped;
+// other characters, including non-ASCII text, are kept as-is since all three languages accept UTF-8
+// in string literals.
+func QuoteLabelValue(s string) string {
+	return `"` + labelValueEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,30 @@
+package grafana
+
+import "testing"
+
+func TestQuoteLabelValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "api", want: `"api"`},
+		{name: "empty", value: "", want: `""`},
+		{name: "embedded quotes", value: `say "hi"`, want: `"say \"hi\""`},
+		{name: "backslash", value: `C:\logs`, want: `"C:\\logs"`},
+		{name: "escaped quote stays escaped", value: `\"`, want: `"\\\""`},
+		{name: "regex", value: `(?i)error|fatal\d+`, want: `"(?i)error|fatal\\d+"`},
+		{name: "line breaks and tab", value: "a\nb\r\tc", want: `"a\nb\r\tc"`},
+		{name: "unicode kept as-is", value: "café-服务", want: `"café-服务"`},
+		{name: "emoji kept as-is", value: "deploy 🚀", want: `"deploy 🚀"`},
+		{name: "unicode with quotes", value: `naïve "ünïcode"`, want: `"naïve \"ünïcode\""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuoteLabelValue(tt.value); got != tt.want {
+				t.Errorf("QuoteLabelValue(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package grafana
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolRegistry is what the tool packages register their tools with: the MCP server itself,
+// or a wrapper that decides at registration time which tools the server gets.
+type ToolRegistry interface {
+	AddTool(tool mcp.Tool, handler server.ToolHandlerFunc)
+}
+
+// toolEnabled is the check ToolEnabled consults. Every tool is enabled until SetToolEnabled installs one.
+var toolEnabled = func(string) bool { return true }
+
+// SetToolEnabled installs the check ToolEnabled consults; RegisterMCPTools installs its tool filter.
+func SetToolEnabled(check func(name string) bool) {
+	toolEnabled = check
+}
+
+// ToolEnabled reports whether the named tool is enabled. Tools that call another tool's handler
+// directly consult it first, so a tool filtered out with MCP_ENABLED_TOOLS or MCP_DISABLED_TOOLS
+// can't be reached through them.
+func ToolEnabled(name string) bool {
+	return toolEnabled(name)
+}
@@ -0,0 +1,112 @@
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// MaxResponseBytes is the response size cap the log and search clients apply, to prevent memory issues.
+const MaxResponseBytes = 48 * 1024 * 1024
+
+// ErrResponseTooLarge is returned when a response body exceeds the size cap it is read under.
+var ErrResponseTooLarge = errors.New("response body too large; narrow the query or time range")
+
+// readLimited reads body, failing with ErrResponseTooLarge if it is longer than maxBytes.
+func readLimited(body io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w (over %d bytes)", ErrResponseTooLarge, maxBytes)
+	}
+	return data, nil
+}
+
+// Request is an HTTP request to the Grafana API or a datasource proxy.
+type Request struct {
+	Method      string
+	URL         string      // Absolute URL without a query string
+	Params      url.Values  // Encoded as the query string when non-empty
+	Body        []byte      // Optional request body
+	ContentType string      // Content-Type of Body
+	Header      http.Header // Extra headers, e.g., X-Scope-OrgID
+	MaxBytes    int64       // Largest response body accepted before failing with ErrResponseTooLarge; 0 means no cap
+	Truncate    bool        // Cut a body over MaxBytes down to MaxBytes instead of failing
+}
+
+// Response is the outcome of a Request that got an HTTP response, whatever its status.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Truncated  bool // The body was cut to the request's MaxBytes
+}
+
+// Do sends r with httpClient and returns the status code and response body. Non-2xx statuses are not
+// errors, so each client can turn them into its own error (usually with StatusError) or interpret them.
+func Do(ctx context.Context, httpClient *http.Client, r Request) (int, []byte, error) {
+	resp, err := Send(ctx, httpClient, r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, resp.Body, nil
+}
+
+// Send is Do for callers that also need the response headers or to know whether the body was truncated.
+func Send(ctx context.Context, httpClient *http.Client, r Request) (*Response, error) {
+	reqURL := r.URL
+	if len(r.Params) > 0 {
+		reqURL += "?" + r.Params.Encode()
+	}
+
+	// A bytes.Reader body lets the transport replay it, e.g., when retrying after a token refresh
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	if r.Body != nil && r.ContentType != "" {
+		req.Header.Set("Content-Type", r.ContentType)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	result := &Response{StatusCode: resp.StatusCode, Header: resp.Header}
+	switch {
+	case r.MaxBytes > 0 && r.Truncate:
+		// Read one byte past the cap to detect truncation
+		result.Body, err = io.ReadAll(io.LimitReader(resp.Body, r.MaxBytes+1))
+		if int64(len(result.Body)) > r.MaxBytes {
+			result.Body, result.Truncated = result.Body[:r.MaxBytes], true
+		}
+	case r.MaxBytes > 0:
+		result.Body, err = readLimited(resp.Body, r.MaxBytes)
+	default:
+		result.Body, err = io.ReadAll(resp.Body)
+	}
+	if errors.Is(err, ErrResponseTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	return result, nil
+}
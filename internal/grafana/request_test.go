@@ -0,0 +1,66 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("q"); got != "up" {
+			t.Errorf("q = %q, want up", got)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte(strings.Repeat("x", 10)))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		maxBytes      int64
+		truncate      bool
+		wantBody      string
+		wantTruncated bool
+		wantErr       error
+	}{
+		{name: "no cap", wantBody: strings.Repeat("x", 10)},
+		{name: "under cap", maxBytes: 10, wantBody: strings.Repeat("x", 10)},
+		{name: "over cap fails", maxBytes: 4, wantErr: ErrResponseTooLarge},
+		{name: "over cap truncates", maxBytes: 4, truncate: true, wantBody: "xxxx", wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := Send(context.Background(), srv.Client(), Request{
+				Method:   "GET",
+				URL:      srv.URL,
+				Params:   map[string][]string{"q": {"up"}},
+				MaxBytes: tt.maxBytes,
+				Truncate: tt.truncate,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != http.StatusTeapot {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusTeapot)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "text/plain" {
+				t.Errorf("content type = %q", got)
+			}
+			if string(resp.Body) != tt.wantBody || resp.Truncated != tt.wantTruncated {
+				t.Errorf("body = %q (truncated %v), want %q (truncated %v)", resp.Body, resp.Truncated, tt.wantBody, tt.wantTruncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// flightCall is an upstream request shared by every caller that issued it while it was in flight.
+type flightCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// singleflightTransport is an http.RoundTripper that collapses concurrent identical GET requests
+// into one upstream call. There is no caching: once a call completes, the next request goes upstream again.
+//
+// The shared request runs detached from any single caller's context, so one caller cancelling
+// (or timing out) does not fail the others; it is only cancelled once every waiting caller has gone.
+type singleflightTransport struct {
+	transport http.RoundTripper
+
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// sharedTransport deduplicates in-flight requests across all Grafana clients.
+var sharedTransport = &singleflightTransport{
+	transport: &gzipTransport{transport: http.DefaultTransport},
+	calls:     make(map[string]*flightCall),
+}
+
+// RoundTrip implements http.RoundTripper, sharing the response of an identical in-flight request if there is one.
+func (t *singleflightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only bodiless GETs are safe to share
+	if req.Method != http.MethodGet || (req.Body != nil && req.Body != http.NoBody) {
+		return t.transport.RoundTrip(req)
+	}
+
+	key := flightKey(req)
+
+	t.mu.Lock()
+	call, ok := t.calls[key]
+	if ok {
+		call.waiters++
+		stats.sharedResponses.Add(1)
+	} else {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel, waiters: 1}
+		t.calls[key] = call
+		go t.run(key, call, req.WithContext(ctx))
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return copyResponse(call.resp, call.body, req), nil
+
+	case <-req.Context().Done():
+		t.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			if t.calls[key] == call {
+				delete(t.calls, key)
+			}
+		}
+		t.mu.Unlock()
+		return nil, req.Context().Err()
+	}
+}
+
+// flightKey identifies a request by method, URL, and headers, so requests for different tenants are never shared.
+func flightKey(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.Method + " " + req.URL.String())
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n" + name + ": " + strings.Join(req.Header[name], ","))
+	}
+	return b.String()
+}
+
+// run performs the shared upstream request and buffers its body for every waiting caller.
+func (t *singleflightTransport) run(key string, call *flightCall, req *http.Request) {
+	defer call.cancel()
+
+	resp, err := t.transport.RoundTrip(req)
+	if err == nil {
+		// Cap the shared body here, since it is buffered before any caller's MaxBytes could apply
+		call.body, err = readLimited(resp.Body, MaxResponseBytes)
+		_ = resp.Body.Close()
+		call.resp = resp
+	}
+	call.err = err
+
+	t.mu.Lock()
+	if t.calls[key] == call {
+		delete(t.calls, key)
+	}
+	t.mu.Unlock()
+
+	close(call.done)
+}
+
+// copyResponse gives each caller its own response with an independent body reader.
+func copyResponse(resp *http.Response, body []byte, req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        resp.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
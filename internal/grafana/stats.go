@@ -0,0 +1,103 @@
+package grafana
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// grafanaBackend is the backend name for requests to Grafana's own API rather than a datasource proxy.
+const grafanaBackend = "grafana"
+
+// datasourceProxyPrefix precedes the datasource UID in datasource proxy request paths.
+const datasourceProxyPrefix = "/api/datasources/proxy/uid/"
+
+// requestStats counts the requests this server sends to Grafana. It lives in memory for the
+// lifetime of the process; every counter is safe for concurrent use.
+type requestStats struct {
+	started time.Time
+
+	requests        atomic.Int64
+	sharedResponses atomic.Int64 // Requests answered by an identical in-flight request
+	transportErrors atomic.Int64 // Requests that failed without a response, including timeouts
+	status2xx       atomic.Int64
+	status3xx       atomic.Int64
+	status4xx       atomic.Int64
+	status5xx       atomic.Int64
+
+	mu        sync.Mutex
+	byBackend map[string]int64
+}
+
+var stats = &requestStats{started: time.Now(), byBackend: make(map[string]int64)}
+
+// RequestStats is a snapshot of the request counters since the server started.
+type RequestStats struct {
+	Since             string           `json:"since"`
+	Requests          int64            `json:"requests"`
+	RequestsByBackend map[string]int64 `json:"requestsByBackend"` // Datasource UID, or "grafana" for the Grafana API
+	ResponsesByStatus map[string]int64 `json:"responsesByStatus"`
+	TransportErrors   int64            `json:"transportErrors"`
+	SharedResponses   int64            `json:"sharedResponses"` // Served from an identical request already in flight
+}
+
+// Stats returns a snapshot of the request counters.
+func Stats() RequestStats {
+	stats.mu.Lock()
+	byBackend := make(map[string]int64, len(stats.byBackend))
+	for k, v := range stats.byBackend {
+		byBackend[k] = v
+	}
+	stats.mu.Unlock()
+
+	return RequestStats{
+		Since:             stats.started.UTC().Format(time.RFC3339),
+		Requests:          stats.requests.Load(),
+		RequestsByBackend: byBackend,
+		ResponsesByStatus: map[string]int64{
+			"2xx": stats.status2xx.Load(),
+			"3xx": stats.status3xx.Load(),
+			"4xx": stats.status4xx.Load(),
+			"5xx": stats.status5xx.Load(),
+		},
+		TransportErrors: stats.transportErrors.Load(),
+		SharedResponses: stats.sharedResponses.Load(),
+	}
+}
+
+// record counts a completed request by backend and by response status class.
+func (s *requestStats) record(req *http.Request, resp *http.Response, err error) {
+	s.requests.Add(1)
+
+	backend := requestBackend(req)
+	s.mu.Lock()
+	s.byBackend[backend]++
+	s.mu.Unlock()
+
+	if err != nil || resp == nil {
+		s.transportErrors.Add(1)
+		return
+	}
+	switch {
+	case resp.StatusCode >= 500:
+		s.status5xx.Add(1)
+	case resp.StatusCode >= 400:
+		s.status4xx.Add(1)
+	case resp.StatusCode >= 300:
+		s.status3xx.Add(1)
+	default:
+		s.status2xx.Add(1)
+	}
+}
+
+// requestBackend returns the datasource UID a proxied request targets, or "grafana" for the Grafana API.
+func requestBackend(req *http.Request) string {
+	_, rest, ok := strings.Cut(req.URL.Path, datasourceProxyPrefix)
+	if !ok {
+		return grafanaBackend
+	}
+	uid, _, _ := strings.Cut(rest, "/")
+	return uid
+}
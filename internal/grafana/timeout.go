@@ -0,0 +1,28 @@
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// MaxRequestTimeout caps per-call timeout overrides.
+const MaxRequestTimeout = 5 * time.Minute
+
+// defaultTimeout is the HTTP client timeout for Grafana API calls. Override with GRAFANA_TIMEOUT.
+var defaultTimeout = DurationFromEnv("GRAFANA_TIMEOUT", 30*time.Second)
+
+// WithRequestTimeout applies a per-call timeout override to a tool call. When seconds is
+// positive, the returned context carries a deadline of that many seconds (capped at
+// MaxRequestTimeout) and httpClient's own timeout is cleared so the deadline governs in
+// both directions. When seconds is zero or negative, ctx and httpClient are left as is.
+// httpClient must be the per-call client returned by GetHTTPClientForGrafana.
+func WithRequestTimeout(ctx context.Context, httpClient *http.Client, seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return ctx, func() {}
+	}
+
+	timeout := min(time.Duration(seconds)*time.Second, MaxRequestTimeout)
+	httpClient.Timeout = 0
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,72 @@
+package grafana
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenFileTTL is how long a token read from GRAFANA_API_KEY_FILE is reused before the file is read again.
+const tokenFileTTL = 30 * time.Second
+
+// apiKeySource supplies the token sent as the Bearer credential. A token from GRAFANA_API_KEY never changes;
+// a token from GRAFANA_API_KEY_FILE is re-read after tokenFileTTL, or immediately after a 401, so a rotated
+// service account token is picked up without restarting the server.
+type apiKeySource struct {
+	static string
+	path   string
+
+	mu     sync.Mutex
+	cached string
+	readAt time.Time
+}
+
+// apiKeys is shared by every client so the token file cache is shared too.
+// GRAFANA_API_KEY_FILE takes precedence over GRAFANA_API_KEY.
+var apiKeys = &apiKeySource{
+	static: os.Getenv("GRAFANA_API_KEY"),
+	path:   os.Getenv("GRAFANA_API_KEY_FILE"),
+}
+
+// fromFile reports whether the token is read from a file and may therefore change.
+func (s *apiKeySource) fromFile() bool {
+	return s.path != ""
+}
+
+// token returns the current token. With refresh set, a file-backed token is re-read even if the cached copy is fresh.
+func (s *apiKeySource) token(refresh bool) (string, error) {
+	if !s.fromFile() {
+		if s.static == "" {
+			return "", fmt.Errorf("GRAFANA_API_KEY environment variable not set")
+		}
+		return s.static, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !refresh && s.cached != "" && time.Since(s.readAt) < tokenFileTTL {
+		return s.cached, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		// Keep serving the last good token if the file is briefly missing mid-rotation
+		if s.cached != "" {
+			return s.cached, nil
+		}
+		return "", fmt.Errorf("reading GRAFANA_API_KEY_FILE: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		if s.cached != "" {
+			return s.cached, nil
+		}
+		return "", fmt.Errorf("GRAFANA_API_KEY_FILE %s is empty", s.path)
+	}
+
+	s.cached, s.readAt = token, time.Now()
+	return token, nil
+}
@@ -0,0 +1,118 @@
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withAPIKey swaps in a static token for the duration of a test.
+func withAPIKey(t *testing.T, token string) {
+	t.Helper()
+	saved := apiKeys
+	apiKeys = &apiKeySource{static: token}
+	t.Cleanup(func() { apiKeys = saved })
+}
+
+func TestGetHTTPClientForGrafanaURL(t *testing.T) {
+	withAPIKey(t, "test-token")
+
+	tests := []struct {
+		name       string
+		grafanaURL string
+		want       string
+		wantErr    bool
+	}{
+		{name: "bare host", grafanaURL: "http://localhost:3000", want: "http://localhost:3000"},
+		{name: "trailing slash", grafanaURL: "http://localhost:3000/", want: "http://localhost:3000"},
+		{name: "subpath", grafanaURL: "https://example.com/grafana", want: "https://example.com/grafana"},
+		{name: "subpath with trailing slash", grafanaURL: "https://example.com/grafana/", want: "https://example.com/grafana"},
+		{name: "subpath with repeated trailing slashes", grafanaURL: "https://example.com/grafana//", want: "https://example.com/grafana"},
+		{name: "nested subpath", grafanaURL: "https://example.com/tools/grafana/", want: "https://example.com/tools/grafana"},
+		{name: "unset", grafanaURL: "", wantErr: true},
+		{name: "missing scheme", grafanaURL: "localhost:3000", wantErr: true},
+		{name: "unsupported scheme", grafanaURL: "ftp://example.com", wantErr: true},
+		{name: "query string", grafanaURL: "https://example.com/grafana?orgId=1", wantErr: true},
+		{name: "fragment", grafanaURL: "https://example.com/grafana#home", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GRAFANA_URL", tt.grafanaURL)
+
+			_, got, err := GetHTTPClientForGrafana()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GetHTTPClientForGrafana() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetHTTPClientForGrafana() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetHTTPClientForGrafana() base URL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrafanaURLPathComposition(t *testing.T) {
+	withAPIKey(t, "test-token")
+
+	var gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name     string
+		subpath  string
+		path     string
+		wantPath string
+	}{
+		{name: "API at root", subpath: "", path: "/api/health", wantPath: "/api/health"},
+		{name: "API under subpath", subpath: "/grafana", path: "/api/health", wantPath: "/grafana/api/health"},
+		{name: "API under subpath with trailing slash", subpath: "/grafana/", path: "/api/health", wantPath: "/grafana/api/health"},
+		{
+			name:     "Prometheus proxy under subpath",
+			subpath:  "/grafana/",
+			path:     "/api/datasources/proxy/uid/prom/api/v1/query",
+			wantPath: "/grafana/api/datasources/proxy/uid/prom/api/v1/query",
+		},
+		{
+			name:     "Loki proxy under nested subpath",
+			subpath:  "/tools/grafana",
+			path:     "/api/datasources/proxy/uid/logs/loki/api/v1/labels",
+			wantPath: "/tools/grafana/api/datasources/proxy/uid/logs/loki/api/v1/labels",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GRAFANA_URL", srv.URL+tt.subpath)
+
+			httpClient, grafanaURL, err := GetHTTPClientForGrafana()
+			if err != nil {
+				t.Fatalf("GetHTTPClientForGrafana() error: %v", err)
+			}
+
+			statusCode, _, err := Do(context.Background(), httpClient, Request{Method: "GET", URL: grafanaURL + tt.path})
+			if err != nil {
+				t.Fatalf("Do() error: %v", err)
+			}
+			if statusCode != http.StatusOK {
+				t.Fatalf("Do() status = %d, want %d", statusCode, http.StatusOK)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("request path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if gotAuth != "Bearer test-token" {
+				t.Errorf("Authorization header = %q, want %q", gotAuth, "Bearer test-token")
+			}
+		})
+	}
+}
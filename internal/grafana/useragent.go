@@ -0,0 +1,23 @@
+package grafana
+
+import "os"
+
+// version is the server version reported in the User-Agent header; SetVersion fills it in at startup.
+var version = "dev"
+
+// userAgentOverride replaces the whole User-Agent header when GRAFANA_USER_AGENT is set.
+var userAgentOverride = os.Getenv("GRAFANA_USER_AGENT")
+
+// SetVersion records the build version (injected at build time) for the User-Agent header.
+func SetVersion(v string) {
+	version = v
+}
+
+// UserAgent returns the User-Agent sent on every Grafana request, mcp-grafana/<version> by default,
+// so operators can identify, audit, or rate-limit this server's traffic in Grafana's access logs.
+func UserAgent() string {
+	if userAgentOverride != "" {
+		return userAgentOverride
+	}
+	return "mcp-grafana/" + version
+}
@@ -0,0 +1,37 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+)
+
+// warmupEnabled makes the server check Grafana at startup. Set with GRAFANA_WARMUP.
+var warmupEnabled = BoolFromEnv("GRAFANA_WARMUP")
+
+// Warmup, when GRAFANA_WARMUP is set, fetches the datasource list once so a bad GRAFANA_URL or token
+// fails at startup instead of on the first tool call, and primes the default datasource cache so the
+// first call without a datasourceUid needs no extra request. It returns the number of permitted
+// datasources, or 0 and nil when warmup is disabled.
+func Warmup(ctx context.Context) (int, error) {
+	if !warmupEnabled {
+		return 0, nil
+	}
+
+	datasources, err := fetchDatasources(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("fetching datasources (check GRAFANA_URL and the API key): %w", err)
+	}
+
+	byType := make(map[string][]defaultCandidate)
+	for _, ds := range datasources {
+		byType[ds.Type] = append(byType[ds.Type], ds)
+	}
+
+	for dsType, candidates := range byType {
+		if uid := pickDefault(candidates); uid != "" {
+			resolvedDefaults.Set(dsType, uid)
+		}
+	}
+
+	return len(datasources), nil
+}
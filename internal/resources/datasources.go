@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 
 	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -23,6 +23,7 @@ type Datasource struct {
 
 func RegisterDatasourcesMCPResource(s *server.MCPServer) {
 	s.AddResource(newDatasourcesMCPResource(), datasourcesHandler)
+	s.AddResourceTemplate(newDatasourcesByTypeMCPResourceTemplate(), datasourcesByTypeHandler)
 }
 
 // Resource schema
@@ -35,36 +36,77 @@ func newDatasourcesMCPResource() mcp.Resource {
 	)
 }
 
+// Resource template schema
+func newDatasourcesByTypeMCPResourceTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate("grafana://datasources/{type}", "grafana_datasources_by_type",
+		mcp.WithTemplateDescription("Grafana datasources of a single plugin type (e.g., grafana://datasources/prometheus). "+
+			"Use this to find the candidate datasources for a templated $datasource variable of a known type, "+
+			"such as one returned by get_dashboard_panel_queries."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
 // Resource handler
 func datasourcesHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-	// Get authenticated HTTP client
-	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	datasources, err := listDatasources(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("creating Grafana client: %w", err)
+		return nil, err
+	}
+	return datasourcesContents(request.Params.URI, datasources)
+}
+
+// Resource template handler
+func datasourcesByTypeHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	// Template variables arrive as a list of values
+	var dsType string
+	switch v := request.Params.Arguments["type"].(type) {
+	case []string:
+		if len(v) > 0 {
+			dsType = v[0]
+		}
+	case string:
+		dsType = v
+	}
+	if dsType == "" {
+		return nil, fmt.Errorf("datasource type is required, e.g., grafana://datasources/prometheus")
+	}
+
+	datasources, err := listDatasources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []Datasource{}
+	for _, ds := range datasources {
+		if strings.EqualFold(ds.Type, dsType) {
+			matched = append(matched, ds)
+		}
 	}
+	return datasourcesContents(request.Params.URI, matched)
+}
 
-	// Build request to list datasources
-	req, err := http.NewRequestWithContext(ctx, "GET", grafanaURL+"/api/datasources", nil)
+// listDatasources fetches the datasources the tools are permitted to query.
+func listDatasources(ctx context.Context) ([]Datasource, error) {
+	// Get authenticated HTTP client
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating Grafana client: %w", err)
 	}
 
-	// Execute request
-	resp, err := httpClient.Do(req)
+	// Fetch the datasource list
+	statusCode, bodyBytes, err := grafana.Do(ctx, httpClient, grafana.Request{Method: "GET", URL: grafanaURL + "/api/datasources"})
 	if err != nil {
 		return nil, fmt.Errorf("fetching datasources: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
 	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
 	}
 
 	// Parse response
 	var rawDatasources []map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&rawDatasources); err != nil {
+	if err := json.Unmarshal(bodyBytes, &rawDatasources); err != nil {
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
@@ -76,6 +118,10 @@ func datasourcesHandler(ctx context.Context, request mcp.ReadResourceRequest) ([
 		if uid, ok := ds["uid"].(string); ok {
 			datasource.UID = uid
 		}
+		// Hide datasources the tools are not permitted to query
+		if !grafana.DatasourcePermitted(datasource.UID) {
+			continue
+		}
 		if name, ok := ds["name"].(string); ok {
 			datasource.Name = name
 		}
@@ -92,8 +138,12 @@ func datasourcesHandler(ctx context.Context, request mcp.ReadResourceRequest) ([
 		datasources = append(datasources, datasource)
 	}
 
-	// Convert to JSON
-	jsonData, err := json.MarshalIndent(datasources, "", "  ")
+	return datasources, nil
+}
+
+// datasourcesContents marshals datasources as the contents of the resource at uri.
+func datasourcesContents(uri string, datasources []Datasource) ([]mcp.ResourceContents, error) {
+	jsonData, err := grafana.MarshalJSON(datasources)
 	if err != nil {
 		return nil, fmt.Errorf("marshalling datasources: %w", err)
 	}
@@ -101,7 +151,7 @@ func datasourcesHandler(ctx context.Context, request mcp.ReadResourceRequest) ([
 	// Return as MCP resource contents
 	return []mcp.ResourceContents{
 		mcp.TextResourceContents{
-			URI:      "grafana://datasources",
+			URI:      uri,
 			MIMEType: "application/json",
 			Text:     string(jsonData),
 		},
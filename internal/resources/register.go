@@ -9,4 +9,5 @@ import (
 func RegisterMCPResources(s *server.MCPServer) {
 	// Register resources
 	RegisterDatasourcesMCPResource(s)
+	RegisterStatsMCPResource(s)
 }
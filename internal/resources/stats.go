@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func RegisterStatsMCPResource(s *server.MCPServer) {
+	s.AddResource(newStatsMCPResource(), statsHandler)
+}
+
+// Resource schema
+func newStatsMCPResource() mcp.Resource {
+	return mcp.NewResource("grafana://stats", "grafana_stats",
+		mcp.WithResourceDescription("Counters of the requests this server has sent to Grafana since it started: "+
+			"totals per backend (datasource UID, or 'grafana' for the Grafana API), responses per status class, "+
+			"transport errors (including timeouts), and requests served from an identical request already in flight."),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// Resource handler
+func statsHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	jsonData, err := grafana.MarshalJSON(grafana.Stats())
+	if err != nil {
+		return nil, fmt.Errorf("marshalling stats: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      "grafana://stats",
+			MIMEType: "application/json",
+			Text:     string(jsonData),
+		},
+	}, nil
+}
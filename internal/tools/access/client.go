@@ -0,0 +1,66 @@
+// Package access provides read-only MCP tools for reviewing who can do what in Grafana: teams, org users,
+// and folder permissions. Most of these endpoints need an org Admin service account.
+package access
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+const (
+	// DefaultLimit is the default number of teams or users returned.
+	DefaultLimit = 100
+
+	// MaxLimit is the maximum number of teams or users returned.
+	MaxLimit = 1000
+)
+
+// client provides methods for interacting with Grafana's team, user, and permission APIs.
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// newClient creates a new access client.
+func newClient() (*client, error) {
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		httpClient: httpClient,
+		baseURL:    grafanaURL,
+	}, nil
+}
+
+// makeRequest performs an HTTP request and returns the response body.
+// A 403 is reported as a missing-permission error rather than a raw API error.
+func (c *client) makeRequest(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{Method: method, URL: c.baseURL + path, Params: params})
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("permission denied (403) for %s: the service account needs the org Admin role "+
+			"(or the matching read permission under RBAC) to use this tool", path)
+	}
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
+	}
+
+	return bodyBytes, nil
+}
+
+// clampLimit applies DefaultLimit and MaxLimit to a requested limit.
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	return min(limit, MaxLimit)
+}
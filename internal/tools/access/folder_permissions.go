@@ -0,0 +1,129 @@
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rawFolderPermission is one ACL entry as returned by the folder permissions API.
+// Exactly one of the user, team, or role fields identifies who the entry applies to.
+type rawFolderPermission struct {
+	UserID         int64  `json:"userId"`
+	UserLogin      string `json:"userLogin"`
+	UserEmail      string `json:"userEmail"`
+	TeamID         int64  `json:"teamId"`
+	Team           string `json:"team"`
+	Role           string `json:"role"`
+	Permission     int    `json:"permission"`
+	PermissionName string `json:"permissionName"`
+	Inherited      bool   `json:"inherited"`
+}
+
+// FolderPermission is one ACL entry: who it applies to and the level it grants.
+type FolderPermission struct {
+	Kind       string `json:"kind"` // "user", "team", or "role"
+	Name       string `json:"name"` // User login, team name, or org role (Viewer, Editor, Admin)
+	Email      string `json:"email,omitempty"`
+	ID         int64  `json:"id,omitempty"`        // User or team ID
+	Permission string `json:"permission"`          // View, Edit, or Admin
+	Inherited  bool   `json:"inherited,omitempty"` // Granted by a parent folder
+}
+
+// FolderPermissions is the output of get_folder_permissions.
+type FolderPermissions struct {
+	FolderUID   string             `json:"folderUid"`
+	Permissions []FolderPermission `json:"permissions"`
+}
+
+type getFolderPermissionsParams struct {
+	FolderUID string `json:"folderUid"`
+}
+
+// permissionNames maps the legacy numeric permission levels to their names, for responses without permissionName.
+var permissionNames = map[int]string{1: "View", 2: "Edit", 4: "Admin"}
+
+// getFolderPermissions gets the ACL entries of a folder.
+func (c *client) getFolderPermissions(ctx context.Context, folderUID string) ([]FolderPermission, error) {
+	path := fmt.Sprintf("/api/folders/%s/permissions", url.PathEscape(folderUID))
+	bodyBytes, err := c.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []rawFolderPermission
+	if err := json.Unmarshal(bodyBytes, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshalling folder permissions: %w", err)
+	}
+
+	permissions := make([]FolderPermission, 0, len(raw))
+	for _, r := range raw {
+		p := FolderPermission{Permission: r.PermissionName, Inherited: r.Inherited}
+		if p.Permission == "" {
+			p.Permission = permissionNames[r.Permission]
+		}
+		switch {
+		case r.UserID != 0:
+			p.Kind, p.Name, p.Email, p.ID = "user", r.UserLogin, r.UserEmail, r.UserID
+		case r.TeamID != 0:
+			p.Kind, p.Name, p.ID = "team", r.Team, r.TeamID
+		default:
+			p.Kind, p.Name = "role", r.Role
+		}
+		permissions = append(permissions, p)
+	}
+	return permissions, nil
+}
+
+func getFolderPermissionsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params getFolderPermissionsParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.FolderUID == "" {
+		return mcp.NewToolResultError("folderUid is required"), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating access client: %v", err)), nil
+	}
+
+	permissions, err := c.getFolderPermissions(ctx, params.FolderUID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(FolderPermissions{FolderUID: params.FolderUID, Permissions: permissions})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newGetFolderPermissionsTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_folder_permissions",
+		mcp.WithDescription("Gets a folder's permission entries: which users, teams, and org roles (Viewer, Editor, Admin) "+
+			"hold View, Edit, or Admin on it, and whether each entry is inherited from a parent folder. "+
+			"This is the authoritative answer to 'who can modify the dashboards or alert rules in this folder'; "+
+			"resolve team members with list_teams and org roles with list_org_users. "+
+			"Folder UIDs appear in search_dashboards results (folderUid). "+
+			"Requires a service account with Admin on the folder (or folders.permissions:read); a 403 is reported as a permission error."),
+		mcp.WithString("folderUid",
+			mcp.Description("The UID of the folder"),
+			mcp.Required(),
+		),
+	)
+}
+
+// RegisterGetFolderPermissions registers the get_folder_permissions tool.
+func RegisterGetFolderPermissions(s grafana.ToolRegistry) {
+	s.AddTool(newGetFolderPermissionsTool(), getFolderPermissionsHandler)
+}
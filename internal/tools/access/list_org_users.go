@@ -0,0 +1,115 @@
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// OrgUser is a member of the current organization and their org role.
+type OrgUser struct {
+	UserID        int64  `json:"userId"`
+	Login         string `json:"login"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	Role          string `json:"role"` // Viewer, Editor, Admin, or None
+	LastSeenAtAge string `json:"lastSeenAtAge,omitempty"`
+	IsDisabled    bool   `json:"isDisabled,omitempty"`
+}
+
+type listOrgUsersParams struct {
+	Query string `json:"query,omitempty"`
+	Role  string `json:"role,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// listOrgUsers gets all users of the current organization.
+func (c *client) listOrgUsers(ctx context.Context) ([]OrgUser, error) {
+	bodyBytes, err := c.makeRequest(ctx, "GET", "/api/org/users", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []OrgUser
+	if err := json.Unmarshal(bodyBytes, &users); err != nil {
+		return nil, fmt.Errorf("unmarshalling org users: %w", err)
+	}
+	return users, nil
+}
+
+// filterOrgUsers keeps users whose login, name, or email contains query and whose role matches role,
+// both case-insensitively. Empty filters match everyone.
+func filterOrgUsers(users []OrgUser, query, role string) []OrgUser {
+	query = strings.ToLower(query)
+	filtered := make([]OrgUser, 0, len(users))
+	for _, u := range users {
+		if role != "" && !strings.EqualFold(u.Role, role) {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(u.Login), query) &&
+			!strings.Contains(strings.ToLower(u.Name), query) &&
+			!strings.Contains(strings.ToLower(u.Email), query) {
+			continue
+		}
+		filtered = append(filtered, u)
+	}
+	return filtered
+}
+
+func listOrgUsersHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params listOrgUsersParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating access client: %v", err)), nil
+	}
+
+	users, err := c.listOrgUsers(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	users = filterOrgUsers(users, params.Query, params.Role)
+	if limit := clampLimit(params.Limit); len(users) > limit {
+		users = users[:limit]
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(users, len(users)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newListOrgUsersTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_org_users",
+		mcp.WithDescription("Lists users of the current Grafana organization with login, name, email, org role "+
+			"(Viewer, Editor, Admin), and when they were last seen. Org Editors and Admins can edit most folders "+
+			"unless folder permissions say otherwise; use get_folder_permissions for the per-folder picture. "+
+			"Requires a service account with the org Admin role (or org.users:read); a 403 is reported as a permission error."),
+		mcp.WithString("query",
+			mcp.Description("Filter users whose login, name, or email contains this text (case-insensitive)"),
+		),
+		mcp.WithString("role",
+			mcp.Description("Only return users with this org role: 'Viewer', 'Editor', 'Admin', or 'None'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of users to return (default: %d, max: %d)", DefaultLimit, MaxLimit)),
+		),
+	)
+}
+
+// RegisterListOrgUsers registers the list_org_users tool.
+func RegisterListOrgUsers(s grafana.ToolRegistry) {
+	s.AddTool(newListOrgUsersTool(), listOrgUsersHandler)
+}
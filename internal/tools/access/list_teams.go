@@ -0,0 +1,101 @@
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Team is a Grafana team as returned by the team search API.
+type Team struct {
+	ID          int64  `json:"id"`
+	UID         string `json:"uid,omitempty"`
+	Name        string `json:"name"`
+	Email       string `json:"email,omitempty"`
+	MemberCount int    `json:"memberCount"`
+}
+
+// TeamList is the output of list_teams.
+type TeamList struct {
+	TotalCount int    `json:"totalCount"` // Matching teams, including any beyond the limit
+	Teams      []Team `json:"teams"`
+}
+
+type listTeamsParams struct {
+	Query string `json:"query,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// listTeams searches teams by name.
+func (c *client) listTeams(ctx context.Context, query string, limit int) (*TeamList, error) {
+	params := url.Values{}
+	params.Set("perpage", strconv.Itoa(limit))
+	params.Set("page", "1")
+	if query != "" {
+		params.Set("query", query)
+	}
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", "/api/teams/search", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result TeamList
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return nil, fmt.Errorf("unmarshalling teams: %w", err)
+	}
+
+	if result.Teams == nil {
+		result.Teams = []Team{}
+	}
+	return &result, nil
+}
+
+func listTeamsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params listTeamsParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating access client: %v", err)), nil
+	}
+
+	result, err := c.listTeams(ctx, params.Query, clampLimit(params.Limit))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newListTeamsTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_teams",
+		mcp.WithDescription("Lists Grafana teams with their ID, name, email, and member count. "+
+			"Combine with get_folder_permissions to see which teams can view or edit a folder's dashboards and alerts. "+
+			"Requires a service account with the org Admin role (or teams:read); a 403 is reported as a permission error."),
+		mcp.WithString("query",
+			mcp.Description("Filter teams by name (substring match)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of teams to return (default: %d, max: %d)", DefaultLimit, MaxLimit)),
+		),
+	)
+}
+
+// RegisterListTeams registers the list_teams tool.
+func RegisterListTeams(s grafana.ToolRegistry) {
+	s.AddTool(newListTeamsTool(), listTeamsHandler)
+}
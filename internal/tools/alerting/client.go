@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 
@@ -38,29 +37,13 @@ func newClient() (*client, error) {
 
 // makeRequest performs an HTTP request and returns the response body.
 func (c *client) makeRequest(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
-	reqURL := c.baseURL + path
-	if len(params) > 0 {
-		reqURL += "?" + params.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{Method: method, URL: c.baseURL + path, Params: params})
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
 	}
 
 	return bodyBytes, nil
@@ -81,6 +64,8 @@ type Rule struct {
 	Data         []QueryData       `json:"data,omitempty"`
 	Updated      string            `json:"updated,omitempty"`
 	IsPaused     bool              `json:"isPaused"`
+
+	Conditions []ConditionSummary `json:"conditions,omitempty"` // Parsed locally from Data, not part of Grafana's response
 }
 
 // QueryData represents query data within an alert rule.
@@ -132,6 +117,7 @@ type prometheusRule struct {
 	State          string            `json:"state"`
 	Health         string            `json:"health"`
 	Type           string            `json:"type"`
+	LastError      string            `json:"lastError,omitempty"`
 	LastEvaluation string            `json:"lastEvaluation,omitempty"`
 	EvaluationTime float64           `json:"evaluationTime,omitempty"`
 }
@@ -156,6 +142,34 @@ func (c *client) listRules(ctx context.Context, limit int) ([]Rule, error) {
 	return rules, nil
 }
 
+// folder is a Grafana folder as returned by the folders API.
+type folder struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// getFolderTitles returns a map of folder UID to folder title.
+func (c *client) getFolderTitles(ctx context.Context) (map[string]string, error) {
+	params := url.Values{}
+	params.Add("limit", "1000")
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", "/api/folders", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []folder
+	if err := json.Unmarshal(bodyBytes, &folders); err != nil {
+		return nil, fmt.Errorf("unmarshalling folders: %w", err)
+	}
+
+	titles := make(map[string]string, len(folders))
+	for _, f := range folders {
+		titles[f.UID] = f.Title
+	}
+	return titles, nil
+}
+
 // getRuleByUID gets a specific alert rule by UID.
 func (c *client) getRuleByUID(ctx context.Context, uid string) (*Rule, error) {
 	path := fmt.Sprintf("/api/v1/provisioning/alert-rules/%s", url.PathEscape(uid))
@@ -172,6 +186,95 @@ func (c *client) getRuleByUID(ctx context.Context, uid string) (*Rule, error) {
 	return &rule, nil
 }
 
+// ruleGroupResponse represents a rule group from the provisioning API.
+type ruleGroupResponse struct {
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+	Interval  int    `json:"interval"` // Seconds
+	Rules     []Rule `json:"rules"`
+}
+
+// getRuleGroup gets an alert rule group, with its rules in evaluation order.
+func (c *client) getRuleGroup(ctx context.Context, folderUID, group string) (*ruleGroupResponse, error) {
+	path := fmt.Sprintf("/api/v1/provisioning/folder/%s/rule-groups/%s", url.PathEscape(folderUID), url.PathEscape(group))
+	bodyBytes, err := c.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ruleGroupResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return nil, fmt.Errorf("unmarshalling rule group: %w", err)
+	}
+
+	return &response, nil
+}
+
+// RecordingRule describes a Prometheus-style recording rule and its evaluation status.
+type RecordingRule struct {
+	Name           string            `json:"name"`
+	Query          string            `json:"query"`
+	RuleGroup      string            `json:"ruleGroup"`
+	File           string            `json:"file,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Health         string            `json:"health"`
+	LastError      string            `json:"lastError,omitempty"`
+	LastEvaluation string            `json:"lastEvaluation,omitempty"`
+	EvaluationTime float64           `json:"evaluationTime,omitempty"` // Seconds
+}
+
+// getRecordingRules gets recording rules from the Prometheus-style rules API.
+// rulesSource is "grafana" for Grafana-managed rules or a datasource UID for datasource-managed rules.
+func (c *client) getRecordingRules(ctx context.Context, rulesSource string) ([]RecordingRule, error) {
+	if rulesSource != grafanaRulesSource {
+		uid, err := grafana.ResolveDatasourceUID(ctx, rulesSource, "prometheus", "loki")
+		if err != nil {
+			return nil, err
+		}
+		if err := grafana.CheckDatasource(uid); err != nil {
+			return nil, err
+		}
+		rulesSource = uid
+	}
+
+	path := fmt.Sprintf("/api/prometheus/%s/api/v1/rules", url.PathEscape(rulesSource))
+	params := url.Values{}
+	params.Add("type", "record")
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", path, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp prometheusRulesResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshalling rules response: %w", err)
+	}
+
+	rules := []RecordingRule{}
+	for _, group := range resp.Data.Groups {
+		for _, rule := range group.Rules {
+			// Not every backend honours the type filter, so check again
+			if rule.Type != "recording" {
+				continue
+			}
+			rules = append(rules, RecordingRule{
+				Name:           rule.Name,
+				Query:          rule.Query,
+				RuleGroup:      group.Name,
+				File:           group.File,
+				Labels:         rule.Labels,
+				Health:         rule.Health,
+				LastError:      rule.LastError,
+				LastEvaluation: rule.LastEvaluation,
+				EvaluationTime: rule.EvaluationTime,
+			})
+		}
+	}
+
+	return rules, nil
+}
+
 // getRulesWithState gets alert rules with their current state from the Prometheus-style API.
 func (c *client) getRulesWithState(ctx context.Context) ([]RuleSummary, error) {
 	bodyBytes, err := c.makeRequest(ctx, "GET", "/api/prometheus/grafana/api/v1/rules", nil)
@@ -0,0 +1,164 @@
+package alerting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expressionDatasourceUID is the pseudo-datasource UID of Grafana server-side expressions.
+const expressionDatasourceUID = "__expr__"
+
+// evaluatorOperators maps Grafana evaluator types to comparison operators.
+var evaluatorOperators = map[string]string{
+	"gt": ">",
+	"lt": "<",
+	"ge": ">=",
+	"le": "<=",
+	"eq": "==",
+	"ne": "!=",
+}
+
+// ConditionSummary is a readable form of one server-side expression in an alert rule,
+// e.g., the threshold it fires at and how the input series is reduced.
+type ConditionSummary struct {
+	RefID       string    `json:"refId"`
+	Type        string    `json:"type"`                  // threshold, reduce, math, classic_conditions, resample
+	Input       string    `json:"input,omitempty"`       // RefID the expression reads from
+	Reducer     string    `json:"reducer,omitempty"`     // e.g., last, mean, max
+	Evaluator   string    `json:"evaluator,omitempty"`   // e.g., gt, lt, within_range
+	Threshold   []float64 `json:"threshold,omitempty"`   // Evaluator parameters
+	Expression  string    `json:"expression,omitempty"`  // Math expression
+	Description string    `json:"description"`           // Human-readable summary, e.g., "B > 80"
+	IsCondition bool      `json:"isCondition,omitempty"` // The expression that decides whether the rule fires
+}
+
+// parseConditions extracts the threshold, reduce, math, and classic condition expressions
+// from a Grafana-managed alert rule's query data.
+func parseConditions(rule *Rule) []ConditionSummary {
+	var conditions []ConditionSummary
+
+	for _, d := range rule.Data {
+		if d.DatasourceUID != expressionDatasourceUID {
+			continue
+		}
+		model, ok := d.Model.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		c := ConditionSummary{
+			RefID:       d.RefID,
+			IsCondition: d.RefID == rule.Condition,
+		}
+		c.Type, _ = model["type"].(string)
+		input, _ := model["expression"].(string)
+
+		switch c.Type {
+		case "threshold":
+			c.Input = input
+			if evals := modelConditions(model); len(evals) > 0 {
+				c.Evaluator, c.Threshold = evaluator(evals[0])
+			}
+			c.Description = comparison(input, c.Evaluator, c.Threshold)
+
+		case "reduce":
+			c.Input = input
+			c.Reducer, _ = model["reducer"].(string)
+			c.Description = fmt.Sprintf("%s(%s)", c.Reducer, input)
+
+		case "math":
+			c.Expression = input
+			c.Description = input
+
+		case "resample":
+			c.Input = input
+			window, _ := model["window"].(string)
+			c.Description = fmt.Sprintf("resample(%s, %s)", input, window)
+
+		case "classic_conditions":
+			var parts []string
+			for i, cond := range modelConditions(model) {
+				evalType, params := evaluator(cond)
+				reducer := nestedString(cond, "reducer", "type")
+				queryRef := ""
+				if query, ok := cond["query"].(map[string]any); ok {
+					if refs, ok := query["params"].([]any); ok && len(refs) > 0 {
+						queryRef, _ = refs[0].(string)
+					}
+				}
+				if i == 0 {
+					c.Input, c.Reducer, c.Evaluator, c.Threshold = queryRef, reducer, evalType, params
+				} else {
+					parts = append(parts, nestedString(cond, "operator", "type"))
+				}
+				parts = append(parts, comparison(fmt.Sprintf("%s(%s)", reducer, queryRef), evalType, params))
+			}
+			c.Description = strings.Join(parts, " ")
+
+		default:
+			c.Description = c.Type
+		}
+
+		conditions = append(conditions, c)
+	}
+
+	return conditions
+}
+
+// modelConditions returns the "conditions" list of an expression model.
+func modelConditions(model map[string]any) []map[string]any {
+	list, ok := model["conditions"].([]any)
+	if !ok {
+		return nil
+	}
+	var conditions []map[string]any
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			conditions = append(conditions, m)
+		}
+	}
+	return conditions
+}
+
+// evaluator extracts the evaluator type and numeric parameters from a condition.
+func evaluator(cond map[string]any) (string, []float64) {
+	eval, ok := cond["evaluator"].(map[string]any)
+	if !ok {
+		return "", nil
+	}
+	evalType, _ := eval["type"].(string)
+
+	var params []float64
+	if raw, ok := eval["params"].([]any); ok {
+		for _, p := range raw {
+			if f, ok := p.(float64); ok {
+				params = append(params, f)
+			}
+		}
+	}
+	return evalType, params
+}
+
+// comparison renders an evaluator as a readable expression, e.g., "B > 80" or "B within 10..20".
+func comparison(input, evalType string, params []float64) string {
+	if op, ok := evaluatorOperators[evalType]; ok && len(params) > 0 {
+		return fmt.Sprintf("%s %s %g", input, op, params[0])
+	}
+	if strings.HasPrefix(evalType, "within_range") && len(params) > 1 {
+		return fmt.Sprintf("%s within %g..%g", input, params[0], params[1])
+	}
+	if strings.HasPrefix(evalType, "outside_range") && len(params) > 1 {
+		return fmt.Sprintf("%s outside %g..%g", input, params[0], params[1])
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s", input, evalType))
+}
+
+// nestedString reads m[key][field] as a string.
+func nestedString(m map[string]any, key, field string) string {
+	inner, ok := m[key].(map[string]any)
+	if !ok {
+		return ""
+	}
+	s, _ := inner[field].(string)
+	return s
+}
@@ -2,15 +2,15 @@ package alerting
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type getRuleByUIDParams struct {
-	UID string `json:"uid"`
+	UID    string   `json:"uid"`
+	Fields []string `json:"fields,omitempty"`
 }
 
 func getRuleByUIDHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -33,7 +33,14 @@ func getRuleByUIDHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	jsonData, err := json.MarshalIndent(rule, "", "  ")
+	rule.Conditions = parseConditions(rule)
+
+	projected, err := grafana.ProjectFields(rule, params.Fields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(projected)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -46,16 +53,22 @@ func newGetRuleByUIDTool() mcp.Tool {
 		"get_alert_rule_by_uid",
 		mcp.WithDescription("Gets the full details of a Grafana alert rule by its UID. "+
 			"Returns complete rule configuration including query definitions, conditions, "+
-			"thresholds, and notification settings. "+
+			"thresholds, and notification settings, plus a conditions summary of the rule's expressions "+
+			"(input, reducer, evaluator, and threshold values, e.g., \"B > 80\"). "+
 			"Use list_alert_rules first to find rule UIDs."),
 		mcp.WithString("uid",
 			mcp.Description("The UID of the alert rule"),
 			mcp.Required(),
 		),
+		mcp.WithArray("fields",
+			mcp.Description("Optional list of fields to return instead of the whole rule, as top-level keys or dotted paths "+
+				"(e.g., [\"title\", \"condition\", \"data.model.expr\"])"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
 	)
 }
 
 // RegisterGetRuleByUID registers the get_alert_rule_by_uid tool.
-func RegisterGetRuleByUID(s *server.MCPServer) {
+func RegisterGetRuleByUID(s grafana.ToolRegistry) {
 	s.AddTool(newGetRuleByUIDTool(), getRuleByUIDHandler)
 }
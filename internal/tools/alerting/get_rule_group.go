@@ -0,0 +1,94 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type getRuleGroupParams struct {
+	FolderUID string `json:"folderUid"`
+	Group     string `json:"group"`
+}
+
+// RuleGroup describes an alert rule group: rules in a group are evaluated together, in order, at the group's interval.
+type RuleGroup struct {
+	Title           string        `json:"title"`
+	FolderUID       string        `json:"folderUID"`
+	IntervalSeconds int           `json:"intervalSeconds"`
+	Interval        string        `json:"interval"` // Human-readable, e.g., "1m0s"
+	Rules           []RuleSummary `json:"rules"`    // In evaluation order
+}
+
+func getRuleGroupHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params getRuleGroupParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.FolderUID == "" || params.Group == "" {
+		return mcp.NewToolResultError("folderUid and group are required"), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating alerting client: %v", err)), nil
+	}
+
+	group, err := c.getRuleGroup(ctx, params.FolderUID, params.Group)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := RuleGroup{
+		Title:           group.Title,
+		FolderUID:       group.FolderUID,
+		IntervalSeconds: group.Interval,
+		Interval:        (time.Duration(group.Interval) * time.Second).String(),
+		Rules:           make([]RuleSummary, 0, len(group.Rules)),
+	}
+	for _, r := range group.Rules {
+		result.Rules = append(result.Rules, RuleSummary{
+			UID:         r.UID,
+			Title:       r.Title,
+			FolderUID:   r.FolderUID,
+			RuleGroup:   r.RuleGroup,
+			For:         r.For,
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+			IsPaused:    r.IsPaused,
+		})
+	}
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newGetRuleGroupTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_alert_rule_group",
+		mcp.WithDescription("Gets a Grafana alert rule group: its evaluation interval and its rules in evaluation order. "+
+			"Rules in a group share one interval and are evaluated sequentially, so a large group or short interval can delay evaluation. "+
+			"Use list_alert_rules (the folderUID and ruleGroup fields) to find the folder and group names."),
+		mcp.WithString("folderUid",
+			mcp.Description("The UID of the folder containing the rule group"),
+			mcp.Required(),
+		),
+		mcp.WithString("group",
+			mcp.Description("The name of the rule group"),
+			mcp.Required(),
+		),
+	)
+}
+
+// RegisterGetRuleGroup registers the get_alert_rule_group tool.
+func RegisterGetRuleGroup(s grafana.ToolRegistry) {
+	s.AddTool(newGetRuleGroupTool(), getRuleGroupHandler)
+}
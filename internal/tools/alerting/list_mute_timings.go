@@ -0,0 +1,85 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MuteTiming is a named set of time intervals during which matching notifications are suppressed.
+type MuteTiming struct {
+	Name          string         `json:"name"`
+	TimeIntervals []TimeInterval `json:"time_intervals"`
+	Provenance    string         `json:"provenance,omitempty"`
+}
+
+// TimeInterval is one recurring window of a mute timing. Empty fields match any value.
+type TimeInterval struct {
+	Times       []TimeRange `json:"times,omitempty"`
+	Weekdays    []string    `json:"weekdays,omitempty"`      // e.g., "saturday", "monday:friday"
+	DaysOfMonth []string    `json:"days_of_month,omitempty"` // e.g., "1", "-1" (last day), "1:5"
+	Months      []string    `json:"months,omitempty"`        // e.g., "january", "1:3"
+	Years       []string    `json:"years,omitempty"`
+	Location    string      `json:"location,omitempty"` // IANA time zone, UTC when unset
+}
+
+// TimeRange is a time-of-day window in HH:MM format.
+type TimeRange struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// listMuteTimings gets all mute timings from the provisioning API.
+func (c *client) listMuteTimings(ctx context.Context) ([]MuteTiming, error) {
+	bodyBytes, err := c.makeRequest(ctx, "GET", "/api/v1/provisioning/mute-timings", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var timings []MuteTiming
+	if err := json.Unmarshal(bodyBytes, &timings); err != nil {
+		return nil, fmt.Errorf("unmarshalling mute timings: %w", err)
+	}
+
+	if timings == nil {
+		timings = []MuteTiming{}
+	}
+	return timings, nil
+}
+
+func listMuteTimingsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating alerting client: %v", err)), nil
+	}
+
+	timings, err := c.listMuteTimings(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(timings, len(timings)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newListMuteTimingsTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_mute_timings",
+		mcp.WithDescription("Lists Grafana alerting mute timings: each timing's name and time intervals "+
+			"(times of day, weekdays, days of month, months, years, and time zone). "+
+			"Notification policies reference mute timings by name to suppress notifications during those windows, "+
+			"so check these when an alert fires but does not notify (e.g., a timing that mutes weekends)."),
+	)
+}
+
+// RegisterListMuteTimings registers the list_mute_timings tool.
+func RegisterListMuteTimings(s grafana.ToolRegistry) {
+	s.AddTool(newListMuteTimingsTool(), listMuteTimingsHandler)
+}
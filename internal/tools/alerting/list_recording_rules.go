@@ -0,0 +1,85 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// grafanaRulesSource selects Grafana-managed rules in the Prometheus-style rules API.
+const grafanaRulesSource = "grafana"
+
+type listRecordingRulesParams struct {
+	DatasourceUID string `json:"datasourceUid,omitempty"`
+	Query         string `json:"query,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+}
+
+func listRecordingRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params listRecordingRulesParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	source := params.DatasourceUID
+	if source == "" {
+		source = grafanaRulesSource
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultRulesLimit
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating alerting client: %v", err)), nil
+	}
+
+	rules, err := c.getRecordingRules(ctx, source)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	query := strings.ToLower(params.Query)
+	matched := []RecordingRule{}
+	for _, r := range rules {
+		if query != "" && !strings.Contains(strings.ToLower(r.Name), query) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(grafana.LimitList(matched, limit), min(len(matched), limit)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newListRecordingRulesTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_recording_rules",
+		mcp.WithDescription("Lists recording rules with their name, PromQL expression, group, health, and last evaluation. "+
+			"Lists Grafana-managed recording rules by default; set datasourceUid to list the rules evaluated by a Prometheus or Loki datasource. "+
+			"Use this to look up the definition of a recording rule referenced by a dashboard query."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("UID of a Prometheus/Loki datasource whose rules to list (defaults to Grafana-managed rules)"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Case-insensitive substring to match against rule names, e.g., 'job:http_requests'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of rules to return (default: 100). When more match, returns {values, _truncated: true, _totalBeforeLimit}"),
+		),
+	)
+}
+
+// RegisterListRecordingRules registers the list_recording_rules tool.
+func RegisterListRecordingRules(s grafana.ToolRegistry) {
+	s.AddTool(newListRecordingRulesTool(), listRecordingRulesHandler)
+}
@@ -2,16 +2,26 @@ package alerting
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sort"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listRulesParams struct {
-	Limit        int  `json:"limit,omitempty"`
-	IncludeState bool `json:"includeState,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	IncludeState bool   `json:"includeState,omitempty"`
+	GroupBy      string `json:"groupBy,omitempty"` // "folder", "group", or "state"
+}
+
+// RuleGroupResult is a set of rule summaries sharing a folder, rule group, or state.
+type RuleGroupResult struct {
+	Key         string         `json:"key"`
+	Title       string         `json:"title,omitempty"` // Folder title when grouping by folder
+	Count       int            `json:"count"`
+	StateCounts map[string]int `json:"stateCounts,omitempty"`
+	Rules       []RuleSummary  `json:"rules"`
 }
 
 // alertStateKey creates a key for matching alerts across APIs.
@@ -30,6 +40,15 @@ func listRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		return mcp.NewToolResultError(fmt.Sprintf("creating alerting client: %v", err)), nil
 	}
 
+	switch params.GroupBy {
+	case "", "folder", "group":
+	case "state":
+		// Grouping by state needs the state enrichment
+		params.IncludeState = true
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid groupBy: %s (must be 'folder', 'group', or 'state')", params.GroupBy)), nil
+	}
+
 	limit := params.Limit
 	if limit <= 0 {
 		limit = DefaultRulesLimit
@@ -41,6 +60,12 @@ func listRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	// A full page means more rules likely exist; how many is only known if Grafana ignored the limit
+	limitReached, totalBeforeLimit := len(rules) >= limit, 0
+	if len(rules) > limit {
+		rules, totalBeforeLimit = rules[:limit], len(rules)
+	}
+
 	// Build state map if state is requested
 	stateMap := make(map[string]RuleSummary)
 	if params.IncludeState {
@@ -81,7 +106,30 @@ func listRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		summaries = append(summaries, summary)
 	}
 
-	jsonData, err := json.MarshalIndent(summaries, "", "  ")
+	var result any = summaries
+	if limitReached {
+		result = grafana.TruncatedList[RuleSummary]{Values: summaries, Truncated: true, TotalBeforeLimit: totalBeforeLimit}
+	}
+	if params.GroupBy != "" {
+		var folderTitles map[string]string
+		if params.GroupBy == "folder" {
+			// Titles are best-effort; the folder UID is still the group key without them
+			folderTitles, _ = c.getFolderTitles(ctx)
+		}
+		groups := groupRules(summaries, params.GroupBy, folderTitles)
+		result = groups
+		if limitReached {
+			result = grafana.TruncatedList[RuleGroupResult]{Values: groups, Truncated: true, TotalBeforeLimit: totalBeforeLimit}
+		}
+	}
+
+	var warnings []string
+	if limitReached {
+		warnings = append(warnings, fmt.Sprintf("Returned the maximum of %d rules, so more rules likely exist. "+
+			"Raise limit, or use search_alert_rules to filter by title, label, or folder.", limit))
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(result, warnings...))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -89,12 +137,61 @@ func listRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
+// groupRules nests rule summaries by folder, rule group, or state, sorted by key.
+func groupRules(summaries []RuleSummary, groupBy string, folderTitles map[string]string) []RuleGroupResult {
+	groups := make(map[string]*RuleGroupResult)
+	var keys []string
+
+	for _, s := range summaries {
+		var key string
+		switch groupBy {
+		case "folder":
+			key = s.FolderUID
+		case "group":
+			key = s.FolderUID + "/" + s.RuleGroup
+		case "state":
+			key = s.State
+			if key == "" {
+				key = "unknown"
+			}
+		}
+
+		g, ok := groups[key]
+		if !ok {
+			g = &RuleGroupResult{Key: key, Title: folderTitles[key], Rules: []RuleSummary{}}
+			if groupBy == "group" {
+				g.Title = s.RuleGroup
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+
+		g.Count++
+		g.Rules = append(g.Rules, s)
+		if s.State != "" && groupBy != "state" {
+			if g.StateCounts == nil {
+				g.StateCounts = make(map[string]int)
+			}
+			g.StateCounts[s.State]++
+		}
+	}
+
+	sort.Strings(keys)
+	result := make([]RuleGroupResult, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *groups[key])
+	}
+	return result
+}
+
 func newListRulesTool() mcp.Tool {
 	return mcp.NewTool(
 		"list_alert_rules",
 		mcp.WithDescription("Lists Grafana alert rules with optional state information. "+
 			"Returns rule UID, title, folder, group, labels, annotations, and pause status. "+
 			"When includeState is true, also includes current firing state and health. "+
+			"Set groupBy to nest the results by folder, rule group, or state, with per-group counts. "+
+			"A list that reached limit is marked _truncated. "+
 			"Use get_alert_rule_by_uid for full rule details including query definitions."),
 		mcp.WithNumber("limit",
 			mcp.Description("Maximum number of rules to return (default: 100)"),
@@ -102,10 +199,13 @@ func newListRulesTool() mcp.Tool {
 		mcp.WithBoolean("includeState",
 			mcp.Description("Include current firing state and health from Prometheus-style API (default: false)"),
 		),
+		mcp.WithString("groupBy",
+			mcp.Description("Nest results by 'folder', 'group' (folder/rule group), or 'state' (implies includeState)"),
+		),
 	)
 }
 
 // RegisterListRules registers the list_alert_rules tool.
-func RegisterListRules(s *server.MCPServer) {
+func RegisterListRules(s grafana.ToolRegistry) {
 	s.AddTool(newListRulesTool(), listRulesHandler)
 }
@@ -0,0 +1,152 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type searchRulesParams struct {
+	Query         string `json:"query,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+}
+
+// labelMatcher is a single equality or inequality matcher from a label selector.
+type labelMatcher struct {
+	name   string
+	value  string
+	negate bool
+}
+
+// parseLabelSelector parses a comma-separated selector such as "severity=critical,team!=infra".
+func parseLabelSelector(selector string) ([]labelMatcher, error) {
+	var matchers []labelMatcher
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		m := labelMatcher{}
+		name, value, ok := strings.Cut(part, "!=")
+		if ok {
+			m.negate = true
+		} else if name, value, ok = strings.Cut(part, "="); !ok {
+			return nil, fmt.Errorf("invalid label matcher %q (expected name=value or name!=value)", part)
+		}
+		m.name = strings.TrimSpace(name)
+		m.value = strings.Trim(strings.TrimSpace(value), `"`)
+		if m.name == "" {
+			return nil, fmt.Errorf("invalid label matcher %q: missing label name", part)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// matchesRule reports whether a rule satisfies the text query and all label matchers.
+func matchesRule(r Rule, query string, matchers []labelMatcher) bool {
+	for _, m := range matchers {
+		if (r.Labels[m.name] == m.value) == m.negate {
+			return false
+		}
+	}
+
+	if query == "" {
+		return true
+	}
+	if strings.Contains(strings.ToLower(r.Title), query) {
+		return true
+	}
+	for _, v := range r.Annotations {
+		if strings.Contains(strings.ToLower(v), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func searchRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params searchRulesParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Query == "" && params.LabelSelector == "" {
+		return mcp.NewToolResultError("at least one of query or labelSelector is required"), nil
+	}
+
+	matchers, err := parseLabelSelector(params.LabelSelector)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultRulesLimit
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating alerting client: %v", err)), nil
+	}
+
+	// Fetch every rule so the filter sees the whole list, then apply the limit to the matches
+	rules, err := c.listRules(ctx, 0)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	query := strings.ToLower(params.Query)
+	summaries := []RuleSummary{}
+	for _, r := range rules {
+		if !matchesRule(r, query, matchers) {
+			continue
+		}
+		summaries = append(summaries, RuleSummary{
+			UID:         r.UID,
+			Title:       r.Title,
+			FolderUID:   r.FolderUID,
+			RuleGroup:   r.RuleGroup,
+			For:         r.For,
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+			IsPaused:    r.IsPaused,
+		})
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(grafana.LimitList(summaries, limit), min(len(summaries), limit)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newSearchRulesTool() mcp.Tool {
+	return mcp.NewTool(
+		"search_alert_rules",
+		mcp.WithDescription("Searches Grafana alert rules by text and labels. "+
+			"query is a case-insensitive substring matched against the rule title and annotation values (e.g., 'disk space'); "+
+			"labelSelector filters on rule labels. Both are applied together when given. "+
+			"Returns the same summaries as list_alert_rules; use get_alert_rule_by_uid for full rule details."),
+		mcp.WithString("query",
+			mcp.Description("Case-insensitive text to find in rule titles and annotations"),
+		),
+		mcp.WithString("labelSelector",
+			mcp.Description("Comma-separated label matchers using = or !=, e.g., 'severity=critical,team!=infra'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of matching rules to return (default: 100). When more match, returns {values, _truncated: true, _totalBeforeLimit}"),
+		),
+	)
+}
+
+// RegisterSearchRules registers the search_alert_rules tool.
+func RegisterSearchRules(s grafana.ToolRegistry) {
+	s.AddTool(newSearchRulesTool(), searchRulesHandler)
+}
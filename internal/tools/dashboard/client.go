@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 
@@ -38,29 +37,13 @@ func newClient() (*client, error) {
 
 // makeRequest performs an HTTP request and returns the response body.
 func (c *client) makeRequest(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
-	reqURL := c.baseURL + path
-	if len(params) > 0 {
-		reqURL += "?" + params.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{Method: method, URL: c.baseURL + path, Params: params})
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
 	}
 
 	return bodyBytes, nil
@@ -0,0 +1,169 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultDashboardMaxBytes is the default size above which get_dashboard returns an overview instead of the JSON.
+const DefaultDashboardMaxBytes = 100 * 1024
+
+type getDashboardParams struct {
+	UID      string   `json:"uid"`
+	PanelID  int      `json:"panelId,omitempty"`
+	MaxBytes int      `json:"maxBytes,omitempty"`
+	Fields   []string `json:"fields,omitempty"`
+}
+
+// PanelResult is the output of get_dashboard when a single panel is requested.
+type PanelResult struct {
+	UID   string `json:"uid"`
+	Panel any    `json:"panel"`
+}
+
+// DashboardOverview replaces a dashboard whose JSON exceeds maxBytes.
+type DashboardOverview struct {
+	Summary   *Summary `json:"summary"`
+	PanelIDs  []int    `json:"panelIds"`
+	SizeBytes int      `json:"sizeBytes"`
+	Note      string   `json:"note"`
+}
+
+// findPanel returns the panel with the given ID, including panels nested in collapsed rows.
+func findPanel(panels []any, id int) (map[string]any, bool) {
+	for _, p := range panels {
+		panelMap, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if pid, ok := panelMap["id"].(float64); ok && int(pid) == id {
+			return panelMap, true
+		}
+		if nested, ok := panelMap["panels"].([]any); ok {
+			if found, ok := findPanel(nested, id); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// panelIDs lists the IDs of all panels, including panels nested in collapsed rows.
+func panelIDs(panels []any) []int {
+	ids := []int{}
+	for _, p := range panels {
+		panelMap, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if id, ok := panelMap["id"].(float64); ok {
+			ids = append(ids, int(id))
+		}
+		if nested, ok := panelMap["panels"].([]any); ok {
+			ids = append(ids, panelIDs(nested)...)
+		}
+	}
+	return ids
+}
+
+func getDashboardHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params getDashboardParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.UID == "" {
+		return mcp.NewToolResultError("uid is required"), nil
+	}
+
+	maxBytes := params.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultDashboardMaxBytes
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating dashboard client: %v", err)), nil
+	}
+
+	dashResponse, err := c.getDashboardByUID(ctx, params.UID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var panels []any
+	if dashMap, ok := dashResponse.Dashboard.(map[string]any); ok {
+		panels, _ = dashMap["panels"].([]any)
+	}
+
+	var result any = dashResponse
+	if params.PanelID > 0 {
+		panel, ok := findPanel(panels, params.PanelID)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("panel %d not found in dashboard %s", params.PanelID, params.UID)), nil
+		}
+		result = PanelResult{UID: params.UID, Panel: panel}
+	}
+
+	// Projecting first lets a narrow field list fit under maxBytes on dashboards that would not
+	projected, err := grafana.ProjectFields(result, params.Fields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(projected)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	// Large dashboards can run to megabytes; return an overview the caller can drill into by panel instead
+	if len(jsonData) > maxBytes && params.PanelID == 0 {
+		overview := DashboardOverview{
+			Summary:   buildSummary(params.UID, dashResponse),
+			PanelIDs:  panelIDs(panels),
+			SizeBytes: len(jsonData),
+			Note: fmt.Sprintf("The dashboard JSON is %d bytes, over the %d byte limit. "+
+				"Call get_dashboard again with panelId to get a single panel's JSON, narrow it with fields, or raise maxBytes.", len(jsonData), maxBytes),
+		}
+		jsonData, err = grafana.MarshalJSON(overview)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+		}
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newGetDashboardTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_dashboard",
+		mcp.WithDescription("Gets the full JSON model and metadata of a Grafana dashboard, or of a single panel when panelId is set. "+
+			fmt.Sprintf("If the dashboard JSON exceeds maxBytes (default: %d), returns its summary and panel IDs instead, ", DefaultDashboardMaxBytes)+
+			"so request specific panels by panelId or narrow the output with fields. "+
+			"Prefer get_dashboard_summary or get_dashboard_panel_queries when they answer the question."),
+		mcp.WithString("uid",
+			mcp.Description("The UID of the dashboard"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("panelId",
+			mcp.Description("Return only the JSON of this panel (panels inside collapsed rows are included)"),
+		),
+		mcp.WithNumber("maxBytes",
+			mcp.Description(fmt.Sprintf("Size limit for the full dashboard JSON in bytes (default: %d)", DefaultDashboardMaxBytes)),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Optional list of fields to return, as top-level keys or dotted paths that descend through arrays "+
+				"(e.g., [\"dashboard.templating\", \"dashboard.panels.title\"], or [\"panel.targets\"] with panelId); "+
+				"maxBytes applies to the projected JSON"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+}
+
+// RegisterGetDashboard registers the get_dashboard tool.
+func RegisterGetDashboard(s grafana.ToolRegistry) {
+	s.AddTool(newGetDashboardTool(), getDashboardHandler)
+}
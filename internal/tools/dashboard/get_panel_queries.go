@@ -2,11 +2,10 @@ package dashboard
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type getPanelQueriesParams struct {
@@ -39,7 +38,7 @@ func getPanelQueriesHandler(ctx context.Context, request mcp.CallToolRequest) (*
 		queries = []PanelQuery{}
 	}
 
-	jsonData, err := json.MarshalIndent(queries, "", "  ")
+	jsonData, err := grafana.MarshalJSON(queries)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -148,7 +147,8 @@ func newGetPanelQueriesTool() mcp.Tool {
 			"Returns the panel ID, title, datasource information, and query expressions for each panel target. "+
 			"Useful for understanding what a dashboard is monitoring and for running those queries directly. "+
 			"Note: If datasourceUid is a template variable (e.g., '$datasource'), "+
-			"you'll need to resolve it using the grafana://datasources resource."),
+			"you'll need to resolve it using the grafana://datasources resource, "+
+			"or grafana://datasources/{type} (e.g., grafana://datasources/prometheus) to list only the candidates of the panel's datasource type."),
 		mcp.WithString("uid",
 			mcp.Description("The UID of the dashboard"),
 			mcp.Required(),
@@ -157,6 +157,6 @@ func newGetPanelQueriesTool() mcp.Tool {
 }
 
 // RegisterGetPanelQueries registers the get_dashboard_panel_queries tool.
-func RegisterGetPanelQueries(s *server.MCPServer) {
+func RegisterGetPanelQueries(s grafana.ToolRegistry) {
 	s.AddTool(newGetPanelQueriesTool(), getPanelQueriesHandler)
 }
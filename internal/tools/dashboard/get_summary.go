@@ -2,15 +2,15 @@ package dashboard
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type getSummaryParams struct {
-	UID string `json:"uid"`
+	UID    string   `json:"uid"`
+	Fields []string `json:"fields,omitempty"`
 }
 
 func getSummaryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -35,7 +35,12 @@ func getSummaryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 
 	summary := buildSummary(params.UID, dashResponse)
 
-	jsonData, err := json.MarshalIndent(summary, "", "  ")
+	projected, err := grafana.ProjectFields(summary, params.Fields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(projected)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -141,10 +146,15 @@ func newGetSummaryTool() mcp.Tool {
 			mcp.Description("The UID of the dashboard"),
 			mcp.Required(),
 		),
+		mcp.WithArray("fields",
+			mcp.Description("Optional list of fields to return, as top-level keys or dotted paths that descend through arrays "+
+				"(e.g., [\"title\", \"panels.title\", \"variables.name\"])"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
 	)
 }
 
 // RegisterGetSummary registers the get_dashboard_summary tool.
-func RegisterGetSummary(s *server.MCPServer) {
+func RegisterGetSummary(s grafana.ToolRegistry) {
 	s.AddTool(newGetSummaryTool(), getSummaryHandler)
 }
@@ -0,0 +1,381 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultPanelStepSeconds is the default step for panel range queries.
+	DefaultPanelStepSeconds = 60
+
+	// PanelLogLimit is the number of log lines fetched for each Loki log target.
+	PanelLogLimit = 100
+)
+
+// promMaxRange and lokiMaxRange cap panel queries with the same limits the Prometheus and Loki tools
+// apply, so a dashboard time range cannot bypass PROM_MAX_RANGE or LOKI_MAX_RANGE.
+var (
+	promMaxRange = grafana.NewRangeLimit("PROM_MAX_RANGE")
+	lokiMaxRange = grafana.NewRangeLimit("LOKI_MAX_RANGE")
+)
+
+// variableReference matches ${name}, ${name:format}, [[name]], and $name template references.
+var variableReference = regexp.MustCompile(`\$\{(\w+)(?::\w+)?\}|\[\[(\w+)\]\]|\$(\w+)`)
+
+type getPanelDataParams struct {
+	UID          string            `json:"uid"`
+	PanelID      int               `json:"panelId"`
+	StartRFC3339 string            `json:"startRfc3339,omitempty"`
+	EndRFC3339   string            `json:"endRfc3339,omitempty"`
+	StepSeconds  int               `json:"stepSeconds,omitempty"`
+	Variables    map[string]string `json:"variables,omitempty"`
+}
+
+// PanelData is the output of get_dashboard_panel_data.
+type PanelData struct {
+	PanelID    int            `json:"panelId"`
+	PanelTitle string         `json:"panelTitle"`
+	Targets    []TargetResult `json:"targets"`
+}
+
+// TargetResult is the outcome of running one panel target; exactly one of Result and Error is set.
+type TargetResult struct {
+	RefID          string `json:"refId,omitempty"`
+	DatasourceUID  string `json:"datasourceUid,omitempty"`
+	DatasourceType string `json:"datasourceType,omitempty"`
+	Query          string `json:"query,omitempty"` // After template variable interpolation
+	Result         any    `json:"result,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// datasourceInfo is the subset of Grafana's datasource model needed to route a query.
+type datasourceInfo struct {
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+}
+
+// getDatasourceType looks up the plugin type of a datasource by UID.
+func (c *client) getDatasourceType(ctx context.Context, uid string) (string, error) {
+	bodyBytes, err := c.makeRequest(ctx, "GET", fmt.Sprintf("/api/datasources/uid/%s", url.PathEscape(uid)), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var ds datasourceInfo
+	if err := json.Unmarshal(bodyBytes, &ds); err != nil {
+		return "", fmt.Errorf("unmarshalling datasource: %w", err)
+	}
+
+	return ds.Type, nil
+}
+
+// proxyResponse represents the standard Prometheus/Loki API response wrapper.
+type proxyResponse struct {
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// runTarget executes an interpolated panel query through the datasource proxy, with the start
+// clamped to the backend's maximum range. The notice is non-empty when the start was clamped.
+func (c *client) runTarget(ctx context.Context, dsUID, dsType, query string, start, end time.Time, stepSeconds int) (any, string, error) {
+	if err := grafana.CheckDatasource(dsUID); err != nil {
+		return nil, "", err
+	}
+
+	params := url.Values{}
+	params.Add("query", query)
+	params.Add("step", fmt.Sprintf("%d", stepSeconds))
+
+	var (
+		path   string
+		notice string
+		err    error
+	)
+	switch dsType {
+	case "prometheus":
+		if start, notice, err = promMaxRange.Clamp(start, end); err != nil {
+			return nil, "", err
+		}
+		path = "/api/v1/query_range"
+		params.Add("start", fmt.Sprintf("%d", start.Unix()))
+		params.Add("end", fmt.Sprintf("%d", end.Unix()))
+	case "loki":
+		if start, notice, err = lokiMaxRange.Clamp(start, end); err != nil {
+			return nil, "", err
+		}
+		path = "/loki/api/v1/query_range"
+		params.Add("start", fmt.Sprintf("%d", start.UnixNano()))
+		params.Add("end", fmt.Sprintf("%d", end.UnixNano()))
+		params.Add("limit", fmt.Sprintf("%d", PanelLogLimit))
+	default:
+		return nil, "", fmt.Errorf("unsupported datasource type %q (only prometheus and loki panels can be run)", dsType)
+	}
+
+	proxyPath := fmt.Sprintf("/api/datasources/proxy/uid/%s%s", url.PathEscape(dsUID), path)
+	bodyBytes, err := c.makeRequest(ctx, "GET", proxyPath, params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var resp proxyResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, "", fmt.Errorf("unmarshalling query response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, "", fmt.Errorf("%s API error: %s", dsType, resp.Error)
+	}
+
+	var data any
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, "", fmt.Errorf("unmarshalling query data: %w", err)
+	}
+
+	return data, notice, nil
+}
+
+// templateVariables returns the current value of each dashboard template variable.
+// Multi-value selections are joined with "|" as Grafana does for Prometheus and Loki regex matchers,
+// and "All" resolves to the variable's custom all value or ".*".
+func templateVariables(dashMap map[string]any) map[string]string {
+	vars := make(map[string]string)
+
+	templating, ok := dashMap["templating"].(map[string]any)
+	if !ok {
+		return vars
+	}
+	list, ok := templating["list"].([]any)
+	if !ok {
+		return vars
+	}
+
+	for _, v := range list {
+		varMap, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, ok := varMap["name"].(string)
+		if !ok || name == "" {
+			continue
+		}
+		current, ok := varMap["current"].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		var values []string
+		switch value := current["value"].(type) {
+		case string:
+			values = []string{value}
+		case []any:
+			for _, item := range value {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+
+		for i, value := range values {
+			if value == "$__all" {
+				allValue, _ := varMap["allValue"].(string)
+				if allValue == "" {
+					allValue = ".*"
+				}
+				values[i] = allValue
+			}
+		}
+
+		vars[name] = strings.Join(values, "|")
+	}
+
+	return vars
+}
+
+// interpolate replaces template variable references with their values.
+// Unknown references are left untouched.
+func interpolate(s string, vars map[string]string) string {
+	return variableReference.ReplaceAllStringFunc(s, func(ref string) string {
+		m := variableReference.FindStringSubmatch(ref)
+		name := m[1] + m[2] + m[3]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+func getPanelDataHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params getPanelDataParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.UID == "" {
+		return mcp.NewToolResultError("uid is required"), nil
+	}
+	if params.PanelID <= 0 {
+		return mcp.NewToolResultError("panelId is required"), nil
+	}
+
+	end := time.Now().UTC()
+	if params.EndRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, params.EndRFC3339)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("parsing end time: %v", err)), nil
+		}
+		end = t
+	}
+	start := end.Add(-time.Hour)
+	if params.StartRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, params.StartRFC3339)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("parsing start time: %v", err)), nil
+		}
+		start = t
+	}
+
+	stepSeconds := params.StepSeconds
+	if stepSeconds <= 0 {
+		stepSeconds = DefaultPanelStepSeconds
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating dashboard client: %v", err)), nil
+	}
+
+	dashResponse, err := c.getDashboardByUID(ctx, params.UID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var targets []PanelQuery
+	for _, q := range extractPanelQueries(dashResponse) {
+		if q.PanelID == params.PanelID {
+			targets = append(targets, q)
+		}
+	}
+	if len(targets) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("panel %d not found in dashboard %s, or it has no queries", params.PanelID, params.UID)), nil
+	}
+
+	// Dashboard defaults, then caller overrides, then Grafana's built-in interval variables
+	vars := map[string]string{}
+	if dashMap, ok := dashResponse.Dashboard.(map[string]any); ok {
+		vars = templateVariables(dashMap)
+	}
+	for name, value := range params.Variables {
+		vars[name] = value
+	}
+	vars["__interval"] = fmt.Sprintf("%ds", stepSeconds)
+	vars["__interval_ms"] = fmt.Sprintf("%d", stepSeconds*1000)
+	vars["__rate_interval"] = fmt.Sprintf("%ds", 4*stepSeconds)
+	vars["__range"] = fmt.Sprintf("%ds", int(end.Sub(start).Seconds()))
+
+	result := PanelData{
+		PanelID:    params.PanelID,
+		PanelTitle: targets[0].PanelTitle,
+		Targets:    []TargetResult{},
+	}
+
+	// Cache datasource type lookups; panels usually share one datasource across targets
+	dsTypes := make(map[string]string)
+	var warnings []string
+
+	for _, target := range targets {
+		if hidden, ok := target.RawQuery["hide"].(bool); ok && hidden {
+			continue
+		}
+
+		tr := TargetResult{
+			RefID:          target.RefID,
+			DatasourceUID:  interpolate(target.DatasourceUID, vars),
+			DatasourceType: target.DatasourceType,
+			Query:          interpolate(target.QueryExpr, vars),
+		}
+
+		switch {
+		case tr.DatasourceUID == "" || strings.Contains(tr.DatasourceUID, "$"):
+			tr.Error = fmt.Sprintf("could not resolve datasource %q; pass it in variables", target.DatasourceUID)
+		case tr.Query == "":
+			tr.Error = "target has no query expression"
+		default:
+			// Panels referencing a datasource through a variable often carry a stale or missing type
+			if tr.DatasourceType == "" || tr.DatasourceUID != target.DatasourceUID {
+				if dsType, ok := dsTypes[tr.DatasourceUID]; ok {
+					tr.DatasourceType = dsType
+				} else if dsType, err := c.getDatasourceType(ctx, tr.DatasourceUID); err == nil {
+					dsTypes[tr.DatasourceUID] = dsType
+					tr.DatasourceType = dsType
+				} else {
+					tr.Error = fmt.Sprintf("looking up datasource: %v", err)
+				}
+			}
+			if tr.Error == "" {
+				data, notice, err := c.runTarget(ctx, tr.DatasourceUID, tr.DatasourceType, tr.Query, start, end, stepSeconds)
+				if err != nil {
+					tr.Error = err.Error()
+				} else {
+					tr.Result = data
+				}
+				if notice != "" {
+					warnings = append(warnings, fmt.Sprintf("target %s: %s", tr.RefID, notice))
+				}
+			}
+		}
+
+		result.Targets = append(result.Targets, tr)
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(result, warnings...))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newGetPanelDataTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_dashboard_panel_data",
+		mcp.WithDescription("Runs a dashboard panel's queries and returns the data, i.e., 'run this panel for me'. "+
+			"Resolves the panel's datasource and template variables (dashboard defaults, overridable via variables, plus $__interval, $__rate_interval, and $__range), "+
+			"then executes each visible Prometheus or Loki target as a range query. "+
+			"Returns per-target results with the interpolated query; targets that fail or use other datasource types report an error instead. "+
+			"Use get_dashboard_summary first to find panel IDs. Defaults to the last hour."),
+		mcp.WithString("uid",
+			mcp.Description("The UID of the dashboard"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("panelId",
+			mcp.Description("The ID of the panel to run"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to 1 hour before the end time)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("stepSeconds",
+			mcp.Description(fmt.Sprintf("Query step in seconds, also used for $__interval (default: %d)", DefaultPanelStepSeconds)),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Optional template variable values overriding the dashboard's current values, e.g., {\"namespace\": \"api\", \"datasource\": \"prom-uid\"}"),
+		),
+	)
+}
+
+// RegisterGetPanelData registers the get_dashboard_panel_data tool.
+func RegisterGetPanelData(s grafana.ToolRegistry) {
+	s.AddTool(newGetPanelDataTool(), getPanelDataHandler)
+}
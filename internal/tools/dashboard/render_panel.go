@@ -0,0 +1,170 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultRenderWidth is the default width of a rendered panel image in pixels.
+	DefaultRenderWidth = 1000
+
+	// DefaultRenderHeight is the default height of a rendered panel image in pixels.
+	DefaultRenderHeight = 500
+
+	// MaxRenderDimension caps the width and height of a rendered panel image in pixels.
+	MaxRenderDimension = 3000
+)
+
+type renderPanelParams struct {
+	UID          string            `json:"uid"`
+	PanelID      int               `json:"panelId"`
+	StartRFC3339 string            `json:"startRfc3339,omitempty"`
+	EndRFC3339   string            `json:"endRfc3339,omitempty"`
+	Width        int               `json:"width,omitempty"`
+	Height       int               `json:"height,omitempty"`
+	Variables    map[string]string `json:"variables,omitempty"`
+}
+
+// renderPanel renders a single dashboard panel to PNG via Grafana's render API.
+// Requires the Grafana image renderer plugin or remote rendering service.
+func (c *client) renderPanel(ctx context.Context, uid string, params url.Values) ([]byte, error) {
+	// The slug segment is required by the route but not used to look up the dashboard
+	reqURL := fmt.Sprintf("%s/render/d-solo/%s/_", c.baseURL, url.PathEscape(uid))
+
+	resp, err := grafana.Send(ctx, c.httpClient, grafana.Request{Method: "GET", URL: reqURL, Params: params})
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(contentType, "image/png") {
+		return resp.Body, nil
+	}
+
+	detail := string(resp.Body)
+	if !strings.HasPrefix(contentType, "image/") && strings.Contains(strings.ToLower(detail), "renderer") {
+		return nil, fmt.Errorf("panel rendering is unavailable: this Grafana instance has no image renderer installed " +
+			"(install the grafana-image-renderer plugin or configure a remote rendering service)")
+	}
+	if resp.StatusCode == http.StatusOK {
+		// Some Grafana versions answer with a placeholder page instead of an error when rendering is unavailable
+		return nil, fmt.Errorf("render API returned %s instead of a PNG; the image renderer is likely not installed", contentType)
+	}
+	if strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("render API returned status %d: %s", resp.StatusCode, contentType)
+	}
+
+	return nil, grafana.StatusError("render API", resp.StatusCode, resp.Body)
+}
+
+func renderPanelHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params renderPanelParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.UID == "" {
+		return mcp.NewToolResultError("uid is required"), nil
+	}
+	if params.PanelID <= 0 {
+		return mcp.NewToolResultError("panelId is required"), nil
+	}
+
+	width := params.Width
+	if width <= 0 {
+		width = DefaultRenderWidth
+	}
+	height := params.Height
+	if height <= 0 {
+		height = DefaultRenderHeight
+	}
+	if width > MaxRenderDimension || height > MaxRenderDimension {
+		return mcp.NewToolResultError(fmt.Sprintf("width and height must not exceed %d pixels", MaxRenderDimension)), nil
+	}
+
+	// The render API takes epoch milliseconds or relative times like "now-1h"
+	from, to := "now-1h", "now"
+	if params.StartRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, params.StartRFC3339)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("parsing start time: %v", err)), nil
+		}
+		from = fmt.Sprintf("%d", t.UnixMilli())
+	}
+	if params.EndRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, params.EndRFC3339)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("parsing end time: %v", err)), nil
+		}
+		to = fmt.Sprintf("%d", t.UnixMilli())
+	}
+
+	query := url.Values{}
+	query.Add("panelId", fmt.Sprintf("%d", params.PanelID))
+	query.Add("from", from)
+	query.Add("to", to)
+	query.Add("width", fmt.Sprintf("%d", width))
+	query.Add("height", fmt.Sprintf("%d", height))
+	for name, value := range params.Variables {
+		query.Add("var-"+name, value)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating dashboard client: %v", err)), nil
+	}
+
+	png, err := c.renderPanel(ctx, params.UID, query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	text := fmt.Sprintf("Panel %d of dashboard %s (%dx%d, from %s to %s)", params.PanelID, params.UID, width, height, from, to)
+	return mcp.NewToolResultImage(text, base64.StdEncoding.EncodeToString(png), "image/png"), nil
+}
+
+func newRenderPanelTool() mcp.Tool {
+	return mcp.NewTool(
+		"render_dashboard_panel",
+		mcp.WithDescription("Renders a single dashboard panel to a PNG image via Grafana's render API and returns it as image content. "+
+			"Requires the Grafana image renderer plugin (or a remote rendering service); returns a clear error if it is not installed. "+
+			"Use get_dashboard_summary first to find panel IDs. Defaults to the last hour at 1000x500 pixels."),
+		mcp.WithString("uid",
+			mcp.Description("The UID of the dashboard"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("panelId",
+			mcp.Description("The ID of the panel to render"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("width",
+			mcp.Description(fmt.Sprintf("Image width in pixels (default: %d, max: %d)", DefaultRenderWidth, MaxRenderDimension)),
+		),
+		mcp.WithNumber("height",
+			mcp.Description(fmt.Sprintf("Image height in pixels (default: %d, max: %d)", DefaultRenderHeight, MaxRenderDimension)),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Optional template variable values, e.g., {\"cluster\": \"prod\", \"namespace\": \"api\"}"),
+		),
+	)
+}
+
+// RegisterRenderPanel registers the render_dashboard_panel tool.
+func RegisterRenderPanel(s grafana.ToolRegistry) {
+	s.AddTool(newRenderPanelTool(), renderPanelHandler)
+}
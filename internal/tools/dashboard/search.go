@@ -2,11 +2,10 @@ package dashboard
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type searchParams struct {
@@ -40,7 +39,20 @@ func searchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallT
 		results = []SearchResult{}
 	}
 
-	jsonData, err := json.MarshalIndent(results, "", "  ")
+	// Grafana stops at the limit, so a full page means more dashboards likely match
+	var result any = results
+	var warnings []string
+	if len(results) >= limit {
+		totalBeforeLimit := 0
+		if len(results) > limit {
+			results, totalBeforeLimit = results[:limit], len(results)
+		}
+		result = grafana.TruncatedList[SearchResult]{Values: results, Truncated: true, TotalBeforeLimit: totalBeforeLimit}
+		warnings = append(warnings, fmt.Sprintf("Returned the maximum of %d dashboards, so more likely match. "+
+			"Narrow query or tag, or raise limit.", limit))
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(result, warnings...))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -52,7 +64,8 @@ func newSearchTool() mcp.Tool {
 	return mcp.NewTool(
 		"search_dashboards",
 		mcp.WithDescription("Searches for Grafana dashboards by query string or tag. "+
-			"Returns a list of matching dashboards with UID, title, tags, folder information, and URL. "+
+			"Returns a list of matching dashboards with UID, title, tags, folder information, and URL; "+
+			"a list that reached limit is marked _truncated. "+
 			"Use the UID from results to call get_dashboard_summary or get_dashboard_panel_queries for more details."),
 		mcp.WithString("query",
 			mcp.Description("Search query string to match against dashboard titles"),
@@ -67,6 +80,6 @@ func newSearchTool() mcp.Tool {
 }
 
 // RegisterSearch registers the search_dashboards tool.
-func RegisterSearch(s *server.MCPServer) {
+func RegisterSearch(s grafana.ToolRegistry) {
 	s.AddTool(newSearchTool(), searchHandler)
 }
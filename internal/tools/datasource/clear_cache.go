@@ -0,0 +1,39 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func clearCacheHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cleared := grafana.ClearCaches()
+
+	total := 0
+	for _, c := range cleared {
+		total += c.Entries
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(cleared, total))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newClearCacheTool() mcp.Tool {
+	return mcp.NewTool(
+		"clear_cache",
+		mcp.WithDescription("Empties this server's caches: auto-resolved default datasources, numeric datasource ID lookups, "+
+			"and Tempo tag name listings. Use it when datasources or tags just changed (e.g., during an incident) and "+
+			"cached listings could be stale; the next calls fetch fresh data. Returns how many entries each cache held."),
+	)
+}
+
+// RegisterClearCache registers the clear_cache tool.
+func RegisterClearCache(s grafana.ToolRegistry) {
+	s.AddTool(newClearCacheTool(), clearCacheHandler)
+}
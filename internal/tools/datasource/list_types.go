@@ -0,0 +1,123 @@
+// Package datasource provides MCP tools for discovering the datasources configured in Grafana
+// and checking the server's connection to it.
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// typeTools maps datasource plugin types to the tools in this server that query them.
+var typeTools = map[string][]string{
+	"prometheus":                    {"query_prometheus", "list_prometheus_metric_names"},
+	"loki":                          {"query_loki_logs", "query_loki_metric", "list_loki_label_names"},
+	"tempo":                         {"search_tempo_traces", "get_tempo_trace"},
+	"elasticsearch":                 {"query_elasticsearch_logs"},
+	"grafana-opensearch-datasource": {"query_elasticsearch_logs"},
+	"influxdb":                      {"query_influxdb"},
+	"grafana-postgresql-datasource": {"query_sql"},
+	"postgres":                      {"query_sql"},
+	"mysql":                         {"query_sql"},
+}
+
+// DatasourceType summarizes the datasources of one plugin type.
+type DatasourceType struct {
+	Type  string   `json:"type"`
+	Count int      `json:"count"`
+	UIDs  []string `json:"uids"`
+	Tools []string `json:"tools,omitempty"` // Tools that query this type, when any do
+}
+
+// datasourceRef is the subset of a Grafana datasource the summary needs.
+type datasourceRef struct {
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+}
+
+// listDatasources fetches the datasources the tools are permitted to query.
+func listDatasources(ctx context.Context) ([]datasourceRef, error) {
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	statusCode, bodyBytes, err := grafana.Do(ctx, httpClient, grafana.Request{Method: "GET", URL: grafanaURL + "/api/datasources"})
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
+	}
+
+	var raw []datasourceRef
+	if err := json.Unmarshal(bodyBytes, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshalling datasources: %w", err)
+	}
+
+	// Hide datasources the tools are not permitted to query
+	datasources := make([]datasourceRef, 0, len(raw))
+	for _, ds := range raw {
+		if grafana.DatasourcePermitted(ds.UID) {
+			datasources = append(datasources, ds)
+		}
+	}
+	return datasources, nil
+}
+
+func listTypesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	datasources, err := listDatasources(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	byType := map[string]*DatasourceType{}
+	for _, ds := range datasources {
+		t, ok := byType[ds.Type]
+		if !ok {
+			t = &DatasourceType{Type: ds.Type, UIDs: []string{}, Tools: typeTools[ds.Type]}
+			byType[ds.Type] = t
+		}
+		t.Count++
+		t.UIDs = append(t.UIDs, ds.UID)
+	}
+
+	types := make([]DatasourceType, 0, len(byType))
+	for _, t := range byType {
+		types = append(types, *t)
+	}
+	// Most common types first, then alphabetically
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Count != types[j].Count {
+			return types[i].Count > types[j].Count
+		}
+		return types[i].Type < types[j].Type
+	})
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(types, len(types)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newListTypesTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_datasource_types",
+		mcp.WithDescription("Groups the Grafana datasources by plugin type (e.g., prometheus, loki, tempo) and returns the count and UIDs of each, "+
+			"most common first, along with the tools in this server that query that type. "+
+			"Use this for orientation, e.g., to check whether a Tempo datasource exists before using tracing tools."),
+	)
+}
+
+// RegisterListTypes registers the list_datasource_types tool.
+func RegisterListTypes(s grafana.ToolRegistry) {
+	s.AddTool(newListTypesTool(), listTypesHandler)
+}
@@ -0,0 +1,136 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SelfTestCheck is the outcome of one step of the self-test.
+type SelfTestCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// SelfTest reports whether the server's Grafana configuration works end to end.
+type SelfTest struct {
+	OK              bool            `json:"ok"`
+	GrafanaURL      string          `json:"grafanaUrl,omitempty"`
+	GrafanaVersion  string          `json:"grafanaVersion,omitempty"`
+	User            string          `json:"user,omitempty"`
+	DatasourceTypes map[string]int  `json:"datasourceTypes,omitempty"` // Permitted datasources per plugin type
+	Checks          []SelfTestCheck `json:"checks"`
+}
+
+// add records a check and clears the overall OK flag if it failed.
+func (s *SelfTest) add(name string, ok bool, detail string) {
+	s.Checks = append(s.Checks, SelfTestCheck{Name: name, OK: ok, Detail: detail})
+	if !ok {
+		s.OK = false
+	}
+}
+
+func selfTestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := &SelfTest{OK: true, Checks: []SelfTestCheck{}}
+	runSelfTest(ctx, result)
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// runSelfTest checks the configuration, Grafana's reachability and version, the API key, and the
+// datasources, stopping at the first step later ones depend on.
+func runSelfTest(ctx context.Context, result *SelfTest) {
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		result.add("config", false, err.Error())
+		return
+	}
+	result.GrafanaURL = grafanaURL
+	result.add("config", true, "GRAFANA_URL and GRAFANA_API_KEY are set")
+
+	// /api/health needs no authentication, so it separates an unreachable Grafana from a bad key
+	statusCode, bodyBytes, err := grafana.Do(ctx, httpClient, grafana.Request{Method: "GET", URL: grafanaURL + "/api/health"})
+	if err != nil {
+		result.add("health", false, fmt.Sprintf("Grafana is unreachable at GRAFANA_URL: %v", err))
+		return
+	}
+	if statusCode != http.StatusOK {
+		result.add("health", false, fmt.Sprintf("GRAFANA_URL does not look like Grafana: %v", grafana.StatusError("API", statusCode, bodyBytes)))
+		return
+	}
+	var health struct {
+		Version  string `json:"version"`
+		Database string `json:"database"`
+	}
+	if err := json.Unmarshal(bodyBytes, &health); err != nil {
+		result.add("health", false, fmt.Sprintf("GRAFANA_URL does not look like Grafana: unmarshalling /api/health: %v", err))
+		return
+	}
+	result.GrafanaVersion = health.Version
+	result.add("health", health.Database == "ok", fmt.Sprintf("Grafana %s is reachable, database %s", health.Version, health.Database))
+
+	statusCode, bodyBytes, err = grafana.Do(ctx, httpClient, grafana.Request{Method: "GET", URL: grafanaURL + "/api/user"})
+	switch {
+	case err != nil:
+		result.add("auth", false, err.Error())
+		return
+	case statusCode == http.StatusUnauthorized:
+		result.add("auth", false, "GRAFANA_API_KEY was rejected (401): the token is invalid, expired, or belongs to another Grafana instance")
+		return
+	case statusCode != http.StatusOK:
+		result.add("auth", false, grafana.StatusError("API", statusCode, bodyBytes).Error())
+		return
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	_ = json.Unmarshal(bodyBytes, &user)
+	result.User = user.Login
+	result.add("auth", true, fmt.Sprintf("authenticated as %s", user.Login))
+
+	datasources, err := listDatasources(ctx)
+	if err != nil {
+		result.add("datasources", false, fmt.Sprintf("listing datasources: %v", err))
+		return
+	}
+	result.DatasourceTypes = make(map[string]int)
+	var queryable []string
+	for _, ds := range datasources {
+		if result.DatasourceTypes[ds.Type] == 0 && typeTools[ds.Type] != nil {
+			queryable = append(queryable, ds.Type)
+		}
+		result.DatasourceTypes[ds.Type]++
+	}
+	sort.Strings(queryable)
+	if len(queryable) == 0 {
+		result.add("datasources", false, fmt.Sprintf("found %d datasources, but none of a type these tools query "+
+			"(prometheus, loki, tempo, elasticsearch, opensearch, influxdb, postgres, mysql)", len(datasources)))
+		return
+	}
+	result.add("datasources", true, fmt.Sprintf("found %d datasources; queryable types: %v", len(datasources), queryable))
+}
+
+func newSelfTestTool() mcp.Tool {
+	return mcp.NewTool(
+		"selftest",
+		mcp.WithDescription("Checks that this server is configured correctly: that GRAFANA_URL and GRAFANA_API_KEY are set, "+
+			"Grafana is reachable (and which version it runs), the API key is accepted, and which datasource types are present. "+
+			"Each check reports ok and an actionable detail; run this first when other tools fail with connection or 401 errors."),
+	)
+}
+
+// RegisterSelfTest registers the selftest tool.
+func RegisterSelfTest(s grafana.ToolRegistry) {
+	s.AddTool(newSelfTestTool(), selfTestHandler)
+}
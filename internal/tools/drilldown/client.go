@@ -0,0 +1,265 @@
+// Package drilldown provides MCP tools that chain queries across Prometheus, Tempo, and Loki
+// datasources to follow a signal from one backend into the others.
+package drilldown
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/krmcbride/mcp-grafana/internal/tools/tempo"
+)
+
+const (
+	// DefaultLogLimit is the default number of correlated log lines to return.
+	DefaultLogLimit = 10
+
+	// MaxLogLimit is the maximum number of correlated log lines that can be requested.
+	MaxLogLimit = 100
+
+	// DefaultServiceLabel is the Loki label used to select a service's log streams.
+	DefaultServiceLabel = "service_name"
+
+	// logWindowPadding widens the trace's time span when searching for correlated logs.
+	logWindowPadding = time.Minute
+)
+
+// client provides methods for querying several datasources through Grafana's datasource proxy.
+type client struct {
+	httpClient *http.Client
+	grafanaURL string
+}
+
+// newClient creates a new drilldown client.
+func newClient() (*client, error) {
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		httpClient: httpClient,
+		grafanaURL: grafanaURL,
+	}, nil
+}
+
+// makeRequest performs an HTTP request against a datasource proxy path and returns the response body.
+// datasourceUID is resolved like the dedicated tools do, so numeric IDs and defaults for dsType work too.
+func (c *client) makeRequest(ctx context.Context, method, dsType, datasourceUID, path string, params url.Values) ([]byte, error) {
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, dsType)
+	if err != nil {
+		return nil, err
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return nil, err
+	}
+
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method: method,
+		URL:    fmt.Sprintf("%s/api/datasources/proxy/uid/%s%s", c.grafanaURL, datasourceUID, path),
+		Params: params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
+	}
+
+	return bodyBytes, nil
+}
+
+// Exemplar represents a single Prometheus exemplar together with the series it was attached to.
+type Exemplar struct {
+	SeriesLabels map[string]string `json:"seriesLabels"`
+	Labels       map[string]string `json:"labels"`
+	Value        string            `json:"value"`
+	Timestamp    float64           `json:"timestamp"`
+}
+
+// exemplarsResponse represents the response from Prometheus' query_exemplars API.
+type exemplarsResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   []struct {
+		SeriesLabels map[string]string `json:"seriesLabels"`
+		Exemplars    []struct {
+			Labels    map[string]string `json:"labels"`
+			Value     string            `json:"value"`
+			Timestamp float64           `json:"timestamp"`
+		} `json:"exemplars"`
+	} `json:"data"`
+}
+
+// traceIDLabels lists the exemplar label names commonly used to carry a trace ID.
+var traceIDLabels = []string{"trace_id", "traceID", "traceId", "TraceID"}
+
+// fetchLatestExemplar returns the most recent exemplar carrying a trace ID for the given selector.
+func (c *client) fetchLatestExemplar(ctx context.Context, datasourceUID, selector string, start, end time.Time) (*Exemplar, string, error) {
+	params := url.Values{}
+	params.Add("query", selector)
+	params.Add("start", strconv.FormatInt(start.Unix(), 10))
+	params.Add("end", strconv.FormatInt(end.Unix(), 10))
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", "prometheus", datasourceUID, "/api/v1/query_exemplars", params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var resp exemplarsResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, "", fmt.Errorf("unmarshalling exemplars response: %w", err)
+	}
+
+	if resp.Status != "success" {
+		return nil, "", fmt.Errorf("prometheus API error: %s", resp.Error)
+	}
+
+	var latest *Exemplar
+	var latestTraceID string
+	for _, series := range resp.Data {
+		for _, ex := range series.Exemplars {
+			traceID := ""
+			for _, key := range traceIDLabels {
+				if v := ex.Labels[key]; v != "" {
+					traceID = v
+					break
+				}
+			}
+			if traceID == "" {
+				continue
+			}
+
+			if latest == nil || ex.Timestamp > latest.Timestamp {
+				latest = &Exemplar{
+					SeriesLabels: series.SeriesLabels,
+					Labels:       ex.Labels,
+					Value:        ex.Value,
+					Timestamp:    ex.Timestamp,
+				}
+				latestTraceID = traceID
+			}
+		}
+	}
+
+	if latest == nil {
+		return nil, "", fmt.Errorf("no exemplars with a trace ID found for selector %s", selector)
+	}
+
+	return latest, latestTraceID, nil
+}
+
+// TraceSummary is a compact description of a trace used to drive log correlation.
+type TraceSummary struct {
+	RootServiceName string    `json:"rootServiceName"`
+	RootSpanName    string    `json:"rootSpanName"`
+	Services        []string  `json:"services"`
+	SpanCount       int       `json:"spanCount"`
+	StartTime       time.Time `json:"startTime"`
+	EndTime         time.Time `json:"endTime"`
+	DurationMs      float64   `json:"durationMs"`
+}
+
+// fetchTraceSummary retrieves a trace from Tempo and summarizes its services and time bounds.
+func (c *client) fetchTraceSummary(ctx context.Context, datasourceUID, traceID string) (*TraceSummary, error) {
+	path := fmt.Sprintf("/api/traces/%s", url.PathEscape(traceID))
+	bodyBytes, err := c.makeRequest(ctx, "GET", "tempo", datasourceUID, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := tempo.DecodeTrace(traceID, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Roots are ordered by start time, so the first is the span that began the trace
+	root := tree.Spans[0]
+	if len(tree.Roots) > 0 {
+		root = tree.Roots[0]
+	}
+	return &TraceSummary{
+		RootServiceName: root.ServiceName,
+		RootSpanName:    root.Name,
+		Services:        tree.Services(),
+		SpanCount:       len(tree.Spans),
+		StartTime:       time.Unix(0, tree.StartNano).UTC(),
+		EndTime:         time.Unix(0, tree.EndNano).UTC(),
+		DurationMs:      tree.DurationMs(),
+	}, nil
+}
+
+// LogEntry represents a single log line correlated with a trace.
+type LogEntry struct {
+	Timestamp string            `json:"timestamp"`
+	Line      string            `json:"line"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// lokiQueryRangeResponse represents the response from Loki's query_range API for log queries.
+type lokiQueryRangeResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Result []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][]string        `json:"values"` // [timestamp, line]
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// fetchLogs runs a LogQL log query against Loki and flattens the resulting streams.
+func (c *client) fetchLogs(ctx context.Context, datasourceUID, query string, start, end time.Time, limit int) ([]LogEntry, error) {
+	params := url.Values{}
+	params.Add("query", query)
+	params.Add("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Add("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("direction", "forward")
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", "loki", datasourceUID, "/loki/api/v1/query_range", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp lokiQueryRangeResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshalling query response: %w", err)
+	}
+
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("loki API returned unexpected status: %s", resp.Status)
+	}
+
+	entries := []LogEntry{}
+	for _, stream := range resp.Data.Result {
+		for _, value := range stream.Values {
+			if len(value) < 2 {
+				continue
+			}
+			entries = append(entries, LogEntry{
+				Timestamp: value[0],
+				Line:      value[1],
+				Labels:    stream.Stream,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// enforceLogLimit ensures the log limit is within acceptable bounds.
+func enforceLogLimit(requestedLimit int) int {
+	if requestedLimit <= 0 {
+		return DefaultLogLimit
+	}
+	if requestedLimit > MaxLogLimit {
+		return MaxLogLimit
+	}
+	return requestedLimit
+}
@@ -0,0 +1,174 @@
+package drilldown
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultSampleValues is the number of label values discover_labels lists per backend.
+const DefaultSampleValues = 10
+
+type discoverLabelsParams struct {
+	Label                   string `json:"label"`
+	PrometheusDatasourceUID string `json:"prometheusDatasourceUid,omitempty"`
+	LokiDatasourceUID       string `json:"lokiDatasourceUid,omitempty"`
+	StartRFC3339            string `json:"startRfc3339,omitempty"`
+	EndRFC3339              string `json:"endRfc3339,omitempty"`
+}
+
+// LabelPresence reports whether one backend has a label and how many values it takes there.
+type LabelPresence struct {
+	DatasourceUID string   `json:"datasourceUid,omitempty"`
+	Exists        bool     `json:"exists"`
+	ValueCount    int      `json:"valueCount"`
+	SampleValues  []string `json:"sampleValues,omitempty"` // The first DefaultSampleValues values, sorted
+	Error         string   `json:"error,omitempty"`
+}
+
+// LabelDiscovery is the result of looking a label up in Prometheus and Loki side by side.
+type LabelDiscovery struct {
+	Label      string         `json:"label"`
+	Start      string         `json:"start"`
+	End        string         `json:"end"`
+	Prometheus *LabelPresence `json:"prometheus"`
+	Loki       *LabelPresence `json:"loki"`
+}
+
+// labelValuesResponse represents the label values response shared by Prometheus and Loki.
+type labelValuesResponse struct {
+	Status string   `json:"status"`
+	Data   []string `json:"data"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// discoverLabel resolves a datasource of dsType and fetches the values of label from path,
+// capturing any failure in the returned LabelPresence rather than returning it.
+func (c *client) discoverLabel(ctx context.Context, datasourceUID, dsType, path string, params url.Values) *LabelPresence {
+	presence := &LabelPresence{}
+
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, dsType)
+	if err != nil {
+		presence.Error = err.Error()
+		return presence
+	}
+	presence.DatasourceUID = datasourceUID
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", dsType, datasourceUID, path, params)
+	if err != nil {
+		presence.Error = err.Error()
+		return presence
+	}
+
+	var resp labelValuesResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		presence.Error = fmt.Sprintf("unmarshalling label values: %v", err)
+		return presence
+	}
+	if resp.Status != "success" {
+		presence.Error = fmt.Sprintf("%s API error: %s", dsType, resp.Error)
+		return presence
+	}
+
+	sort.Strings(resp.Data)
+	presence.Exists = len(resp.Data) > 0
+	presence.ValueCount = len(resp.Data)
+	presence.SampleValues = resp.Data[:min(len(resp.Data), DefaultSampleValues)]
+	return presence
+}
+
+func discoverLabelsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params discoverLabelsParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Label == "" {
+		return mcp.NewToolResultError("label is required"), nil
+	}
+
+	start, end, err := parseTimeRange(params.StartRFC3339, params.EndRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !end.After(start) {
+		return mcp.NewToolResultError("end time must be after start time"), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating drilldown client: %v", err)), nil
+	}
+
+	result := &LabelDiscovery{
+		Label: params.Label,
+		Start: start.Format(time.RFC3339),
+		End:   end.Format(time.RFC3339),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		promParams := url.Values{}
+		promParams.Add("start", strconv.FormatInt(start.Unix(), 10))
+		promParams.Add("end", strconv.FormatInt(end.Unix(), 10))
+		path := fmt.Sprintf("/api/v1/label/%s/values", url.PathEscape(params.Label))
+		result.Prometheus = c.discoverLabel(ctx, params.PrometheusDatasourceUID, "prometheus", path, promParams)
+	}()
+	go func() {
+		defer wg.Done()
+		lokiParams := url.Values{}
+		lokiParams.Add("start", strconv.FormatInt(start.UnixNano(), 10))
+		lokiParams.Add("end", strconv.FormatInt(end.UnixNano(), 10))
+		path := fmt.Sprintf("/loki/api/v1/label/%s/values", url.PathEscape(params.Label))
+		result.Loki = c.discoverLabel(ctx, params.LokiDatasourceUID, "loki", path, lokiParams)
+	}()
+	wg.Wait()
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newDiscoverLabelsTool() mcp.Tool {
+	return mcp.NewTool(
+		"discover_labels",
+		mcp.WithDescription("Looks a label name (e.g., namespace, pod) up in a Prometheus and a Loki datasource at once and reports, for each, "+
+			"whether the label exists, how many values it has, and the first few values. "+
+			"Use it to find out which backend owns a dimension before writing a query. "+
+			"Both lookups run concurrently; if one fails, its section carries the error and the other is still returned. Defaults to the last hour."),
+		mcp.WithString("label",
+			mcp.Description("Label name to look up"),
+			mcp.Required(),
+		),
+		mcp.WithString("prometheusDatasourceUid",
+			mcp.Description("The UID of the Prometheus datasource; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
+		),
+		mcp.WithString("lokiDatasourceUid",
+			mcp.Description("The UID of the Loki datasource; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to 1 hour before the end time)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+	)
+}
+
+// RegisterDiscoverLabels registers the discover_labels tool.
+func RegisterDiscoverLabels(s grafana.ToolRegistry) {
+	s.AddTool(newDiscoverLabelsTool(), discoverLabelsHandler)
+}
@@ -0,0 +1,176 @@
+package drilldown
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// traceIDPattern matches hex-encoded trace IDs (64-bit or 128-bit).
+var traceIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{16,32}$`)
+
+type exemplarParams struct {
+	TempoDatasourceUID      string `json:"tempoDatasourceUid"`
+	LokiDatasourceUID       string `json:"lokiDatasourceUid,omitempty"`
+	PrometheusDatasourceUID string `json:"prometheusDatasourceUid,omitempty"`
+	TraceID                 string `json:"traceId,omitempty"`
+	MetricSelector          string `json:"metricSelector,omitempty"`
+	StartRFC3339            string `json:"startRfc3339,omitempty"`
+	EndRFC3339              string `json:"endRfc3339,omitempty"`
+	ServiceLabel            string `json:"serviceLabel,omitempty"`
+	LogLimit                int    `json:"logLimit,omitempty"`
+}
+
+// ExemplarDrilldown is the combined result of following an exemplar to its trace and logs.
+type ExemplarDrilldown struct {
+	TraceID   string        `json:"traceId"`
+	Exemplar  *Exemplar     `json:"exemplar,omitempty"`
+	Trace     *TraceSummary `json:"trace"`
+	LogQL     string        `json:"logql,omitempty"`
+	Logs      []LogEntry    `json:"logs,omitempty"`
+	LogsError string        `json:"logsError,omitempty"`
+}
+
+func exemplarHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params exemplarParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.TempoDatasourceUID == "" {
+		return mcp.NewToolResultError("tempoDatasourceUid is required"), nil
+	}
+	if params.TraceID == "" && (params.MetricSelector == "" || params.PrometheusDatasourceUID == "") {
+		return mcp.NewToolResultError("either traceId, or metricSelector with prometheusDatasourceUid, is required"), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating drilldown client: %v", err)), nil
+	}
+
+	result := &ExemplarDrilldown{TraceID: params.TraceID}
+
+	// Resolve the trace ID from the most recent exemplar when only a selector was given
+	if result.TraceID == "" {
+		start, end, err := parseTimeRange(params.StartRFC3339, params.EndRFC3339)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		exemplar, traceID, err := c.fetchLatestExemplar(ctx, params.PrometheusDatasourceUID, params.MetricSelector, start, end)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("fetching exemplars: %v", err)), nil
+		}
+		result.Exemplar = exemplar
+		result.TraceID = traceID
+	}
+
+	if !traceIDPattern.MatchString(result.TraceID) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid trace ID %q: expected a 16 or 32 character hex string", result.TraceID)), nil
+	}
+
+	trace, err := c.fetchTraceSummary(ctx, params.TempoDatasourceUID, result.TraceID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("fetching trace: %v", err)), nil
+	}
+	result.Trace = trace
+
+	// Log correlation is best-effort; the trace summary is still useful on its own
+	if params.LokiDatasourceUID != "" && trace.RootServiceName != "" {
+		serviceLabel := params.ServiceLabel
+		if serviceLabel == "" {
+			serviceLabel = DefaultServiceLabel
+		}
+
+		result.LogQL = fmt.Sprintf("{%s=%s} |= %s", serviceLabel, grafana.QuoteLabelValue(trace.RootServiceName), grafana.QuoteLabelValue(result.TraceID))
+
+		logs, err := c.fetchLogs(ctx, params.LokiDatasourceUID, result.LogQL,
+			trace.StartTime.Add(-logWindowPadding), trace.EndTime.Add(logWindowPadding),
+			enforceLogLimit(params.LogLimit))
+		if err != nil {
+			result.LogsError = err.Error()
+		} else {
+			result.Logs = logs
+		}
+	}
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// parseTimeRange parses optional RFC3339 bounds, defaulting to the last hour.
+func parseTimeRange(startRFC3339, endRFC3339 string) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if endRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, endRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing end time: %w", err)
+		}
+		end = t
+	}
+
+	start := end.Add(-1 * time.Hour)
+	if startRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, startRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing start time: %w", err)
+		}
+		start = t
+	}
+
+	return start, end, nil
+}
+
+func newExemplarTool() mcp.Tool {
+	return mcp.NewTool(
+		"drilldown_exemplar",
+		mcp.WithDescription("Follows a metric exemplar to its trace and correlated logs in a single call. "+
+			"Provide either a traceId (e.g., copied from an exemplar) or a metricSelector plus prometheusDatasourceUid, "+
+			"in which case the most recent exemplar carrying a trace ID is used. "+
+			"Returns the exemplar, a trace summary (root service, root span, services involved, span count, duration), "+
+			"and, when lokiDatasourceUid is set, log lines from the root service that mention the trace ID. "+
+			"Use get_tempo_trace for the full span detail."),
+		mcp.WithString("tempoDatasourceUid",
+			mcp.Description("The UID of the Tempo datasource holding the trace"),
+			mcp.Required(),
+		),
+		mcp.WithString("lokiDatasourceUid",
+			mcp.Description("The UID of the Loki datasource to search for correlated logs (logs are skipped if omitted)"),
+		),
+		mcp.WithString("prometheusDatasourceUid",
+			mcp.Description("The UID of the Prometheus datasource to read exemplars from (required with metricSelector)"),
+		),
+		mcp.WithString("traceId",
+			mcp.Description("Trace ID from an exemplar (16 or 32 character hex string)"),
+		),
+		mcp.WithString("metricSelector",
+			mcp.Description("PromQL selector whose exemplars to inspect (e.g., 'http_request_duration_seconds_bucket{job=\"api\"}')"),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start of the exemplar search window in RFC3339 format (defaults to 1 hour ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End of the exemplar search window in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithString("serviceLabel",
+			mcp.Description("Loki label that holds the service name (default: service_name)"),
+		),
+		mcp.WithNumber("logLimit",
+			mcp.Description("Maximum number of correlated log lines to return (default: 10, max: 100)"),
+		),
+	)
+}
+
+// RegisterExemplar registers the drilldown_exemplar tool.
+func RegisterExemplar(s grafana.ToolRegistry) {
+	s.AddTool(newExemplarTool(), exemplarHandler)
+}
@@ -0,0 +1,228 @@
+package drilldown
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/krmcbride/mcp-grafana/internal/tools/loki"
+	"github.com/krmcbride/mcp-grafana/internal/tools/prometheus"
+	"github.com/krmcbride/mcp-grafana/internal/tools/tempo"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultErrorPatterns is the number of error log patterns recent_errors returns.
+	DefaultErrorPatterns = 5
+
+	// DefaultErrorTraces is the number of error traces recent_errors returns.
+	DefaultErrorTraces = 5
+
+	// errorLineFilter selects log lines that look like errors when no level label is available.
+	errorLineFilter = `(?i)(error|exception|fatal|panic)`
+)
+
+type recentErrorsParams struct {
+	Service                 string `json:"service"`
+	PrometheusDatasourceUID string `json:"prometheusDatasourceUid,omitempty"`
+	LokiDatasourceUID       string `json:"lokiDatasourceUid,omitempty"`
+	TempoDatasourceUID      string `json:"tempoDatasourceUid,omitempty"`
+	StartRFC3339            string `json:"startRfc3339,omitempty"`
+	EndRFC3339              string `json:"endRfc3339,omitempty"`
+	ErrorRateExpr           string `json:"errorRateExpr,omitempty"`
+	ServiceLabel            string `json:"serviceLabel,omitempty"`
+}
+
+// ErrorSection is one backend's contribution to a recent errors summary: the query that was run
+// and either the backing tool's result or the error it returned.
+type ErrorSection struct {
+	Tool   string          `json:"tool"`
+	Query  string          `json:"query"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// RecentErrors consolidates error signals for one service across Prometheus, Loki, and Tempo.
+// Sections for backends without a datasource UID are omitted.
+type RecentErrors struct {
+	Service     string        `json:"service"`
+	Start       string        `json:"start"`
+	End         string        `json:"end"`
+	ErrorRate   *ErrorSection `json:"errorRate,omitempty"`
+	LogPatterns *ErrorSection `json:"logPatterns,omitempty"`
+	ErrorTraces *ErrorSection `json:"errorTraces,omitempty"`
+}
+
+// runSection calls a backend tool handler and captures its JSON output or error message.
+// A backend tool disabled on this server is reported as the section's error instead.
+func runSection(ctx context.Context, handler server.ToolHandlerFunc, tool, query string, args map[string]any) *ErrorSection {
+	section := &ErrorSection{Tool: tool, Query: query}
+	if !grafana.ToolEnabled(tool) {
+		section.Error = fmt.Sprintf("tool disabled: %s is disabled on this server", tool)
+		return section
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool
+	request.Params.Arguments = args
+
+	result, err := handler(ctx, request)
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+
+	// The backend tools return a single text content: JSON on success, a message on error
+	var text string
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text = tc.Text
+			break
+		}
+	}
+
+	if result.IsError {
+		section.Error = text
+	} else {
+		section.Result = json.RawMessage(text)
+	}
+	return section
+}
+
+func recentErrorsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params recentErrorsParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Service == "" {
+		return mcp.NewToolResultError("service is required"), nil
+	}
+	if params.PrometheusDatasourceUID == "" && params.LokiDatasourceUID == "" && params.TempoDatasourceUID == "" {
+		return mcp.NewToolResultError("at least one of prometheusDatasourceUid, lokiDatasourceUid, or tempoDatasourceUid is required"), nil
+	}
+
+	start, end, err := parseTimeRange(params.StartRFC3339, params.EndRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !end.After(start) {
+		return mcp.NewToolResultError("end time must be after start time"), nil
+	}
+
+	result := &RecentErrors{
+		Service: params.Service,
+		Start:   start.Format(time.RFC3339),
+		End:     end.Format(time.RFC3339),
+	}
+
+	var wg sync.WaitGroup
+
+	if params.PrometheusDatasourceUID != "" {
+		expr := params.ErrorRateExpr
+		if expr == "" {
+			// Error ratio over the whole window from Tempo's span metrics, the one naming scheme shared across services
+			window := fmt.Sprintf("%ds", int(end.Sub(start).Seconds()))
+			service := grafana.QuoteLabelValue(params.Service)
+			expr = fmt.Sprintf(`sum(rate(traces_spanmetrics_calls_total{service=%s,status_code="STATUS_CODE_ERROR"}[%s])) `+
+				`/ sum(rate(traces_spanmetrics_calls_total{service=%s}[%s]))`, service, window, service, window)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.ErrorRate = runSection(ctx, prometheus.HandleQuery, "query_prometheus", expr, map[string]any{
+				"datasourceUid": params.PrometheusDatasourceUID,
+				"expr":          expr,
+				"timeRfc3339":   result.End,
+			})
+		}()
+	}
+
+	if params.LokiDatasourceUID != "" {
+		serviceLabel := params.ServiceLabel
+		if serviceLabel == "" {
+			serviceLabel = DefaultServiceLabel
+		}
+		logql := fmt.Sprintf("{%s=%s} |~ %s", serviceLabel, grafana.QuoteLabelValue(params.Service), grafana.QuoteLabelValue(errorLineFilter))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.LogPatterns = runSection(ctx, loki.HandleAggregateLogs, "aggregate_loki_logs", logql, map[string]any{
+				"datasourceUid": params.LokiDatasourceUID,
+				"logql":         logql,
+				"startRfc3339":  result.Start,
+				"endRfc3339":    result.End,
+				"topN":          DefaultErrorPatterns,
+			})
+		}()
+	}
+
+	if params.TempoDatasourceUID != "" {
+		traceql := fmt.Sprintf("{resource.service.name=%s && status=error}", grafana.QuoteLabelValue(params.Service))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.ErrorTraces = runSection(ctx, tempo.HandleSearchTraces, "search_tempo_traces", traceql, map[string]any{
+				"datasourceUid": params.TempoDatasourceUID,
+				"query":         traceql,
+				"startRfc3339":  result.Start,
+				"endRfc3339":    result.End,
+				"limit":         DefaultErrorTraces,
+			})
+		}()
+	}
+
+	wg.Wait()
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newRecentErrorsTool() mcp.Tool {
+	return mcp.NewTool(
+		"recent_errors",
+		mcp.WithDescription("Summarizes what is going wrong with a service right now in one call, over a shared time window: "+
+			"the error ratio from Prometheus, the top error log patterns from Loki, and recent error traces from Tempo. "+
+			"Provide any subset of the three datasource UIDs; each section shows the query that was run and either its result or its error, "+
+			"so one failing backend does not hide the others. Defaults to the last hour."),
+		mcp.WithString("service",
+			mcp.Description("Service name, matched against the span metrics 'service' label, the Loki service label, and the Tempo resource.service.name attribute"),
+			mcp.Required(),
+		),
+		mcp.WithString("prometheusDatasourceUid",
+			mcp.Description("The UID of the Prometheus datasource for the error rate (skipped if omitted)"),
+		),
+		mcp.WithString("lokiDatasourceUid",
+			mcp.Description("The UID of the Loki datasource for error log patterns (skipped if omitted)"),
+		),
+		mcp.WithString("tempoDatasourceUid",
+			mcp.Description("The UID of the Tempo datasource for error traces (skipped if omitted)"),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithString("errorRateExpr",
+			mcp.Description("PromQL for the error rate, evaluated at the end time "+
+				"(defaults to the error ratio of Tempo span metrics, traces_spanmetrics_calls_total, over the window)"),
+		),
+		mcp.WithString("serviceLabel",
+			mcp.Description("Loki label that holds the service name (default: service_name)"),
+		),
+	)
+}
+
+// RegisterRecentErrors registers the recent_errors tool.
+func RegisterRecentErrors(s grafana.ToolRegistry) {
+	s.AddTool(newRecentErrorsTool(), recentErrorsHandler)
+}
@@ -0,0 +1,231 @@
+// Package elasticsearch provides MCP tools for querying logs via Grafana's Elasticsearch/OpenSearch datasource proxy.
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+const (
+	// DefaultLogLimit is the default number of log hits to return if not specified.
+	DefaultLogLimit = 10
+
+	// MaxLogLimit is the maximum number of log hits that can be requested.
+	MaxLogLimit = 100
+
+	// DefaultTimeField is the timestamp field used when the datasource does not configure one.
+	DefaultTimeField = "@timestamp"
+
+	// DefaultMessageField is the log line field used when the datasource does not configure one.
+	DefaultMessageField = "message"
+)
+
+// defaultWindow is how far back searches reach when no start time is given.
+// Override with ES_DEFAULT_WINDOW (e.g., "15m", "6h").
+var defaultWindow = grafana.DurationFromEnv("ES_DEFAULT_WINDOW", time.Hour)
+
+// datedIndexPattern matches Grafana's dated index patterns such as "[logs-]YYYY.MM.DD".
+var datedIndexPattern = regexp.MustCompile(`^\[([^\]]*)\].*$`)
+
+// client provides methods for searching Elasticsearch via Grafana's datasource proxy.
+type client struct {
+	httpClient    *http.Client
+	baseURL       string
+	grafanaURL    string
+	datasourceUID string
+}
+
+// newClient creates an Elasticsearch client for the specified datasource UID.
+func newClient(ctx context.Context, datasourceUID string) (*client, error) {
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, "elasticsearch", "grafana-opensearch-datasource")
+	if err != nil {
+		return nil, err
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return nil, err
+	}
+
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		httpClient:    httpClient,
+		baseURL:       fmt.Sprintf("%s/api/datasources/proxy/uid/%s", grafanaURL, datasourceUID),
+		grafanaURL:    grafanaURL,
+		datasourceUID: datasourceUID,
+	}, nil
+}
+
+// makeRequest performs an HTTP request with an optional body and returns the response body.
+func (c *client) makeRequest(ctx context.Context, method, reqURL string, body []byte, contentType string) ([]byte, error) {
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method:      method,
+		URL:         reqURL,
+		Body:        body,
+		ContentType: contentType,
+		MaxBytes:    grafana.MaxResponseBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("elasticsearch API", statusCode, bodyBytes)
+	}
+
+	return bodyBytes, nil
+}
+
+// datasourceSettings holds the Elasticsearch settings configured on the Grafana datasource.
+type datasourceSettings struct {
+	Index        string
+	TimeField    string
+	MessageField string
+}
+
+// getSettings reads the index pattern, time field, and log message field from the datasource.
+// Dated index patterns (e.g., "[logs-]YYYY.MM.DD") are widened to a wildcard (e.g., "logs-*").
+func (c *client) getSettings(ctx context.Context) (*datasourceSettings, error) {
+	reqURL := fmt.Sprintf("%s/api/datasources/uid/%s", c.grafanaURL, url.PathEscape(c.datasourceUID))
+	bodyBytes, err := c.makeRequest(ctx, "GET", reqURL, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var ds struct {
+		Database string `json:"database"`
+		JSONData struct {
+			Index           string `json:"index"`
+			Interval        string `json:"interval"`
+			TimeField       string `json:"timeField"`
+			LogMessageField string `json:"logMessageField"`
+		} `json:"jsonData"`
+	}
+	if err := json.Unmarshal(bodyBytes, &ds); err != nil {
+		return nil, fmt.Errorf("unmarshalling datasource: %w", err)
+	}
+
+	settings := &datasourceSettings{
+		Index:        ds.JSONData.Index,
+		TimeField:    ds.JSONData.TimeField,
+		MessageField: ds.JSONData.LogMessageField,
+	}
+	if settings.Index == "" {
+		settings.Index = ds.Database // Older Grafana versions keep the index in database
+	}
+	if ds.JSONData.Interval != "" {
+		if m := datedIndexPattern.FindStringSubmatch(settings.Index); m != nil {
+			settings.Index = m[1] + "*"
+		}
+	}
+	if settings.TimeField == "" {
+		settings.TimeField = DefaultTimeField
+	}
+	if settings.MessageField == "" {
+		settings.MessageField = DefaultMessageField
+	}
+
+	return settings, nil
+}
+
+// searchHit is a single document in an Elasticsearch search response.
+type searchHit struct {
+	Index  string         `json:"_index"`
+	ID     string         `json:"_id"`
+	Source map[string]any `json:"_source"`
+}
+
+// searchResponse represents one search's response within an Elasticsearch _msearch response.
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []searchHit `json:"hits"`
+	} `json:"hits"`
+	Error *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+// search runs a query against index. Grafana's datasource proxy only lets Elasticsearch and
+// OpenSearch datasources POST to _msearch, so the query is sent as a one-search NDJSON body
+// (header line, then query line) and the single response is unwrapped.
+func (c *client) search(ctx context.Context, index string, body map[string]any) (*searchResponse, error) {
+	header, err := json.Marshal(map[string]any{"index": strings.TrimSpace(index), "ignore_unavailable": true})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling search header: %w", err)
+	}
+	query, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request body: %w", err)
+	}
+	ndjson := append(append(append(header, '\n'), query...), '\n')
+
+	bodyBytes, err := c.makeRequest(ctx, "POST", c.baseURL+"/_msearch", ndjson, "application/x-ndjson")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Responses []searchResponse `json:"responses"`
+	}
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshalling search response: %w", err)
+	}
+	if len(resp.Responses) == 0 {
+		return nil, fmt.Errorf("elasticsearch returned no search response")
+	}
+
+	search := &resp.Responses[0]
+	if search.Error != nil {
+		return nil, fmt.Errorf("elasticsearch error: %s: %s", search.Error.Type, search.Error.Reason)
+	}
+
+	return search, nil
+}
+
+// getDefaultTimeRange parses optional RFC3339 bounds, defaulting to the last defaultWindow.
+func getDefaultTimeRange(startRFC3339, endRFC3339 string) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if endRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, endRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing end time: %w", err)
+		}
+		end = t
+	}
+
+	start := end.Add(-defaultWindow)
+	if startRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, startRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing start time: %w", err)
+		}
+		start = t
+	}
+
+	return start, end, nil
+}
+
+// enforceLogLimit ensures the log limit is within acceptable bounds.
+func enforceLogLimit(requestedLimit int) int {
+	if requestedLimit <= 0 {
+		return DefaultLogLimit
+	}
+	if requestedLimit > MaxLogLimit {
+		return MaxLogLimit
+	}
+	return requestedLimit
+}
@@ -0,0 +1,225 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// LogEntry mirrors the loki package's log entry shape so results from either backend are interchangeable.
+type LogEntry struct {
+	Timestamp string            `json:"timestamp"` // Unix nanoseconds, as Loki returns them
+	Line      string            `json:"line,omitempty"`
+	Labels    map[string]string `json:"labels"`
+	Fields    map[string]any    `json:"fields,omitempty"` // Remaining _source fields
+}
+
+type queryLogsParams struct {
+	DatasourceUID  string `json:"datasourceUid"`
+	Query          string `json:"query,omitempty"`
+	StartRFC3339   string `json:"startRfc3339,omitempty"`
+	EndRFC3339     string `json:"endRfc3339,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	Direction      string `json:"direction,omitempty"`
+	Index          string `json:"index,omitempty"`
+	TimeField      string `json:"timeField,omitempty"`
+	MessageField   string `json:"messageField,omitempty"`
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"`
+}
+
+// buildSearchBody builds a _search body filtering on the time range and either a Lucene query string
+// or, when query is a JSON object, a query DSL clause.
+func buildSearchBody(query, timeField string, start, end time.Time, limit int, order string) (map[string]any, error) {
+	filters := []any{
+		map[string]any{"range": map[string]any{timeField: map[string]any{
+			"gte":    start.UnixMilli(),
+			"lte":    end.UnixMilli(),
+			"format": "epoch_millis",
+		}}},
+	}
+
+	query = strings.TrimSpace(query)
+	switch {
+	case strings.HasPrefix(query, "{"):
+		var clause map[string]any
+		if err := json.Unmarshal([]byte(query), &clause); err != nil {
+			return nil, fmt.Errorf("invalid query DSL: %w", err)
+		}
+		// Accept either a bare clause or a full {"query": {...}} body
+		if inner, ok := clause["query"].(map[string]any); ok && len(clause) == 1 {
+			clause = inner
+		}
+		filters = append(filters, clause)
+	case query != "" && query != "*":
+		filters = append(filters, map[string]any{"query_string": map[string]any{"query": query, "analyze_wildcard": true}})
+	}
+
+	return map[string]any{
+		"size":  limit,
+		"sort":  []any{map[string]any{timeField: map[string]any{"order": order, "unmapped_type": "date"}}},
+		"query": map[string]any{"bool": map[string]any{"filter": filters}},
+	}, nil
+}
+
+// hitToEntry converts a search hit into a LogEntry, moving the time and message fields out of _source.
+func hitToEntry(hit searchHit, timeField, messageField string) LogEntry {
+	entry := LogEntry{
+		Labels: map[string]string{"_index": hit.Index, "_id": hit.ID},
+		Fields: map[string]any{},
+	}
+
+	for k, v := range hit.Source {
+		switch k {
+		case timeField:
+			entry.Timestamp = timestampNanos(v)
+		case messageField:
+			entry.Line = fmt.Sprint(v)
+		default:
+			entry.Fields[k] = v
+		}
+	}
+
+	if len(entry.Fields) == 0 {
+		entry.Fields = nil
+	}
+	return entry
+}
+
+// timestampNanos converts an RFC3339 or epoch-millis time field value to Unix nanoseconds,
+// returning the raw value when it cannot be parsed.
+func timestampNanos(v any) string {
+	switch t := v.(type) {
+	case string:
+		if parsed, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			return strconv.FormatInt(parsed.UnixNano(), 10)
+		}
+		if ms, err := strconv.ParseInt(t, 10, 64); err == nil {
+			return strconv.FormatInt(ms*int64(time.Millisecond), 10)
+		}
+		return t
+	case float64:
+		return strconv.FormatInt(int64(t)*int64(time.Millisecond), 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func queryLogsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params queryLogsParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	order := "desc" // Newest first by default, like query_loki_logs
+	switch params.Direction {
+	case "", "backward":
+	case "forward":
+		order = "asc"
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid direction: %s (must be 'forward' or 'backward')", params.Direction)), nil
+	}
+
+	start, end, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Elasticsearch client: %v", err)), nil
+	}
+
+	ctx, cancel := grafana.WithRequestTimeout(ctx, c.httpClient, params.TimeoutSeconds)
+	defer cancel()
+
+	settings, err := c.getSettings(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("reading datasource settings: %v", err)), nil
+	}
+	if params.Index != "" {
+		settings.Index = params.Index
+	}
+	if params.TimeField != "" {
+		settings.TimeField = params.TimeField
+	}
+	if params.MessageField != "" {
+		settings.MessageField = params.MessageField
+	}
+	if settings.Index == "" {
+		return mcp.NewToolResultError("the datasource has no index configured; pass index explicitly"), nil
+	}
+
+	body, err := buildSearchBody(params.Query, settings.TimeField, start, end, enforceLogLimit(params.Limit), order)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resp, err := c.search(ctx, settings.Index, body)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entries := make([]LogEntry, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		entries = append(entries, hitToEntry(hit, settings.TimeField, settings.MessageField))
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(entries, len(entries)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newQueryLogsTool() mcp.Tool {
+	return mcp.NewTool(
+		"query_elasticsearch_logs",
+		mcp.WithDescription("Searches logs in an Elasticsearch or OpenSearch datasource using a Lucene query string (e.g., 'level:error AND service:api') "+
+			"or a query DSL clause (e.g., '{\"match\": {\"message\": \"timeout\"}}'). "+
+			"Returns log entries in the same shape as query_loki_logs: timestamp (Unix ns), line (the message field), "+
+			"labels (_index and _id), and the remaining document fields. "+
+			"The index, time field, and message field default to the datasource's settings. Defaults to the last "+grafana.FormatDuration(defaultWindow)+", 10 entries, newest first."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Elasticsearch/OpenSearch datasource to query; defaults to GRAFANA_DEFAULT_ELASTICSEARCH_UID or the default Elasticsearch/OpenSearch datasource"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Lucene query string, or a JSON query DSL clause (defaults to all documents in the time range)"),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of log entries to return (default: %d, max: %d)", DefaultLogLimit, MaxLogLimit)),
+		),
+		mcp.WithString("direction",
+			mcp.Description("Sort direction: 'forward' (oldest first) or 'backward' (newest first, default)"),
+		),
+		mcp.WithString("index",
+			mcp.Description("Index or index pattern to search (e.g., 'logs-*'; defaults to the datasource's index)"),
+		),
+		mcp.WithString("timeField",
+			mcp.Description("Timestamp field (defaults to the datasource's time field, or @timestamp)"),
+		),
+		mcp.WithString("messageField",
+			mcp.Description("Field returned as the log line (defaults to the datasource's log message field, or message)"),
+		),
+		mcp.WithNumber("timeoutSeconds",
+			mcp.Description("Override the request timeout for this call in seconds (default: the server-wide timeout, max: 300)"),
+		),
+	)
+}
+
+// RegisterQueryLogs registers the query_elasticsearch_logs tool with the MCP server.
+func RegisterQueryLogs(s grafana.ToolRegistry) {
+	s.AddTool(newQueryLogsTool(), queryLogsHandler)
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolFilter registers tools on the server unless MCP_ENABLED_TOOLS or MCP_DISABLED_TOOLS
+// (comma-separated tool names) exclude them, so excluded tools never reach the server.
+// When MCP_ENABLED_TOOLS is set only the listed tools are kept; MCP_DISABLED_TOOLS is applied after it.
+type toolFilter struct {
+	s             *server.MCPServer
+	enabledTools  map[string]bool
+	disabledTools map[string]bool
+
+	seen    map[string]bool
+	skipped []string
+}
+
+var _ grafana.ToolRegistry = (*toolFilter)(nil)
+
+// newToolFilter reads the tool filter settings from the environment.
+func newToolFilter(s *server.MCPServer) *toolFilter {
+	return &toolFilter{
+		s:             s,
+		enabledTools:  grafana.SetFromEnv("MCP_ENABLED_TOOLS"),
+		disabledTools: grafana.SetFromEnv("MCP_DISABLED_TOOLS"),
+		seen:          make(map[string]bool),
+	}
+}
+
+// AddTool registers the tool on the server, or records it as skipped if it is filtered out.
+func (f *toolFilter) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	f.seen[tool.Name] = true
+	if !f.enabled(tool.Name) {
+		f.skipped = append(f.skipped, tool.Name)
+		return
+	}
+	f.s.AddTool(tool, handler)
+}
+
+// enabled reports whether the filter settings keep the named tool.
+func (f *toolFilter) enabled(name string) bool {
+	return (len(f.enabledTools) == 0 || f.enabledTools[name]) && !f.disabledTools[name]
+}
+
+// report logs the skipped tools and any filter names that match no tool to stderr.
+func (f *toolFilter) report() {
+	var unknown []string
+	for name := range f.enabledTools {
+		if !f.seen[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range f.disabledTools {
+		if !f.seen[name] && !f.enabledTools[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		fmt.Fprintf(os.Stderr, "Ignoring unknown tool names in MCP_ENABLED_TOOLS/MCP_DISABLED_TOOLS: %s\n", strings.Join(unknown, ", "))
+	}
+
+	if len(f.skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d tools: %s\n", len(f.skipped), strings.Join(f.skipped, ", "))
+	}
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// serverToolNames lists the tools registered on s through its tools/list handler.
+func serverToolNames(t *testing.T, s *server.MCPServer) []string {
+	t.Helper()
+	resp := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	r, ok := resp.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("unexpected tools/list response: %#v", resp)
+	}
+	result, ok := r.Result.(mcp.ListToolsResult)
+	if !ok {
+		t.Fatalf("unexpected tools/list result: %#v", r.Result)
+	}
+	names := []string{}
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestToolFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     string
+		disabled    string
+		wantServer  []string
+		wantSkipped []string
+	}{
+		{name: "no filter", wantServer: []string{"a", "b", "c"}},
+		{name: "enabled only", enabled: "a,c,missing", wantServer: []string{"a", "c"}, wantSkipped: []string{"b"}},
+		{name: "disabled only", disabled: "b", wantServer: []string{"a", "c"}, wantSkipped: []string{"b"}},
+		{name: "disabled after enabled", enabled: "a,b", disabled: "b", wantServer: []string{"a"}, wantSkipped: []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MCP_ENABLED_TOOLS", tt.enabled)
+			t.Setenv("MCP_DISABLED_TOOLS", tt.disabled)
+
+			srv := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false))
+			f := newToolFilter(srv)
+			for _, name := range []string{"a", "b", "c"} {
+				f.AddTool(mcp.NewTool(name), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					return mcp.NewToolResultText("ok"), nil
+				})
+			}
+
+			if got := serverToolNames(t, srv); !reflect.DeepEqual(got, tt.wantServer) {
+				t.Errorf("server tools = %v, want %v", got, tt.wantServer)
+			}
+			if !reflect.DeepEqual(f.skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", f.skipped, tt.wantSkipped)
+			}
+
+			// Tools that dispatch to other tools' handlers consult the same check
+			for _, name := range []string{"a", "b", "c"} {
+				want := false
+				for _, kept := range tt.wantServer {
+					want = want || kept == name
+				}
+				if got := f.enabled(name); got != want {
+					t.Errorf("enabled(%q) = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
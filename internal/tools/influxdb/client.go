@@ -0,0 +1,224 @@
+// Package influxdb provides MCP tools for querying InfluxDB via Grafana's datasource proxy.
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+const (
+	// LanguageInfluxQL selects the InfluxQL query language (InfluxDB 1.x and the 2.x v1 compatibility API).
+	LanguageInfluxQL = "influxql"
+
+	// LanguageFlux selects the Flux query language (InfluxDB 2.x).
+	LanguageFlux = "flux"
+
+	// DefaultRowLimit is the default number of rows returned per table if not specified.
+	DefaultRowLimit = 100
+
+	// MaxRowLimit is the maximum number of rows that can be requested per table.
+	MaxRowLimit = 1000
+)
+
+// defaultWindow is how far back queries reach when no start time is given.
+// Override with INFLUX_DEFAULT_WINDOW (e.g., "15m", "6h").
+var defaultWindow = grafana.DurationFromEnv("INFLUX_DEFAULT_WINDOW", time.Hour)
+
+// client provides methods for querying InfluxDB via Grafana's datasource proxy.
+type client struct {
+	httpClient    *http.Client
+	baseURL       string
+	grafanaURL    string
+	datasourceUID string
+}
+
+// newClient creates an InfluxDB client for the specified datasource UID.
+func newClient(ctx context.Context, datasourceUID string) (*client, error) {
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, "influxdb")
+	if err != nil {
+		return nil, err
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return nil, err
+	}
+
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		httpClient:    httpClient,
+		baseURL:       fmt.Sprintf("%s/api/datasources/proxy/uid/%s", grafanaURL, datasourceUID),
+		grafanaURL:    grafanaURL,
+		datasourceUID: datasourceUID,
+	}, nil
+}
+
+// makeRequest performs an HTTP request with an optional body and returns the response body.
+func (c *client) makeRequest(ctx context.Context, method, reqURL string, body []byte, header http.Header) ([]byte, error) {
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method:   method,
+		URL:      reqURL,
+		Body:     body,
+		Header:   header,
+		MaxBytes: grafana.MaxResponseBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("influxdb API", statusCode, bodyBytes)
+	}
+
+	return bodyBytes, nil
+}
+
+// datasourceSettings holds the InfluxDB settings configured on the Grafana datasource.
+type datasourceSettings struct {
+	Language     string
+	Database     string
+	Organization string
+}
+
+// getSettings reads the query language, database, and organization configured on the datasource.
+func (c *client) getSettings(ctx context.Context) (*datasourceSettings, error) {
+	reqURL := fmt.Sprintf("%s/api/datasources/uid/%s", c.grafanaURL, url.PathEscape(c.datasourceUID))
+	bodyBytes, err := c.makeRequest(ctx, "GET", reqURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ds struct {
+		Database string `json:"database"`
+		JSONData struct {
+			Version      string `json:"version"`
+			DBName       string `json:"dbName"`
+			Organization string `json:"organization"`
+		} `json:"jsonData"`
+	}
+	if err := json.Unmarshal(bodyBytes, &ds); err != nil {
+		return nil, fmt.Errorf("unmarshalling datasource: %w", err)
+	}
+
+	settings := &datasourceSettings{
+		Language:     LanguageInfluxQL,
+		Database:     ds.JSONData.DBName,
+		Organization: ds.JSONData.Organization,
+	}
+	if ds.JSONData.Version == "Flux" {
+		settings.Language = LanguageFlux
+	}
+	if settings.Database == "" {
+		settings.Database = ds.Database // Older Grafana versions keep the database at the top level
+	}
+
+	return settings, nil
+}
+
+// influxQLResponse represents the response from InfluxDB's /query endpoint.
+type influxQLResponse struct {
+	Results []struct {
+		StatementID int `json:"statement_id"`
+		Series      []struct {
+			Name    string            `json:"name"`
+			Tags    map[string]string `json:"tags,omitempty"`
+			Columns []string          `json:"columns"`
+			Values  [][]any           `json:"values"`
+		} `json:"series"`
+		Error string `json:"error,omitempty"`
+	} `json:"results"`
+	Error string `json:"error,omitempty"`
+}
+
+// queryInfluxQL runs an InfluxQL query against the /query endpoint with millisecond epoch timestamps.
+func (c *client) queryInfluxQL(ctx context.Context, database, query string) (*influxQLResponse, error) {
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("epoch", "ms")
+	if database != "" {
+		params.Add("db", database)
+	}
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", c.baseURL+"/query?"+params.Encode(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp influxQLResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshalling response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("influxdb error: %s", resp.Error)
+	}
+
+	return &resp, nil
+}
+
+// queryFlux runs a Flux query against the /api/v2/query endpoint and returns the annotated CSV response.
+func (c *client) queryFlux(ctx context.Context, organization, query string) ([]byte, error) {
+	body, err := json.Marshal(map[string]any{
+		"query": query,
+		"type":  "flux",
+		"dialect": map[string]any{
+			"header":      true,
+			"annotations": []string{"datatype", "group", "default"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	params := url.Values{}
+	if organization != "" {
+		params.Add("org", organization)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Accept", "application/csv")
+
+	return c.makeRequest(ctx, "POST", c.baseURL+"/api/v2/query?"+params.Encode(), body, header)
+}
+
+// getDefaultTimeRange parses optional RFC3339 bounds, defaulting to the last defaultWindow.
+func getDefaultTimeRange(startRFC3339, endRFC3339 string) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if endRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, endRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing end time: %w", err)
+		}
+		end = t
+	}
+
+	start := end.Add(-defaultWindow)
+	if startRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, startRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing start time: %w", err)
+		}
+		start = t
+	}
+
+	return start, end, nil
+}
+
+// enforceRowLimit ensures the per-table row limit is within acceptable bounds.
+func enforceRowLimit(requestedLimit int) int {
+	if requestedLimit <= 0 {
+		return DefaultRowLimit
+	}
+	if requestedLimit > MaxRowLimit {
+		return MaxRowLimit
+	}
+	return requestedLimit
+}
@@ -0,0 +1,320 @@
+package influxdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// targetPoints is the number of points per series the substituted interval aims for, like a panel of typical width.
+const targetPoints = 250
+
+// Table is one series (InfluxQL) or one table of the result stream (Flux).
+type Table struct {
+	Name      string            `json:"name,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"` // InfluxQL GROUP BY tags, or the Flux group key
+	Columns   []string          `json:"columns"`
+	Rows      [][]any           `json:"rows"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+type queryParams struct {
+	DatasourceUID  string `json:"datasourceUid"`
+	Query          string `json:"query"`
+	Language       string `json:"language,omitempty"`
+	Organization   string `json:"organization,omitempty"`
+	StartRFC3339   string `json:"startRfc3339,omitempty"`
+	EndRFC3339     string `json:"endRfc3339,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"`
+}
+
+// substituteMacros replaces the Grafana time range macros a dashboard query may contain.
+func substituteMacros(query, language string, start, end time.Time) string {
+	interval := time.Duration(math.Ceil(end.Sub(start).Seconds()/targetPoints)) * time.Second
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	var r *strings.Replacer
+	if language == LanguageFlux {
+		r = strings.NewReplacer(
+			"v.timeRangeStart", start.UTC().Format(time.RFC3339),
+			"v.timeRangeStop", end.UTC().Format(time.RFC3339),
+			"v.windowPeriod", interval.String(),
+		)
+	} else {
+		r = strings.NewReplacer(
+			"$timeFilter", fmt.Sprintf("time >= %dms and time <= %dms", start.UnixMilli(), end.UnixMilli()),
+			"$__interval", fmt.Sprintf("%ds", int(interval.Seconds())),
+			"$interval", fmt.Sprintf("%ds", int(interval.Seconds())),
+		)
+	}
+	return r.Replace(query)
+}
+
+// influxQLTables flattens the series of every statement result into tables.
+func influxQLTables(resp *influxQLResponse, limit int) ([]Table, error) {
+	tables := []Table{}
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("statement %d: %s", result.StatementID, result.Error)
+		}
+		for _, s := range result.Series {
+			t := Table{Name: s.Name, Tags: s.Tags, Columns: s.Columns, Rows: s.Values}
+			if len(t.Rows) > limit {
+				t.Rows, t.Truncated = t.Rows[:limit], true
+			}
+			tables = append(tables, t)
+		}
+	}
+	return tables, nil
+}
+
+// fluxTables parses Flux annotated CSV into tables, moving group key columns into Tags.
+func fluxTables(data []byte, limit int) ([]Table, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	var (
+		tables    []*Table
+		current   *Table
+		datatypes []string
+		groups    []string
+		header    []string
+		lastKey   string
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing flux response: %w", err)
+		}
+
+		// Annotations start a new block with its own header row
+		switch record[0] {
+		case "#datatype":
+			datatypes, header = record, nil
+			continue
+		case "#group":
+			groups = record
+			continue
+		case "#default":
+			continue
+		}
+		if header == nil {
+			header = record
+			continue
+		}
+		if header[0] == "error" {
+			return nil, fmt.Errorf("flux error: %s", record[0])
+		}
+
+		key := ""
+		row := []any{}
+		tags := map[string]string{}
+		var columns []string
+		for i, col := range header {
+			if i == 0 || i >= len(record) {
+				continue // The first column only carries annotations
+			}
+			switch {
+			case col == "result":
+				key = record[i] + key
+			case col == "table":
+				key += "/" + record[i]
+			case i < len(groups) && groups[i] == "true":
+				tags[col] = record[i]
+			default:
+				columns = append(columns, col)
+				datatype := ""
+				if i < len(datatypes) {
+					datatype = datatypes[i]
+				}
+				row = append(row, fluxValue(record[i], datatype))
+			}
+		}
+
+		if current == nil || key != lastKey {
+			current = &Table{Tags: tags, Columns: columns, Rows: [][]any{}}
+			if name, ok := tags["_measurement"]; ok {
+				current.Name = name
+				delete(tags, "_measurement")
+			}
+			if len(current.Tags) == 0 {
+				current.Tags = nil
+			}
+			tables = append(tables, current)
+			lastKey = key
+		}
+		if len(current.Rows) >= limit {
+			current.Truncated = true
+			continue
+		}
+		current.Rows = append(current.Rows, row)
+	}
+
+	result := make([]Table, 0, len(tables))
+	for _, t := range tables {
+		result = append(result, *t)
+	}
+	return result, nil
+}
+
+// fluxValue converts an annotated CSV cell to a JSON value based on its column datatype.
+func fluxValue(s, datatype string) any {
+	if s == "" {
+		return nil
+	}
+	switch datatype {
+	case "long":
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v
+		}
+	case "unsignedLong":
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return v
+		}
+	case "double":
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+func queryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params queryParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if strings.TrimSpace(params.Query) == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+
+	language := strings.ToLower(params.Language)
+	switch language {
+	case "", LanguageInfluxQL, LanguageFlux:
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid language: %s (must be '%s' or '%s')", params.Language, LanguageInfluxQL, LanguageFlux)), nil
+	}
+
+	start, end, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating InfluxDB client: %v", err)), nil
+	}
+
+	ctx, cancel := grafana.WithRequestTimeout(ctx, c.httpClient, params.TimeoutSeconds)
+	defer cancel()
+
+	settings, err := c.getSettings(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("reading datasource settings: %v", err)), nil
+	}
+	if language == "" {
+		language = settings.Language
+	}
+	if params.Organization != "" {
+		settings.Organization = params.Organization
+	}
+
+	query := substituteMacros(params.Query, language, start, end)
+	limit := enforceRowLimit(params.Limit)
+
+	var tables []Table
+	if language == LanguageFlux {
+		if err := checkFluxReadOnly(query); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := c.queryFlux(ctx, settings.Organization, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		tables, err = fluxTables(data, limit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		resp, err := c.queryInfluxQL(ctx, settings.Database, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		tables, err = influxQLTables(resp, limit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(tables, len(tables)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newQueryTool() mcp.Tool {
+	return mcp.NewTool(
+		"query_influxdb",
+		mcp.WithDescription("Executes an InfluxQL or Flux query against an InfluxDB datasource and returns the result as tables "+
+			"of columns and rows, with InfluxQL GROUP BY tags or the Flux group key in tags. "+
+			"The language defaults to the datasource's configured query language, and InfluxQL always runs against the datasource's configured database. "+
+			"Grafana time macros are substituted from startRfc3339/endRfc3339: $timeFilter, $__interval, and $interval for InfluxQL; "+
+			"v.timeRangeStart, v.timeRangeStop, and v.windowPeriod for Flux. "+
+			"Flux queries that write or send data (to(), http.post(), notification packages) are rejected unless the server was started with GRAFANA_ALLOW_WRITE=1. "+
+			"InfluxQL example: 'SELECT mean(\"usage_idle\") FROM \"cpu\" WHERE $timeFilter GROUP BY time($__interval), \"host\"'. "+
+			"Flux example: 'from(bucket: \"telegraf\") |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> filter(fn: (r) => r._measurement == \"cpu\")'."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the InfluxDB datasource to query; defaults to GRAFANA_DEFAULT_INFLUXDB_UID or the default InfluxDB datasource"),
+		),
+		mcp.WithString("query",
+			mcp.Description("The InfluxQL or Flux query to execute"),
+			mcp.Required(),
+		),
+		mcp.WithString("language",
+			mcp.Description("Query language: 'influxql' or 'flux' (defaults to the datasource's configured language)"),
+		),
+		mcp.WithString("organization",
+			mcp.Description("Flux organization to query (defaults to the datasource's organization)"),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time substituted into time macros, in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time substituted into time macros, in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of rows to return per table (default: %d, max: %d)", DefaultRowLimit, MaxRowLimit)),
+		),
+		mcp.WithNumber("timeoutSeconds",
+			mcp.Description("Override the request timeout for this call in seconds (default: the server-wide timeout, max: 300)"),
+		),
+	)
+}
+
+// RegisterQuery registers the query_influxdb tool with the MCP server.
+func RegisterQuery(s grafana.ToolRegistry) {
+	s.AddTool(newQueryTool(), queryHandler)
+}
@@ -0,0 +1,106 @@
+package influxdb
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+// allowWriteEnv permits Flux queries that write or send data when set to "1" or "true".
+const allowWriteEnv = "GRAFANA_ALLOW_WRITE"
+
+// fluxImportPattern matches a Flux import with its optional alias, e.g., import h "http".
+var fluxImportPattern = regexp.MustCompile(`\bimport\s+(?:([A-Za-z_]\w*)\s+)?"([^"]+)"`)
+
+// fluxToPattern matches a call to to() or wideTo(), bare or from any package
+// (experimental.to, sql.to, mqtt.to, influxdb.wideTo).
+var fluxToPattern = regexp.MustCompile(`(?:^|[^\w])(to|wideTo)\s*\(`)
+
+// fluxSendPackages lists packages, by the last element of their import path, whose functions only
+// exist to send data out of the query: notification endpoints, message brokers, and raw HTTP requests.
+var fluxSendPackages = map[string]bool{
+	"slack": true, "pagerduty": true, "opsgenie": true, "telegram": true, "discord": true,
+	"teams": true, "webexteams": true, "sensu": true, "servicenow": true, "victorops": true,
+	"zenoss": true, "alerta": true, "bigpanda": true, "pushbullet": true, "mqtt": true,
+	"kafka": true, "requests": true,
+}
+
+// fluxHTTPWrites lists the functions of the http package that send a request.
+var fluxHTTPWrites = []string{"post", "endpoint"}
+
+// checkFluxReadOnly rejects a Flux query that writes to a bucket or sends data elsewhere: calls to
+// to() or wideTo() from any package, http.post() and http.endpoint(), and imports of notification,
+// message broker, and HTTP request packages. Unless GRAFANA_ALLOW_WRITE is set, such queries would let
+// a read-only server write through the datasource's token. Like query_sql's check, it is a guard
+// against accidents, not a security boundary.
+func checkFluxReadOnly(query string) error {
+	if grafana.BoolFromEnv(allowWriteEnv) {
+		return nil
+	}
+
+	notPermitted := func(what string) error {
+		return fmt.Errorf("flux query is not permitted: %s writes or sends data, and this server is read-only unless %s=1 is set",
+			what, allowWriteEnv)
+	}
+
+	withStrings := scanFlux(query, false)
+	code := scanFlux(query, true)
+
+	for _, m := range fluxImportPattern.FindAllStringSubmatch(withStrings, -1) {
+		alias, pkg := m[1], m[2]
+		if fluxSendPackages[path.Base(pkg)] {
+			return notPermitted(fmt.Sprintf("importing %q", pkg))
+		}
+		if pkg == "http" {
+			if alias == "" {
+				alias = "http"
+			}
+			for _, fn := range fluxHTTPWrites {
+				if regexp.MustCompile(`\b` + regexp.QuoteMeta(alias) + `\s*\.\s*` + fn + `\s*\(`).MatchString(code) {
+					return notPermitted("http." + fn + "()")
+				}
+			}
+		}
+	}
+
+	if m := fluxToPattern.FindStringSubmatch(code); m != nil {
+		return notPermitted(m[1] + "()")
+	}
+	return nil
+}
+
+// scanFlux returns query without comments. With blankStrings, string literals are emptied too,
+// so their contents can't be mistaken for code.
+func scanFlux(query string, blankStrings bool) string {
+	var b strings.Builder
+	s := []rune(query)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			b.WriteRune('"')
+			for i++; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					if !blankStrings {
+						b.WriteRune(s[i])
+					}
+					i++
+				}
+				if !blankStrings {
+					b.WriteRune(s[i])
+				}
+			}
+			b.WriteRune('"')
+		case s[i] == '/' && i+1 < len(s) && s[i+1] == '/':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			b.WriteRune('\n')
+		default:
+			b.WriteRune(s[i])
+		}
+	}
+	return b.String()
+}
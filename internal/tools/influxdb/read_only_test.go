@@ -0,0 +1,47 @@
+package influxdb
+
+import "testing"
+
+func TestCheckFluxReadOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "read", query: `from(bucket: "telegraf") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "cpu")`},
+		{name: "to in a string", query: `from(bucket: "to(") |> range(start: -1h)`},
+		{name: "to in a comment", query: "// |> to(bucket: \"copy\")\nfrom(bucket: \"telegraf\") |> range(start: -1h)"},
+		{name: "http get", query: "import \"http\"\nhttp.get(url: \"https://example.com\")"},
+		{name: "sql from", query: "import \"sql\"\nsql.from(driverName: \"postgres\", dataSourceName: \"x\", query: \"SELECT 1\")"},
+		{name: "identifier ending in to", query: `from(bucket: "b") |> range(start: -1h) |> map(fn: (r) => ({r with v: into(x: r._value)}))`},
+
+		{name: "to", query: `from(bucket: "a") |> range(start: -1h) |> to(bucket: "b")`, wantErr: true},
+		{name: "experimental to", query: "import \"experimental\"\nfrom(bucket: \"a\") |> range(start: -1h) |> experimental.to(bucket: \"b\")", wantErr: true},
+		{name: "sql to", query: "import \"sql\"\nfrom(bucket: \"a\") |> range(start: -1h) |> sql.to(driverName: \"postgres\", dataSourceName: \"x\", table: \"t\")", wantErr: true},
+		{name: "wide to", query: "import \"influxdata/influxdb\"\nfrom(bucket: \"a\") |> range(start: -1h) |> influxdb.wideTo(bucket: \"b\")", wantErr: true},
+		{name: "http post", query: "import \"http\"\nhttp.post(url: \"https://example.com\", data: bytes(v: \"x\"))", wantErr: true},
+		{name: "aliased http post", query: "import h \"http\"\nh.post(url: \"https://example.com\")", wantErr: true},
+		{name: "notification package", query: "import \"slack\"\nslack.message(url: \"https://example.com\", text: \"hi\", color: \"good\")", wantErr: true},
+		{name: "contrib notification package", query: "import \"contrib/sranka/telegram\"\ntelegram.message(token: \"t\", channel: \"c\", text: \"hi\")", wantErr: true},
+		{name: "mqtt", query: "import \"experimental/mqtt\"\nfrom(bucket: \"a\") |> range(start: -1h) |> mqtt.to(broker: \"tcp://x:1883\")", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFluxReadOnly(tt.query)
+			if tt.wantErr && err == nil {
+				t.Fatalf("checkFluxReadOnly(%q) = nil, want an error", tt.query)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("checkFluxReadOnly(%q) error: %v", tt.query, err)
+			}
+		})
+	}
+
+	t.Run("allowed with GRAFANA_ALLOW_WRITE", func(t *testing.T) {
+		t.Setenv(allowWriteEnv, "1")
+		if err := checkFluxReadOnly(`from(bucket: "a") |> range(start: -1h) |> to(bucket: "b")`); err != nil {
+			t.Fatalf("checkFluxReadOnly() error with %s=1: %v", allowWriteEnv, err)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultAggregateSampleSize is the default number of log lines sampled for aggregation.
+	DefaultAggregateSampleSize = 1000
+
+	// MaxAggregateSampleSize is the maximum number of log lines that can be sampled for aggregation.
+	MaxAggregateSampleSize = 5000
+
+	// DefaultTopN is the default number of patterns returned by aggregation.
+	DefaultTopN = 10
+
+	// MaxTopN is the maximum number of patterns that can be returned by aggregation.
+	MaxTopN = 100
+
+	// patternPlaceholder replaces variable tokens in normalized log lines.
+	patternPlaceholder = "<*>"
+)
+
+// defaultNormalizePatterns strip the variable parts of log lines, most specific first.
+var defaultNormalizePatterns = []string{
+	`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, // UUIDs
+	`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`,         // ISO-8601 timestamps
+	`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`,                                            // IPv4 addresses
+	`\b(0x)?[0-9a-fA-F]{16,}\b`,                                                   // Hex IDs (trace IDs, hashes)
+	`\d+(\.\d+)?`,                                                                 // Numbers
+}
+
+// LogPattern represents a normalized log line and how often it occurred.
+type LogPattern struct {
+	Pattern string `json:"pattern"`
+	Count   int    `json:"count"`
+	Example string `json:"example"`
+}
+
+// AggregateResult represents the output of aggregate_loki_logs.
+type AggregateResult struct {
+	SampledLines     int          `json:"sampledLines"`
+	DistinctPatterns int          `json:"distinctPatterns"`
+	Patterns         []LogPattern `json:"patterns"`
+}
+
+type aggregateLogsParams struct {
+	DatasourceUID string   `json:"datasourceUid"`
+	LogQL         string   `json:"logql"`
+	StartRFC3339  string   `json:"startRfc3339,omitempty"`
+	EndRFC3339    string   `json:"endRfc3339,omitempty"`
+	SampleSize    int      `json:"sampleSize,omitempty"`
+	TopN          int      `json:"topN,omitempty"`
+	Patterns      []string `json:"patterns,omitempty"`
+}
+
+func aggregateLogsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params aggregateLogsParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	patternSources := params.Patterns
+	if len(patternSources) == 0 {
+		patternSources = defaultNormalizePatterns
+	}
+
+	normalizers := make([]*regexp.Regexp, 0, len(patternSources))
+	for _, p := range patternSources {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid pattern %q: %v", p, err)), nil
+		}
+		normalizers = append(normalizers, re)
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
+	}
+
+	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sampleSize := enforceBoundedLimit(params.SampleSize, DefaultAggregateSampleSize, MaxAggregateSampleSize)
+	topN := enforceBoundedLimit(params.TopN, DefaultTopN, MaxTopN)
+
+	streams, err := c.fetchLogs(ctx, params.LogQL, startTime, endTime, sampleSize, "backward", 0)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := aggregateLines(streams, normalizers, topN)
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(result, notice))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// aggregateLines normalizes every log line in the streams and returns the topN most frequent patterns.
+func aggregateLines(streams []logStream, normalizers []*regexp.Regexp, topN int) *AggregateResult {
+	counts := make(map[string]*LogPattern)
+	sampled := 0
+
+	for _, stream := range streams {
+		for _, value := range stream.Values {
+			if len(value) < 2 {
+				continue
+			}
+
+			var line string
+			if err := json.Unmarshal(value[1], &line); err != nil {
+				continue // Skip non-string values (metric samples)
+			}
+			sampled++
+
+			normalized := line
+			for _, re := range normalizers {
+				normalized = re.ReplaceAllString(normalized, patternPlaceholder)
+			}
+
+			if p, ok := counts[normalized]; ok {
+				p.Count++
+			} else {
+				counts[normalized] = &LogPattern{Pattern: normalized, Count: 1, Example: line}
+			}
+		}
+	}
+
+	patterns := make([]LogPattern, 0, len(counts))
+	for _, p := range counts {
+		patterns = append(patterns, *p)
+	}
+
+	// Most frequent first; ties broken alphabetically for stable output
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Count != patterns[j].Count {
+			return patterns[i].Count > patterns[j].Count
+		}
+		return patterns[i].Pattern < patterns[j].Pattern
+	})
+
+	result := &AggregateResult{
+		SampledLines:     sampled,
+		DistinctPatterns: len(patterns),
+	}
+	if len(patterns) > topN {
+		patterns = patterns[:topN]
+	}
+	result.Patterns = patterns
+
+	return result
+}
+
+// HandleAggregateLogs runs the aggregate_loki_logs tool handler; recent_errors uses it for the top error patterns.
+func HandleAggregateLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return aggregateLogsHandler(ctx, request)
+}
+
+func newAggregateLogsTool() mcp.Tool {
+	return mcp.NewTool(
+		"aggregate_loki_logs",
+		mcp.WithDescription("Runs a LogQL log query and groups the matching lines into normalized patterns, returning the top-N patterns with counts. "+
+			"Variable tokens (UUIDs, timestamps, IPs, hex IDs, numbers) are replaced with <*> so repeated messages collapse together. "+
+			"Far more token-efficient than query_loki_logs for questions like 'what are the most frequent errors'. "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+", sampling the newest 1000 lines and returning the top 10 patterns."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+		mcp.WithString("logql",
+			mcp.Description("LogQL log query expression (e.g., '{app=\"nginx\"} |= \"error\"'); metric queries are not supported"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("sampleSize",
+			mcp.Description("Number of log lines to sample for aggregation (default: 1000, max: 5000)"),
+		),
+		mcp.WithNumber("topN",
+			mcp.Description("Number of patterns to return (default: 10, max: 100)"),
+		),
+		mcp.WithArray("patterns",
+			mcp.Description("Regexes whose matches are replaced with <*> during normalization; replaces the built-in set of UUID, timestamp, IP, hex ID, and number patterns"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+}
+
+// RegisterAggregateLogs registers the aggregate_loki_logs tool with the MCP server.
+func RegisterAggregateLogs(s grafana.ToolRegistry) {
+	s.AddTool(newAggregateLogsTool(), aggregateLogsHandler)
+}
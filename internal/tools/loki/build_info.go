@@ -0,0 +1,117 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// BuildInfo represents the response from Loki's buildinfo endpoint, plus feature
+// support derived from the version.
+type BuildInfo struct {
+	Version   string          `json:"version"`
+	Revision  string          `json:"revision,omitempty"`
+	Branch    string          `json:"branch,omitempty"`
+	BuildUser string          `json:"buildUser,omitempty"`
+	BuildDate string          `json:"buildDate,omitempty"`
+	GoVersion string          `json:"goVersion,omitempty"`
+	Features  map[string]bool `json:"features,omitempty"`
+}
+
+// featureMinVersions maps Loki features to the [major, minor] release that introduced them.
+var featureMinVersions = map[string][2]int{
+	"patterns":           {3, 0},
+	"structuredMetadata": {3, 0},
+	"detectedFields":     {3, 1},
+}
+
+type buildInfoParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+}
+
+// fetchBuildInfo retrieves version and build details from Loki.
+func (c *client) fetchBuildInfo(ctx context.Context) (*BuildInfo, error) {
+	bodyBytes, err := c.makeRequest(ctx, "GET", "/loki/api/v1/status/buildinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info BuildInfo
+	if err := json.Unmarshal(bodyBytes, &info); err != nil {
+		return nil, fmt.Errorf("unmarshalling build info: %w", err)
+	}
+
+	// Features are only reported when the version is a recognizable release number;
+	// builds like "main-abc1234" or managed-service versions are left undetermined.
+	if major, minor, ok := parseVersion(info.Version); ok {
+		info.Features = make(map[string]bool, len(featureMinVersions))
+		for feature, minVersion := range featureMinVersions {
+			info.Features[feature] = major > minVersion[0] || (major == minVersion[0] && minor >= minVersion[1])
+		}
+	}
+
+	return &info, nil
+}
+
+// parseVersion extracts the major and minor numbers from versions like "3.1.0" or "v2.9.4".
+func parseVersion(version string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+func buildInfoHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params buildInfoParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
+	}
+
+	info, err := c.fetchBuildInfo(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(info)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newBuildInfoTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_loki_build_info",
+		mcp.WithDescription("Gets the version and build details of a Loki datasource, plus which version-dependent features "+
+			"(patterns, structured metadata, detected fields) it supports. Features are omitted when the version is not a release number. "+
+			"Check this before relying on newer LogQL features against an unfamiliar Loki deployment."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+	)
+}
+
+// RegisterBuildInfo registers the get_loki_build_info tool with the MCP server.
+func RegisterBuildInfo(s grafana.ToolRegistry) {
+	s.AddTool(newBuildInfoTool(), buildInfoHandler)
+}
@@ -6,7 +6,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -23,14 +22,36 @@ const (
 	MaxLogLimit = 100
 )
 
+// defaultWindow is how far back queries reach when no start time is given.
+// Override with LOKI_DEFAULT_WINDOW (e.g., "15m", "6h").
+var defaultWindow = grafana.DurationFromEnv("LOKI_DEFAULT_WINDOW", time.Hour)
+
+// maxRange caps how long a time range queries may cover. Set with LOKI_MAX_RANGE (e.g., "720h");
+// unset means no limit.
+var maxRange = grafana.NewRangeLimit("LOKI_MAX_RANGE")
+
+// maxLogLimit caps the number of log lines per query. Override with LOKI_MAX_LOG_LIMIT.
+var maxLogLimit = grafana.IntFromEnv("LOKI_MAX_LOG_LIMIT", MaxLogLimit)
+
 // client wraps an HTTP client for making Loki API requests through Grafana datasource proxy.
 type client struct {
 	httpClient *http.Client
 	baseURL    string
+	headers    http.Header // Extra headers sent with every request, e.g., X-Scope-OrgID
+
+	datasourceUID string // Resolved UID, after falling back to the default datasource
 }
 
 // newClient creates a Loki client for the specified datasource UID.
-func newClient(datasourceUID string) (*client, error) {
+func newClient(ctx context.Context, datasourceUID string) (*client, error) {
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, "loki")
+	if err != nil {
+		return nil, err
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return nil, err
+	}
+
 	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
 	if err != nil {
 		return nil, err
@@ -39,62 +60,51 @@ func newClient(datasourceUID string) (*client, error) {
 	baseURL := fmt.Sprintf("%s/api/datasources/proxy/uid/%s", grafanaURL, datasourceUID)
 
 	return &client{
-		httpClient: httpClient,
-		baseURL:    baseURL,
+		httpClient:    httpClient,
+		baseURL:       baseURL,
+		datasourceUID: datasourceUID,
 	}, nil
 }
 
-// buildURL constructs a full URL for a Loki API endpoint.
+// buildURL constructs a full URL for a Loki API endpoint, joining the base and path with exactly one slash
+// so a Grafana subpath (e.g., https://host/grafana/api/datasources/proxy/uid/x) is preserved.
 func (c *client) buildURL(path string) string {
-	if !strings.HasSuffix(c.baseURL, "/") && !strings.HasPrefix(path, "/") {
-		return c.baseURL + "/" + path
-	} else if strings.HasSuffix(c.baseURL, "/") && strings.HasPrefix(path, "/") {
-		return c.baseURL + strings.TrimPrefix(path, "/")
-	}
-	return c.baseURL + path
+	return strings.TrimRight(c.baseURL, "/") + "/" + strings.TrimLeft(path, "/")
 }
 
 // makeRequest executes an HTTP request to the Loki API and returns the response body.
 func (c *client) makeRequest(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
-	fullURL := c.buildURL(path)
-
-	u, err := url.Parse(fullURL)
+	statusCode, bodyBytes, err := c.doRequest(ctx, method, path, params)
 	if err != nil {
-		return nil, fmt.Errorf("parsing URL: %w", err)
-	}
-
-	if params != nil {
-		u.RawQuery = params.Encode()
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("loki API", statusCode, bodyBytes)
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+	if len(bodyBytes) == 0 {
+		return nil, fmt.Errorf("empty response from Loki API")
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("loki API returned status code %d: %s", resp.StatusCode, string(bodyBytes))
-	}
+	return bodyBytes, nil
+}
 
-	// Read response body with 48MB limit to prevent memory issues
-	limitedReader := io.LimitReader(resp.Body, 1024*1024*48)
-	bodyBytes, err := io.ReadAll(limitedReader)
+// doRequest executes an HTTP request to the Loki API and returns the status code and trimmed
+// body without treating non-200 responses as errors, for callers that interpret the status themselves.
+func (c *client) doRequest(ctx context.Context, method, path string, params url.Values) (int, []byte, error) {
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method:   method,
+		URL:      c.buildURL(path),
+		Params:   params,
+		Header:   c.headers,
+		MaxBytes: grafana.MaxResponseBytes,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
-	}
-
-	if len(bodyBytes) == 0 {
-		return nil, fmt.Errorf("empty response from Loki API")
+		return 0, nil, err
 	}
 
-	return bytes.TrimSpace(bodyBytes), nil
+	return statusCode, bytes.TrimSpace(bodyBytes), nil
 }
 
 // labelResponse represents the JSON response from Loki label endpoints.
@@ -134,11 +144,43 @@ func (c *client) fetchLabels(ctx context.Context, path, startRFC3339, endRFC3339
 	return response.Data, nil
 }
 
+// seriesResponse represents the JSON response from Loki's series endpoint.
+type seriesResponse struct {
+	Status string              `json:"status"`
+	Data   []map[string]string `json:"data,omitempty"`
+}
+
+// fetchSeries returns the label sets of all streams matching the selector in the time range.
+func (c *client) fetchSeries(ctx context.Context, selector, startRFC3339, endRFC3339 string) ([]map[string]string, error) {
+	params := url.Values{}
+	params.Add("match[]", selector)
+
+	if err := addTimeRangeParams(params, startRFC3339, endRFC3339); err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", "/loki/api/v1/series", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response seriesResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return nil, fmt.Errorf("unmarshalling series response: %w", err)
+	}
+
+	if response.Status != "success" {
+		return nil, fmt.Errorf("loki API returned unexpected status: %s", response.Status)
+	}
+
+	return response.Data, nil
+}
+
 // getDefaultTimeRange returns default start and end times if not provided.
-// Default range is the last 1 hour.
+// Default range is the last defaultWindow (1 hour unless overridden).
 func getDefaultTimeRange(startRFC3339, endRFC3339 string) (string, string) {
 	if startRFC3339 == "" {
-		startRFC3339 = time.Now().Add(-1 * time.Hour).Format(time.RFC3339)
+		startRFC3339 = time.Now().Add(-defaultWindow).Format(time.RFC3339)
 	}
 	if endRFC3339 == "" {
 		endRFC3339 = time.Now().Format(time.RFC3339)
@@ -173,8 +215,19 @@ func enforceLogLimit(requestedLimit int) int {
 	if requestedLimit <= 0 {
 		return DefaultLogLimit
 	}
-	if requestedLimit > MaxLogLimit {
-		return MaxLogLimit
+	if requestedLimit > maxLogLimit {
+		return maxLogLimit
 	}
 	return requestedLimit
 }
+
+// enforceBoundedLimit applies a default when the limit is unset and caps it at max.
+func enforceBoundedLimit(requested, defaultLimit, maxLimit int) int {
+	if requested <= 0 {
+		return defaultLimit
+	}
+	if requested > maxLimit {
+		return maxLimit
+	}
+	return requested
+}
@@ -0,0 +1,116 @@
+package loki
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// DedupeNone disables deduplication.
+	DedupeNone = "none"
+
+	// DedupeConsecutive collapses runs of identical adjacent entries.
+	DedupeConsecutive = "consecutive"
+
+	// DedupeAll collapses identical entries anywhere in the result.
+	DedupeAll = "all"
+)
+
+// validateDedupeMode checks a dedupe parameter value.
+func validateDedupeMode(mode string) error {
+	switch mode {
+	case "", DedupeNone, DedupeConsecutive, DedupeAll:
+		return nil
+	default:
+		return fmt.Errorf("invalid dedupe: %s (must be 'none', 'consecutive', or 'all')", mode)
+	}
+}
+
+// dedupeKey identifies an entry for deduplication, preferring extracted fields over the raw line.
+func dedupeKey(entry LogEntry) string {
+	if len(entry.Fields) > 0 {
+		// json.Marshal sorts map keys, so equal field sets produce equal keys
+		if b, err := json.Marshal(entry.Fields); err == nil {
+			return string(b)
+		}
+	}
+	return entry.Line
+}
+
+// dedupeEntries collapses identical log entries into one entry carrying a count and
+// the first/last timestamps (in nanoseconds) of the collapsed occurrences.
+// Metric samples are passed through untouched.
+func dedupeEntries(entries []LogEntry, mode string) []LogEntry {
+	if mode == "" || mode == DedupeNone {
+		return entries
+	}
+
+	result := make([]LogEntry, 0, len(entries))
+	index := make(map[string]int) // key -> position in result (mode "all")
+	lastKey := ""
+	lastIdx := -1
+
+	for _, entry := range entries {
+		if entry.Value != nil {
+			result = append(result, entry)
+			lastIdx = -1
+			continue
+		}
+
+		key := dedupeKey(entry)
+
+		existing := -1
+		switch mode {
+		case DedupeConsecutive:
+			if lastIdx >= 0 && key == lastKey {
+				existing = lastIdx
+			}
+		case DedupeAll:
+			if i, ok := index[key]; ok {
+				existing = i
+			}
+		}
+
+		if existing >= 0 {
+			mergeDuplicate(&result[existing], entry.Timestamp)
+			continue
+		}
+
+		entry.Count = 1
+		entry.FirstTimestamp = entry.Timestamp
+		entry.LastTimestamp = entry.Timestamp
+		result = append(result, entry)
+
+		lastKey = key
+		lastIdx = len(result) - 1
+		index[key] = lastIdx
+	}
+
+	// Only annotate entries that actually collapsed something
+	for i := range result {
+		if result[i].Count == 1 {
+			result[i].Count = 0
+			result[i].FirstTimestamp = ""
+			result[i].LastTimestamp = ""
+		}
+	}
+
+	return result
+}
+
+// mergeDuplicate folds another occurrence into an already-collapsed entry.
+func mergeDuplicate(target *LogEntry, timestamp string) {
+	target.Count++
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return
+	}
+	if first, err := strconv.ParseInt(target.FirstTimestamp, 10, 64); err == nil && ts < first {
+		target.FirstTimestamp = timestamp
+	}
+	if last, err := strconv.ParseInt(target.LastTimestamp, 10, 64); err == nil && ts > last {
+		target.LastTimestamp = timestamp
+	}
+}
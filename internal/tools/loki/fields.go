@@ -0,0 +1,123 @@
+package loki
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// parseLogLine decodes a log line as a JSON object, falling back to logfmt.
+// Returns nil if the line is neither.
+func parseLogLine(line string) map[string]any {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(trimmed), &obj); err == nil {
+			return obj
+		}
+	}
+
+	return parseLogfmt(trimmed)
+}
+
+// parseLogfmt parses a logfmt line (key=value pairs, values optionally double-quoted).
+// Returns nil if no key=value pairs are found.
+func parseLogfmt(line string) map[string]any {
+	fields := make(map[string]any)
+	i := 0
+	for i < len(line) {
+		// Skip whitespace between pairs
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+
+		keyStart := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		key := line[keyStart:i]
+
+		if i >= len(line) || line[i] != '=' {
+			continue // Bare word, not a key=value pair
+		}
+		i++ // Skip '='
+
+		var value string
+		if i < len(line) && line[i] == '"' {
+			i++
+			var sb strings.Builder
+			for i < len(line) && line[i] != '"' {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				sb.WriteByte(line[i])
+				i++
+			}
+			i++ // Skip closing quote
+			value = sb.String()
+		} else {
+			valueStart := i
+			for i < len(line) && line[i] != ' ' {
+				i++
+			}
+			value = line[valueStart:i]
+		}
+
+		if key != "" {
+			fields[key] = value
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// lookupField resolves a field name against parsed line fields.
+// Dotted names (e.g., "http.status") descend into nested JSON objects when no flat key matches.
+func lookupField(fields map[string]any, name string) (any, bool) {
+	if v, ok := fields[name]; ok {
+		return v, true
+	}
+
+	parts := strings.Split(name, ".")
+	var current any = fields
+	for _, part := range parts {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// extractFields promotes the named fields from each parsed log line into entry.Fields.
+// When dropLine is set, the raw line is removed from entries that yielded at least one field.
+func extractFields(entries []LogEntry, names []string, dropLine bool) {
+	for i := range entries {
+		parsed := parseLogLine(entries[i].Line)
+		if parsed == nil {
+			continue
+		}
+
+		fields := make(map[string]any)
+		for _, name := range names {
+			if v, ok := lookupField(parsed, name); ok {
+				fields[name] = v
+			}
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		entries[i].Fields = fields
+		if dropLine {
+			entries[i].Line = ""
+		}
+	}
+}
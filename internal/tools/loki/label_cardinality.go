@@ -0,0 +1,122 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// cardinalitySampleValues is the number of example values listed per label by loki_label_cardinality.
+const cardinalitySampleValues = 5
+
+// LabelCardinality is the number of distinct values a label takes within the matched streams.
+type LabelCardinality struct {
+	Label        string   `json:"label"`
+	Cardinality  int      `json:"cardinality"`
+	SampleValues []string `json:"sampleValues"`
+}
+
+// LabelCardinalityResult represents the output of loki_label_cardinality.
+type LabelCardinalityResult struct {
+	Selector    string             `json:"selector"`
+	StreamCount int                `json:"streamCount"`
+	Labels      []LabelCardinality `json:"labels"`
+}
+
+type labelCardinalityParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Selector      string `json:"selector"`
+	StartRFC3339  string `json:"startRfc3339,omitempty"`
+	EndRFC3339    string `json:"endRfc3339,omitempty"`
+}
+
+func labelCardinalityHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params labelCardinalityParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Selector == "" {
+		return mcp.NewToolResultError("selector is required"), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
+	}
+
+	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	series, err := c.fetchSeries(ctx, params.Selector, startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := &LabelCardinalityResult{
+		Selector:    params.Selector,
+		StreamCount: len(series),
+		Labels:      []LabelCardinality{},
+	}
+
+	for label, values := range distinctLabelValues(series) {
+		samples := values
+		if len(samples) > cardinalitySampleValues {
+			samples = samples[:cardinalitySampleValues]
+		}
+		result.Labels = append(result.Labels, LabelCardinality{
+			Label:        label,
+			Cardinality:  len(values),
+			SampleValues: samples,
+		})
+	}
+
+	// Worst offenders first
+	sort.Slice(result.Labels, func(i, j int) bool {
+		if result.Labels[i].Cardinality != result.Labels[j].Cardinality {
+			return result.Labels[i].Cardinality > result.Labels[j].Cardinality
+		}
+		return result.Labels[i].Label < result.Labels[j].Label
+	})
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(result, notice))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newLabelCardinalityTool() mcp.Tool {
+	return mcp.NewTool(
+		"loki_label_cardinality",
+		mcp.WithDescription("Reports label cardinality for the Loki streams matching a selector: "+
+			"for each label, the number of distinct values it takes across those streams, with a few sample values. "+
+			"Labels are sorted by cardinality, highest first, to surface the high-cardinality labels that hurt Loki performance. "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+"."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+		mcp.WithString("selector",
+			mcp.Description("LogQL stream selector choosing the streams to analyze (e.g., '{namespace=\"prod\"}')"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+	)
+}
+
+// RegisterLabelCardinality registers the loki_label_cardinality tool.
+func RegisterLabelCardinality(s grafana.ToolRegistry) {
+	s.AddTool(newLabelCardinalityTool(), labelCardinalityHandler)
+}
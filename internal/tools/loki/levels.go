@@ -0,0 +1,78 @@
+package loki
+
+import (
+	"fmt"
+	"strings"
+)
+
+// levelRanks orders log severities; aliases map onto the canonical debug < info < warn < error scale.
+var levelRanks = map[string]int{
+	"trace":    0,
+	"debug":    0,
+	"dbug":     0,
+	"info":     1,
+	"notice":   1,
+	"warn":     2,
+	"warning":  2,
+	"error":    3,
+	"err":      3,
+	"eror":     3,
+	"critical": 4,
+	"crit":     4,
+	"fatal":    4,
+	"panic":    4,
+	"alert":    4,
+	"emerg":    4,
+}
+
+// levelKeys lists the label and field names checked, in order, to detect an entry's level.
+var levelKeys = []string{"level", "detected_level", "severity", "lvl", "log.level", "severity_text"}
+
+// parseMinLevel validates a minLevel parameter and returns its rank.
+func parseMinLevel(minLevel string) (int, error) {
+	rank, ok := levelRanks[strings.ToLower(minLevel)]
+	if !ok {
+		return 0, fmt.Errorf("invalid minLevel %q (must be 'debug', 'info', 'warn', or 'error')", minLevel)
+	}
+	return rank, nil
+}
+
+// detectLevel returns the severity rank of an entry from its labels (including structured metadata)
+// or, failing that, from a level field in the parsed JSON/logfmt line.
+func detectLevel(entry LogEntry) (int, bool) {
+	for _, key := range levelKeys {
+		if v, ok := entry.Labels[key]; ok {
+			if rank, ok := levelRanks[strings.ToLower(v)]; ok {
+				return rank, true
+			}
+		}
+	}
+
+	parsed := parseLogLine(entry.Line)
+	if parsed == nil {
+		return 0, false
+	}
+	for _, key := range levelKeys {
+		if v, ok := lookupField(parsed, key); ok {
+			if s, ok := v.(string); ok {
+				if rank, ok := levelRanks[strings.ToLower(s)]; ok {
+					return rank, true
+				}
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// filterByLevel keeps only entries whose detected level is at or above minRank.
+// Entries without a detectable level are dropped.
+func filterByLevel(entries []LogEntry, minRank int) []LogEntry {
+	filtered := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if rank, ok := detectLevel(entry); ok && rank >= minRank {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,37 @@
+package loki
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+// isMetricQuery reports whether logql is a metric query, e.g., rate({app="api"}[5m]), rather than a log selector.
+func isMetricQuery(logql string) bool {
+	trimmed := strings.TrimSpace(logql)
+	return !strings.HasPrefix(trimmed, "{") && metricFunctionPattern.MatchString(trimmed)
+}
+
+// addLineFilters appends a |= filter for each contains string and a != filter for each notContains
+// string to a log query, quoting the strings so quotes and backslashes in them cannot break the query.
+func addLineFilters(logql string, contains, notContains []string) (string, error) {
+	if len(contains) == 0 && len(notContains) == 0 {
+		return logql, nil
+	}
+
+	trimmed := strings.TrimSpace(logql)
+	if !strings.HasPrefix(trimmed, "{") || isMetricQuery(trimmed) {
+		return "", fmt.Errorf("contains and notContains need a log query starting with a stream selector, e.g., '{app=\"api\"}', not a metric query")
+	}
+
+	var b strings.Builder
+	b.WriteString(trimmed)
+	for _, s := range contains {
+		fmt.Fprintf(&b, " |= %s", grafana.QuoteLabelValue(s))
+	}
+	for _, s := range notContains {
+		fmt.Fprintf(&b, " != %s", grafana.QuoteLabelValue(s))
+	}
+	return b.String(), nil
+}
@@ -2,11 +2,10 @@ package loki
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listLabelNamesParams struct {
@@ -21,12 +20,16 @@ func listLabelNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
 	}
 
 	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	labels, err := c.fetchLabels(ctx, "/loki/api/v1/labels", startTime, endTime)
 	if err != nil {
@@ -37,7 +40,7 @@ func listLabelNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 		labels = []string{}
 	}
 
-	jsonData, err := json.MarshalIndent(labels, "", "  ")
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(labels, notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -48,13 +51,12 @@ func listLabelNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 func newListLabelNamesTool() mcp.Tool {
 	return mcp.NewTool(
 		"list_loki_label_names",
-		mcp.WithDescription("Lists all available label names (keys) found in logs within a Loki datasource and time range. Returns a list of unique label strings (e.g., [\"app\", \"env\", \"pod\"]). Defaults to the last hour if time range is not specified."),
+		mcp.WithDescription("Lists all available label names (keys) found in logs within a Loki datasource and time range. Returns a list of unique label strings (e.g., [\"app\", \"env\", \"pod\"]). Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Loki datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
 		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
@@ -63,6 +65,6 @@ func newListLabelNamesTool() mcp.Tool {
 }
 
 // RegisterListLabelNames registers the list_loki_label_names tool with the MCP server.
-func RegisterListLabelNames(s *server.MCPServer) {
+func RegisterListLabelNames(s grafana.ToolRegistry) {
 	s.AddTool(newListLabelNamesTool(), listLabelNamesHandler)
 }
@@ -2,11 +2,10 @@ package loki
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listLabelValuesParams struct {
@@ -14,6 +13,7 @@ type listLabelValuesParams struct {
 	LabelName     string `json:"labelName"`
 	StartRFC3339  string `json:"startRfc3339,omitempty"`
 	EndRFC3339    string `json:"endRfc3339,omitempty"`
+	Regex         string `json:"regex,omitempty"`
 }
 
 func listLabelValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -22,12 +22,16 @@ func listLabelValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
 	}
 
 	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	path := fmt.Sprintf("/loki/api/v1/label/%s/values", params.LabelName)
 	values, err := c.fetchLabels(ctx, path, startTime, endTime)
@@ -39,7 +43,16 @@ func listLabelValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*
 		values = []string{}
 	}
 
-	jsonData, err := json.MarshalIndent(values, "", "  ")
+	var output any = values
+	if params.Regex != "" {
+		filtered, err := grafana.FilterByRegex(values, params.Regex)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		output = grafana.FilteredValues{TotalCount: len(values), MatchedCount: len(filtered), Values: filtered}
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(output, notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -50,17 +63,19 @@ func listLabelValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*
 func newListLabelValuesTool() mcp.Tool {
 	return mcp.NewTool(
 		"list_loki_label_values",
-		mcp.WithDescription("Retrieves all unique values for a specific label name within a Loki datasource and time range. Returns a list of string values (e.g., for labelName=\"env\", might return [\"prod\", \"staging\", \"dev\"]). Useful for discovering filter options. Defaults to the last hour if time range is omitted."),
+		mcp.WithDescription("Retrieves all unique values for a specific label name within a Loki datasource and time range. Returns a list of string values (e.g., for labelName=\"env\", might return [\"prod\", \"staging\", \"dev\"]). Useful for discovering filter options. With regex, returns {totalCount, matchedCount, values} where totalCount is the count before filtering. Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is omitted."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Loki datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
 		),
 		mcp.WithString("labelName",
 			mcp.Description("The name of the label to retrieve values for (e.g., 'app', 'env', 'pod')"),
 			mcp.Required(),
 		),
+		mcp.WithString("regex",
+			mcp.Description("Optional regex to filter the values client-side (e.g., 'payments-.*')"),
+		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
@@ -69,6 +84,6 @@ func newListLabelValuesTool() mcp.Tool {
 }
 
 // RegisterListLabelValues registers the list_loki_label_values tool with the MCP server.
-func RegisterListLabelValues(s *server.MCPServer) {
+func RegisterListLabelValues(s grafana.ToolRegistry) {
 	s.AddTool(newListLabelValuesTool(), listLabelValuesHandler)
 }
@@ -0,0 +1,194 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultContextLines is the default number of lines fetched on each side of the anchor.
+	DefaultContextLines = 10
+
+	// MaxContextLines is the maximum number of lines fetched on each side of the anchor.
+	MaxContextLines = 100
+
+	// contextWindow bounds how far before and after the anchor each context query searches.
+	contextWindow = time.Hour
+)
+
+// ContextLine is a log line around an anchor; Anchor marks lines at the anchor timestamp itself.
+type ContextLine struct {
+	Timestamp string            `json:"timestamp"`
+	Line      string            `json:"line"`
+	Labels    map[string]string `json:"labels"`
+	Anchor    bool              `json:"anchor,omitempty"`
+}
+
+// LogContext is the output of get_loki_log_context, with lines in chronological order.
+type LogContext struct {
+	AnchorTimestamp string        `json:"anchorTimestamp"` // Unix nanoseconds
+	Before          int           `json:"before"`
+	After           int           `json:"after"`
+	Lines           []ContextLine `json:"lines"`
+}
+
+type logContextParams struct {
+	DatasourceUID string            `json:"datasourceUid"`
+	LogQL         string            `json:"logql"`
+	Timestamp     string            `json:"timestamp"`
+	Lines         int               `json:"lines,omitempty"`
+	TenantID      string            `json:"tenantId,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// parseAnchorTimestamp accepts Unix nanoseconds, as query_loki_logs returns, or an RFC3339 time.
+func parseAnchorTimestamp(s string) (time.Time, error) {
+	if ns, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, ns).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q (expected Unix nanoseconds or RFC3339)", s)
+	}
+	return t.UTC(), nil
+}
+
+// contextLines flattens log streams into lines.
+func contextLines(streams []logStream) []ContextLine {
+	var lines []ContextLine
+	for _, stream := range streams {
+		for _, value := range stream.Values {
+			if len(value) < 2 {
+				continue
+			}
+			var line string
+			if err := json.Unmarshal(value[1], &line); err != nil {
+				continue // Skip invalid lines
+			}
+			lines = append(lines, ContextLine{
+				Timestamp: strings.Trim(string(value[0]), "\""),
+				Line:      line,
+				Labels:    stream.Stream,
+			})
+		}
+	}
+	return lines
+}
+
+// sortLines orders lines oldest first by their Unix nanosecond timestamps.
+func sortLines(lines []ContextLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, _ := strconv.ParseInt(lines[i].Timestamp, 10, 64)
+		b, _ := strconv.ParseInt(lines[j].Timestamp, 10, 64)
+		return a < b
+	})
+}
+
+func logContextHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params logContextParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	logql := strings.TrimSpace(params.LogQL)
+	if !strings.HasPrefix(logql, "{") {
+		return mcp.NewToolResultError("logql must be a log query starting with a stream selector, e.g., '{app=\"api\"}'"), nil
+	}
+
+	anchor, err := parseAnchorTimestamp(params.Timestamp)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	n := enforceBoundedLimit(params.Lines, DefaultContextLines, MaxContextLines)
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
+	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	anchorRFC3339 := anchor.Format(time.RFC3339Nano)
+
+	// Loki's end is exclusive, so the backward query stops just short of the anchor and the
+	// forward query starts at it; one extra forward line leaves room for the anchor itself.
+	beforeStreams, err := c.fetchLogs(ctx, logql, anchor.Add(-contextWindow).Format(time.RFC3339Nano), anchorRFC3339, n, "backward", 0)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("fetching lines before the anchor: %v", err)), nil
+	}
+	afterStreams, err := c.fetchLogs(ctx, logql, anchorRFC3339, anchor.Add(contextWindow).Format(time.RFC3339Nano), n+1, "forward", 0)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("fetching lines after the anchor: %v", err)), nil
+	}
+
+	before := contextLines(beforeStreams)
+	after := contextLines(afterStreams)
+	sortLines(before)
+	sortLines(after)
+
+	anchorNanos := strconv.FormatInt(anchor.UnixNano(), 10)
+	result := LogContext{AnchorTimestamp: anchorNanos, Before: len(before), Lines: append([]ContextLine{}, before...)}
+	for _, line := range after {
+		if line.Timestamp == anchorNanos {
+			line.Anchor = true
+		} else if result.After == n {
+			break // The extra line was not needed for the anchor
+		} else {
+			result.After++
+		}
+		result.Lines = append(result.Lines, line)
+	}
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newLogContextTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_loki_log_context",
+		mcp.WithDescription("Gets the log lines surrounding a line of interest, like 'show context' in Grafana Explore. "+
+			"Given a stream selector and the line's timestamp, fetches up to N lines before and N lines after it "+
+			fmt.Sprintf("(each within %s of the anchor) and returns them oldest first, with lines at the anchor timestamp marked anchor: true. ", contextWindow)+
+			"Pass the timestamp exactly as query_loki_logs returned it (Unix nanoseconds) and the line's labels as the selector "+
+			"(e.g., '{app=\"api\", pod=\"api-7d9f\"}') to read the same stream around the event."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+		mcp.WithString("logql",
+			mcp.Description("Log query selecting the stream(s) to read, usually just a stream selector (e.g., '{app=\"api\", pod=\"api-7d9f\"}')"),
+			mcp.Required(),
+		),
+		mcp.WithString("timestamp",
+			mcp.Description("Timestamp of the anchor line, as Unix nanoseconds (e.g., '1700000000123456789') or RFC3339"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("lines",
+			mcp.Description(fmt.Sprintf("Number of lines to fetch on each side of the anchor (default: %d, max: %d)", DefaultContextLines, MaxContextLines)),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+	)
+}
+
+// RegisterLogContext registers the get_loki_log_context tool.
+func RegisterLogContext(s grafana.ToolRegistry) {
+	s.AddTool(newLogContextTool(), logContextHandler)
+}
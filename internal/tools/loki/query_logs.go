@@ -7,15 +7,21 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
-// logStream represents a stream of log entries from Loki.
+// logStream represents a stream of log entries from Loki. Metric queries return matrix or
+// vector series keyed by metric instead; fetchLogs normalizes those into Stream and Values.
 type logStream struct {
 	Stream map[string]string   `json:"stream"`
 	Values [][]json.RawMessage `json:"values"` // [timestamp, value]
+	Metric map[string]string   `json:"metric"`
+	Value  []json.RawMessage   `json:"value"` // Vector results carry a single [timestamp, value]
+
+	isMetric bool // Values hold [unixSeconds, "number"] samples rather than [unixNanos, "line"]
 }
 
 // queryRangeResponse represents the response from Loki's query_range API.
@@ -33,18 +39,36 @@ type LogEntry struct {
 	Line      string            `json:"line,omitempty"`  // For log queries
 	Value     *float64          `json:"value,omitempty"` // For metric queries
 	Labels    map[string]string `json:"labels"`
+	Fields    map[string]any    `json:"fields,omitempty"` // Extracted from JSON/logfmt lines
+
+	// Populated when dedupe collapses repeated entries
+	Count          int    `json:"count,omitempty"`
+	FirstTimestamp string `json:"firstTimestamp,omitempty"`
+	LastTimestamp  string `json:"lastTimestamp,omitempty"`
 }
 
 type queryLogsParams struct {
-	DatasourceUID string `json:"datasourceUid"`
-	LogQL         string `json:"logql"`
-	StartRFC3339  string `json:"startRfc3339,omitempty"`
-	EndRFC3339    string `json:"endRfc3339,omitempty"`
-	Limit         int    `json:"limit,omitempty"`
-	Direction     string `json:"direction,omitempty"`
+	DatasourceUID  string            `json:"datasourceUid"`
+	LogQL          string            `json:"logql"`
+	Contains       []string          `json:"contains,omitempty"`
+	StepSeconds    int               `json:"stepSeconds,omitempty"`
+	NotContains    []string          `json:"notContains,omitempty"`
+	StartRFC3339   string            `json:"startRfc3339,omitempty"`
+	EndRFC3339     string            `json:"endRfc3339,omitempty"`
+	Limit          int               `json:"limit,omitempty"`
+	Direction      string            `json:"direction,omitempty"`
+	ExtractFields  []string          `json:"extractFields,omitempty"`
+	DropLine       bool              `json:"dropLine,omitempty"`
+	MinLevel       string            `json:"minLevel,omitempty"`
+	Dedupe         string            `json:"dedupe,omitempty"`
+	TimeoutSeconds int               `json:"timeoutSeconds,omitempty"`
+	PrecheckHealth *bool             `json:"precheckHealth,omitempty"`
+	DryRun         bool              `json:"dryRun,omitempty"`
+	TenantID       string            `json:"tenantId,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
 }
 
-func (c *client) fetchLogs(ctx context.Context, query, startRFC3339, endRFC3339 string, limit int, direction string) ([]logStream, error) {
+func (c *client) fetchLogs(ctx context.Context, query, startRFC3339, endRFC3339 string, limit int, direction string, stepSeconds int) ([]logStream, error) {
 	params := url.Values{}
 	params.Add("query", query)
 
@@ -60,11 +84,21 @@ func (c *client) fetchLogs(ctx context.Context, query, startRFC3339, endRFC3339
 		params.Add("direction", direction)
 	}
 
+	if stepSeconds > 0 {
+		params.Add("step", strconv.Itoa(stepSeconds))
+	}
+
 	bodyBytes, err := c.makeRequest(ctx, "GET", "/loki/api/v1/query_range", params)
 	if err != nil {
 		return nil, err
 	}
 
+	return decodeQueryRange(bodyBytes)
+}
+
+// decodeQueryRange parses a query_range response body into streams. Metric query results
+// (matrix or vector) are normalized into streams of [unixSeconds, "number"] samples.
+func decodeQueryRange(bodyBytes []byte) ([]logStream, error) {
 	var response queryRangeResponse
 	if err := json.Unmarshal(bodyBytes, &response); err != nil {
 		return nil, fmt.Errorf("unmarshalling query response: %w", err)
@@ -74,21 +108,88 @@ func (c *client) fetchLogs(ctx context.Context, query, startRFC3339, endRFC3339
 		return nil, fmt.Errorf("loki API returned unexpected status: %s", response.Status)
 	}
 
+	// The result type, not any stream label, says whether this was a log or a metric query
+	switch response.Data.ResultType {
+	case "matrix", "vector":
+		for i := range response.Data.Result {
+			stream := &response.Data.Result[i]
+			stream.isMetric = true
+			stream.Stream = stream.Metric
+			if response.Data.ResultType == "vector" && len(stream.Value) == 2 {
+				stream.Values = [][]json.RawMessage{stream.Value}
+			}
+		}
+	case "streams", "":
+	default:
+		return nil, fmt.Errorf("loki API returned unsupported result type: %s", response.Data.ResultType)
+	}
+
 	return response.Data.Result, nil
 }
 
+// metricTimestamp converts a metric sample's Unix-seconds timestamp, such as 1700000000.5,
+// to the Unix-nanosecond string log entries use.
+func metricTimestamp(raw json.RawMessage) (string, bool) {
+	var ts float64
+	if err := json.Unmarshal(raw, &ts); err != nil {
+		return "", false
+	}
+	sec := int64(ts)
+	nsec := int64((ts - float64(sec)) * float64(time.Second))
+	return strconv.FormatInt(time.Unix(sec, nsec).UnixNano(), 10), true
+}
+
 func queryLogsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params queryLogsParams
 	if err := request.BindArguments(&params); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	minLevelRank := 0
+	if params.MinLevel != "" {
+		rank, err := parseMinLevel(params.MinLevel)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		minLevelRank = rank
+	}
+
+	if err := validateDedupeMode(params.Dedupe); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if params.StepSeconds < 0 {
+		return mcp.NewToolResultError("stepSeconds must be positive"), nil
+	}
+
+	logql, err := addLineFilters(params.LogQL, params.Contains, params.NotContains)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
 	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ctx, cancel := grafana.WithRequestTimeout(ctx, c.httpClient, params.TimeoutSeconds)
+	defer cancel()
+	if params.DryRun {
+		ctx = grafana.WithDryRun(ctx)
+	}
+
+	if err := grafana.PrecheckHealth(ctx, c.httpClient, c.datasourceUID, params.PrecheckHealth); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	limit := enforceLogLimit(params.Limit)
 
 	direction := params.Direction
@@ -96,16 +197,55 @@ func queryLogsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		direction = "backward" // Newest first by default
 	}
 
-	streams, err := c.fetchLogs(ctx, params.LogQL, startTime, endTime, limit, direction)
+	// Step only sets the resolution of metric queries; Loki has no use for it on log selectors
+	stepSeconds := 0
+	if isMetricQuery(logql) {
+		stepSeconds = params.StepSeconds
+	}
+
+	streams, err := c.fetchLogs(ctx, logql, startTime, endTime, limit, direction, stepSeconds)
+	if params.DryRun {
+		return grafana.DryRunResult(ctx), nil
+	}
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	if len(streams) == 0 {
-		return mcp.NewToolResultText("[]"), nil
+		return logEntriesResult([]LogEntry{}, 0, notice)
+	}
+
+	entries := streamEntries(streams)
+
+	// Loki caps the total entries across streams, so a full page means the window holds more.
+	// For metric queries the limit caps series instead, so a full page says nothing.
+	limitReached := 0
+	if !streams[0].isMetric && len(entries) >= limit {
+		limitReached = limit
+	}
+
+	// Level detection reads the raw line, so filter before fields are extracted and lines dropped
+	if params.MinLevel != "" {
+		entries = filterByLevel(entries, minLevelRank)
+	}
+
+	if len(entries) == 0 {
+		return logEntriesResult([]LogEntry{}, limitReached, notice)
+	}
+
+	if len(params.ExtractFields) > 0 {
+		extractFields(entries, params.ExtractFields, params.DropLine)
 	}
 
-	// Convert streams to flat list of log entries
+	// Dedupe after extraction so it can key on extracted fields instead of the raw line
+	entries = dedupeEntries(entries, params.Dedupe)
+
+	return logEntriesResult(entries, limitReached, notice)
+}
+
+// streamEntries flattens streams into log entries: log lines for log queries, and numeric values
+// with Unix-nanosecond timestamps for metric queries. Malformed samples are skipped.
+func streamEntries(streams []logStream) []LogEntry {
 	var entries []LogEntry
 	for _, stream := range streams {
 		for _, value := range stream.Values {
@@ -119,7 +259,13 @@ func queryLogsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 			}
 
 			// Handle metric queries (numeric values) vs log queries (strings)
-			if stream.Stream["__type__"] == "metrics" {
+			if stream.isMetric {
+				ts, ok := metricTimestamp(value[0])
+				if !ok {
+					continue // Skip invalid timestamps
+				}
+				entry.Timestamp = ts
+
 				// Try parsing as numeric value
 				var numStr string
 				if err := json.Unmarshal(value[1], &numStr); err == nil {
@@ -150,11 +296,23 @@ func queryLogsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		}
 	}
 
-	if len(entries) == 0 {
-		return mcp.NewToolResultText("[]"), nil
+	return entries
+}
+
+// logEntriesResult marshals log entries as the tool result. A non-zero limitReached is the limit
+// Loki's response filled: the entries are returned as a grafana.TruncatedList with a warning on how
+// to page past them. A non-empty notice is reported in the result's warnings too.
+func logEntriesResult(entries []LogEntry, limitReached int, notice string) (*mcp.CallToolResult, error) {
+	var result any = entries
+	warnings := []string{notice}
+	if limitReached > 0 {
+		// Loki applied the limit, so how many entries it dropped is unknown
+		result = grafana.TruncatedList[LogEntry]{Values: entries, Truncated: true}
+		warnings = append(warnings, fmt.Sprintf("Loki returned the maximum of %d entries, so more logs likely exist in the time range. "+
+			"Narrow the time range or query, or page by moving endRfc3339 (backward) or startRfc3339 (forward) past the last entry returned.", limitReached))
 	}
 
-	jsonData, err := json.MarshalIndent(entries, "", "  ")
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(grafana.WrapResult(result, len(entries)), warnings...))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -162,34 +320,84 @@ func queryLogsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
+// HandleQueryLogs runs the query_loki_logs tool handler; the unified query tool routes LogQL here.
+func HandleQueryLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return queryLogsHandler(ctx, request)
+}
+
 func newQueryLogsTool() mcp.Tool {
 	return mcp.NewTool(
 		"query_loki_logs",
-		mcp.WithDescription("Executes a LogQL query against a Loki datasource to retrieve log entries. Supports full LogQL syntax including label matchers, filters, and pipeline operations (e.g., '{app=\"nginx\"} |= \"error\"'). Returns a list of log entries with timestamp, labels, and log line. Defaults to last hour, 10 entries, newest first. Consider using query_loki_stats first to check query size."),
+		mcp.WithDescription("Executes a LogQL query against a Loki datasource to retrieve log entries. Supports full LogQL syntax including label matchers, filters, and pipeline operations (e.g., '{app=\"nginx\"} |= \"error\"'). Returns a list of log entries with timestamp, labels, and log line; when as many entries as the limit come back, the result is {values, _truncated: true} with a paging hint alongside it in warnings, since more logs likely exist. Defaults to the last "+grafana.FormatDuration(defaultWindow)+", 10 entries, newest first. For metric queries (rate, count_over_time, etc.) use query_loki_metric. Consider using query_loki_stats first to check query size."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Loki datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
 		),
 		mcp.WithString("logql",
 			mcp.Description("LogQL query expression (e.g., '{app=\"nginx\"} |= \"error\"')"),
 			mcp.Required(),
 		),
+		mcp.WithArray("contains",
+			mcp.Description("Only return lines containing every one of these strings, appended as |= \"...\" line filters with quoting handled for you (e.g., [\"timeout\"])"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("notContains",
+			mcp.Description("Drop lines containing any of these strings, appended as != \"...\" line filters (e.g., [\"healthcheck\"])"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
 		),
 		mcp.WithNumber("limit",
-			mcp.Description("Maximum number of log lines to return (default: 10, max: 100)"),
+			mcp.Description(fmt.Sprintf("Maximum number of log lines to return (default: %d, max: %d)", DefaultLogLimit, maxLogLimit)),
 		),
 		mcp.WithString("direction",
 			mcp.Description("Query direction: 'forward' (oldest first) or 'backward' (newest first, default)"),
 		),
+		mcp.WithNumber("stepSeconds",
+			mcp.Description("Step interval in seconds for metric queries such as rate() or count_over_time() (default: chosen by Loki from the time range). "+
+				"Ignored for log selectors"),
+		),
+		mcp.WithArray("extractFields",
+			mcp.Description("Field names to extract from JSON or logfmt log lines into a 'fields' object on each entry (e.g., [\"status\", \"latency\", \"msg\"]). Dotted names descend into nested JSON."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("dropLine",
+			mcp.Description("Omit the raw log line from entries where fields were extracted (default: false)"),
+		),
+		mcp.WithString("minLevel",
+			mcp.Description("Keep only entries at or above this level: 'debug', 'info', 'warn', or 'error'. "+
+				"The level is detected from a level/severity label, structured metadata, or a JSON/logfmt field; entries without a detectable level are dropped. "+
+				"Filtering happens after fetching, so fewer than limit entries may be returned."),
+		),
+		mcp.WithString("dedupe",
+			mcp.Description("Collapse identical entries into one with a count and first/last timestamps: "+
+				"'none' (default), 'consecutive' (adjacent duplicates only), or 'all'. "+
+				"When extractFields is set, entries are compared on the extracted fields instead of the raw line."),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Instead of running the query, return the exact upstream request(s) it would send: "+
+				"method, URL, decoded params (including the converted start/end times), and headers with the token redacted (default: false)"),
+		),
+		mcp.WithBoolean("precheckHealth",
+			mcp.Description("Check the datasource health endpoint first and fail with a clear 'datasource is unhealthy' error instead of running the query "+
+				"(default: false, or true when GRAFANA_PRECHECK_HEALTH is set)"),
+		),
+		mcp.WithNumber("timeoutSeconds",
+			mcp.Description("Override the request timeout for this call in seconds (default: the server-wide timeout, max: 300)"),
+		),
 	)
 }
 
 // RegisterQueryLogs registers the query_loki_logs tool with the MCP server.
-func RegisterQueryLogs(s *server.MCPServer) {
+func RegisterQueryLogs(s grafana.ToolRegistry) {
 	s.AddTool(newQueryLogsTool(), queryLogsHandler)
 }
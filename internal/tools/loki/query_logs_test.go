@@ -0,0 +1,106 @@
+package loki
+
+import (
+	"reflect"
+	"testing"
+)
+
+// countOverTimeMatrix is a query_range response for sum by (level) (count_over_time({job="api"}[1m])).
+const countOverTimeMatrix = `{
+  "status": "success",
+  "data": {
+    "resultType": "matrix",
+    "result": [
+      {
+        "metric": {"level": "error"},
+        "values": [[1700000000, "3"], [1700000060.5, "7"]]
+      },
+      {
+        "metric": {"level": "info"},
+        "values": [[1700000000, "120"]]
+      }
+    ],
+    "stats": {"summary": {"bytesProcessedPerSecond": 1024}}
+  }
+}`
+
+func TestDecodeQueryRangeMetricMatrix(t *testing.T) {
+	streams, err := decodeQueryRange([]byte(countOverTimeMatrix))
+	if err != nil {
+		t.Fatalf("decodeQueryRange() error: %v", err)
+	}
+	if len(streams) != 2 {
+		t.Fatalf("decodeQueryRange() returned %d streams, want 2", len(streams))
+	}
+	for i, stream := range streams {
+		if !stream.isMetric {
+			t.Errorf("stream %d: isMetric = false, want true", i)
+		}
+	}
+
+	want := []LogEntry{
+		{Timestamp: "1700000000000000000", Value: ptr(3), Labels: map[string]string{"level": "error"}},
+		{Timestamp: "1700000060500000000", Value: ptr(7), Labels: map[string]string{"level": "error"}},
+		{Timestamp: "1700000000000000000", Value: ptr(120), Labels: map[string]string{"level": "info"}},
+	}
+
+	got := streamEntries(streams)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("streamEntries() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDecodeQueryRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []LogEntry
+		wantErr bool
+	}{
+		{
+			name: "vector",
+			body: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1700000000,"42"]}]}}`,
+			want: []LogEntry{{Timestamp: "1700000000000000000", Value: ptr(42), Labels: map[string]string{"job": "api"}}},
+		},
+		{
+			name: "streams",
+			body: `{"status":"success","data":{"resultType":"streams","result":[{"stream":{"job":"api"},"values":[["1700000000000000001","GET /health 200"]]}]}}`,
+			want: []LogEntry{{Timestamp: "1700000000000000001", Line: "GET /health 200", Labels: map[string]string{"job": "api"}}},
+		},
+		{
+			name: "malformed samples skipped",
+			body: `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[["bad","1"],[1700000000,"NaN?"],[1700000000,"2"]]}]}}`,
+			want: []LogEntry{{Timestamp: "1700000000000000000", Value: ptr(2), Labels: map[string]string{}}},
+		},
+		{
+			name:    "error status",
+			body:    `{"status":"error","data":{}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported result type",
+			body:    `{"status":"success","data":{"resultType":"scalar","result":[]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, err := decodeQueryRange([]byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("decodeQueryRange() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeQueryRange() error: %v", err)
+			}
+			if got := streamEntries(streams); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("streamEntries() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
@@ -0,0 +1,283 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// metricFunctionPattern matches the range aggregations that turn a log selector into a metric query.
+var metricFunctionPattern = regexp.MustCompile(`\b(rate|rate_counter|bytes_rate|count_over_time|bytes_over_time|` +
+	`(sum|avg|min|max|stddev|stdvar|quantile|first|last|absent)_over_time)\s*\(`)
+
+// MetricSample is a single point in a metric series.
+type MetricSample struct {
+	Timestamp string  `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// MetricSeries is one labelled series from a metric LogQL query.
+type MetricSeries struct {
+	Labels  map[string]string `json:"labels"`
+	Samples []MetricSample    `json:"samples"`
+}
+
+// MetricResult represents the output of query_loki_metric.
+type MetricResult struct {
+	ResultType string         `json:"resultType"`
+	Series     []MetricSeries `json:"series"`
+}
+
+type queryMetricParams struct {
+	DatasourceUID  string            `json:"datasourceUid"`
+	LogQL          string            `json:"logql"`
+	QueryType      string            `json:"queryType,omitempty"` // "instant" or "range", defaults to "range"
+	TimeRFC3339    string            `json:"timeRfc3339,omitempty"`
+	StartRFC3339   string            `json:"startRfc3339,omitempty"`
+	EndRFC3339     string            `json:"endRfc3339,omitempty"`
+	StepSeconds    int               `json:"stepSeconds,omitempty"`
+	TimeoutSeconds int               `json:"timeoutSeconds,omitempty"`
+	PrecheckHealth *bool             `json:"precheckHealth,omitempty"`
+	DryRun         bool              `json:"dryRun,omitempty"`
+	TenantID       string            `json:"tenantId,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+}
+
+// metricQueryResponse represents a matrix or vector response from Loki's query and query_range APIs.
+type metricQueryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string   `json:"metric"`
+			Value  []json.RawMessage   `json:"value,omitempty"`  // vector: [ts, "v"]
+			Values [][]json.RawMessage `json:"values,omitempty"` // matrix: [[ts, "v"], ...]
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// validateMetricQuery rejects plain log selectors, which Loki would answer with log lines instead of samples.
+func validateMetricQuery(query string) error {
+	trimmed := strings.TrimSpace(query)
+	if trimmed == "" {
+		return fmt.Errorf("logql is required")
+	}
+	if strings.HasPrefix(trimmed, "{") || !metricFunctionPattern.MatchString(trimmed) {
+		return fmt.Errorf("logql is not a metric query: wrap the log selector in a range aggregation, "+
+			"e.g., 'sum by (level) (count_over_time(%s [5m]))', or use query_loki_logs to fetch log lines", trimmed)
+	}
+	return nil
+}
+
+// fetchMetric runs a metric LogQL query as a range or instant query and flattens the result into labelled series.
+func (c *client) fetchMetric(ctx context.Context, query string, rangeQuery bool, timeRFC3339, startRFC3339, endRFC3339 string, stepSeconds int) (*MetricResult, error) {
+	params := url.Values{}
+	params.Add("query", query)
+
+	path := "/loki/api/v1/query"
+	if rangeQuery {
+		path = "/loki/api/v1/query_range"
+		if err := addTimeRangeParams(params, startRFC3339, endRFC3339); err != nil {
+			return nil, err
+		}
+		if stepSeconds > 0 {
+			params.Add("step", strconv.Itoa(stepSeconds))
+		}
+	} else if timeRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, timeRFC3339)
+		if err != nil {
+			return nil, fmt.Errorf("parsing query time: %w", err)
+		}
+		params.Add("time", strconv.FormatInt(t.UnixNano(), 10))
+	}
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", path, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response metricQueryResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return nil, fmt.Errorf("unmarshalling query response: %w", err)
+	}
+
+	if response.Status != "success" {
+		return nil, fmt.Errorf("loki API returned unexpected status: %s", response.Status)
+	}
+
+	switch response.Data.ResultType {
+	case "matrix", "vector":
+	default:
+		return nil, fmt.Errorf("expected a matrix or vector result, got %s", response.Data.ResultType)
+	}
+
+	result := &MetricResult{ResultType: response.Data.ResultType, Series: []MetricSeries{}}
+	for _, r := range response.Data.Result {
+		points := r.Values
+		if response.Data.ResultType == "vector" {
+			points = [][]json.RawMessage{r.Value}
+		}
+
+		series := MetricSeries{Labels: r.Metric, Samples: []MetricSample{}}
+		for _, point := range points {
+			if sample, ok := parseMetricSample(point); ok {
+				series.Samples = append(series.Samples, sample)
+			}
+		}
+		result.Series = append(result.Series, series)
+	}
+
+	return result, nil
+}
+
+// parseMetricSample parses a [unixSeconds, "value"] pair; Loki encodes sample values as strings.
+func parseMetricSample(point []json.RawMessage) (MetricSample, bool) {
+	if len(point) != 2 {
+		return MetricSample{}, false
+	}
+
+	var ts float64
+	if err := json.Unmarshal(point[0], &ts); err != nil {
+		return MetricSample{}, false
+	}
+
+	var str string
+	if err := json.Unmarshal(point[1], &str); err != nil {
+		return MetricSample{}, false
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return MetricSample{}, false
+	}
+
+	sec := int64(ts)
+	nsec := int64((ts - float64(sec)) * float64(time.Second))
+	return MetricSample{
+		Timestamp: time.Unix(sec, nsec).UTC().Format(time.RFC3339Nano),
+		Value:     v,
+	}, true
+}
+
+func queryMetricHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params queryMetricParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if err := validateMetricQuery(params.LogQL); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	queryType := params.QueryType
+	if queryType == "" {
+		queryType = "range"
+	}
+	if queryType != "instant" && queryType != "range" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid queryType: %s (must be 'instant' or 'range')", queryType)), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
+	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ctx, cancel := grafana.WithRequestTimeout(ctx, c.httpClient, params.TimeoutSeconds)
+	defer cancel()
+	if params.DryRun {
+		ctx = grafana.WithDryRun(ctx)
+	}
+
+	if err := grafana.PrecheckHealth(ctx, c.httpClient, c.datasourceUID, params.PrecheckHealth); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+
+	// An instant query ignores the range, so only a range query is clamped
+	var notice string
+	if queryType == "range" {
+		if startTime, notice, err = maxRange.ClampRFC3339(startTime, endTime); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	result, err := c.fetchMetric(ctx, params.LogQL, queryType == "range", params.TimeRFC3339, startTime, endTime, params.StepSeconds)
+	if params.DryRun {
+		return grafana.DryRunResult(ctx), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(grafana.WrapResult(result, len(result.Series)), notice))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newQueryMetricTool() mcp.Tool {
+	return mcp.NewTool(
+		"query_loki_metric",
+		mcp.WithDescription("Executes a metric LogQL query (e.g., rate, count_over_time, bytes_over_time, optionally wrapped in sum/topk) against a Loki datasource "+
+			"and returns numeric series of {labels, samples: [{timestamp, value}]}. "+
+			"Runs as a range query by default; set queryType='instant' for a single value per series. "+
+			"Plain log selectors are rejected; use query_loki_logs to fetch log lines. Defaults to the last "+grafana.FormatDuration(defaultWindow)+"."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+		mcp.WithString("logql",
+			mcp.Description("Metric LogQL expression (e.g., 'sum by (level) (count_over_time({app=\"nginx\"} |= \"error\" [5m]))')"),
+			mcp.Required(),
+		),
+		mcp.WithString("queryType",
+			mcp.Description("Query type: 'range' (default) for a time series, or 'instant' for a single point in time"),
+		),
+		mcp.WithString("timeRfc3339",
+			mcp.Description("Evaluation time for instant queries in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time for range queries in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time for range queries in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("stepSeconds",
+			mcp.Description("Step interval for range queries in seconds (default: chosen by Loki from the time range)"),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Instead of running the query, return the exact upstream request(s) it would send: "+
+				"method, URL, decoded params (including the converted start/end times), and headers with the token redacted (default: false)"),
+		),
+		mcp.WithBoolean("precheckHealth",
+			mcp.Description("Check the datasource health endpoint first and fail with a clear 'datasource is unhealthy' error instead of running the query "+
+				"(default: false, or true when GRAFANA_PRECHECK_HEALTH is set)"),
+		),
+		mcp.WithNumber("timeoutSeconds",
+			mcp.Description("Override the request timeout for this call in seconds (default: the server-wide timeout, max: 300)"),
+		),
+	)
+}
+
+// RegisterQueryMetric registers the query_loki_metric tool with the MCP server.
+func RegisterQueryMetric(s grafana.ToolRegistry) {
+	s.AddTool(newQueryMetricTool(), queryMetricHandler)
+}
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 // Stats represents statistics from Loki's index/stats endpoint.
@@ -16,6 +16,22 @@ type Stats struct {
 	Chunks  int `json:"chunks"`
 	Entries int `json:"entries"`
 	Bytes   int `json:"bytes"`
+
+	BytesHuman string `json:"bytesHuman"` // Bytes in binary units, e.g., "1.4 GiB"
+}
+
+// humanizeBytes formats a byte count in binary units with one decimal place, e.g., "1.4 GiB".
+func humanizeBytes(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := unit, 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 type queryStatsParams struct {
@@ -42,6 +58,7 @@ func (c *client) fetchStats(ctx context.Context, query, startRFC3339, endRFC3339
 	if err := json.Unmarshal(bodyBytes, &stats); err != nil {
 		return nil, fmt.Errorf("unmarshalling stats response: %w", err)
 	}
+	stats.BytesHuman = humanizeBytes(stats.Bytes)
 
 	return &stats, nil
 }
@@ -52,19 +69,23 @@ func queryStatsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
 	}
 
 	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	stats, err := c.fetchStats(ctx, params.LogQL, startTime, endTime)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	jsonData, err := json.MarshalIndent(stats, "", "  ")
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(stats, notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -75,17 +96,16 @@ func queryStatsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 func newQueryStatsTool() mcp.Tool {
 	return mcp.NewTool(
 		"query_loki_stats",
-		mcp.WithDescription("Retrieves statistics about log streams matching a LogQL selector within a Loki datasource and time range. Returns counts of streams, chunks, entries, and bytes. The logql parameter must be a simple label selector (e.g., '{app=\"nginx\"}') and does not support line filters or aggregations. Useful for checking query size before fetching logs. Defaults to the last hour."),
+		mcp.WithDescription("Retrieves statistics about log streams matching a LogQL selector within a Loki datasource and time range. Returns counts of streams, chunks, entries, and bytes, plus the bytes in readable form (bytesHuman, e.g., '1.4 GiB'). The logql parameter must be a simple label selector (e.g., '{app=\"nginx\"}') and does not support line filters or aggregations. Useful for checking query size before fetching logs. Defaults to the last "+grafana.FormatDuration(defaultWindow)+"."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Loki datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
 		),
 		mcp.WithString("logql",
 			mcp.Description("LogQL label selector expression (e.g., '{app=\"nginx\"}')"),
 			mcp.Required(),
 		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
@@ -94,6 +114,6 @@ func newQueryStatsTool() mcp.Tool {
 }
 
 // RegisterQueryStats registers the query_loki_stats tool with the MCP server.
-func RegisterQueryStats(s *server.MCPServer) {
+func RegisterQueryStats(s grafana.ToolRegistry) {
 	s.AddTool(newQueryStatsTool(), queryStatsHandler)
 }
@@ -0,0 +1,170 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultSuggestMaxValues is the default number of values listed per suggested label.
+	DefaultSuggestMaxValues = 20
+
+	// MaxSuggestMaxValues is the maximum number of values that can be listed per suggested label.
+	MaxSuggestMaxValues = 100
+)
+
+// equalityMatcherPattern finds label names pinned to a single value by an '=' matcher in a selector.
+var equalityMatcherPattern = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*=\s*"`)
+
+// LabelSuggestion lists the distinct values a label takes within the matched streams.
+type LabelSuggestion struct {
+	Label      string   `json:"label"`
+	ValueCount int      `json:"valueCount"`
+	Values     []string `json:"values"`
+	Truncated  bool     `json:"truncated,omitempty"`
+}
+
+// SuggestLabelsResult represents the output of suggest_loki_labels.
+type SuggestLabelsResult struct {
+	Selector    string            `json:"selector"`
+	StreamCount int               `json:"streamCount"`
+	Labels      []LabelSuggestion `json:"labels"`
+}
+
+type suggestLabelsParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Selector      string `json:"selector"`
+	StartRFC3339  string `json:"startRfc3339,omitempty"`
+	EndRFC3339    string `json:"endRfc3339,omitempty"`
+	MaxValues     int    `json:"maxValues,omitempty"`
+}
+
+func suggestLabelsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params suggestLabelsParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Selector == "" {
+		return mcp.NewToolResultError("selector is required"), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
+	}
+
+	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	maxValues := enforceBoundedLimit(params.MaxValues, DefaultSuggestMaxValues, MaxSuggestMaxValues)
+
+	series, err := c.fetchSeries(ctx, params.Selector, startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Labels already pinned by an equality matcher can't narrow the selector further
+	pinned := make(map[string]bool)
+	for _, m := range equalityMatcherPattern.FindAllStringSubmatch(params.Selector, -1) {
+		pinned[m[1]] = true
+	}
+
+	result := &SuggestLabelsResult{
+		Selector:    params.Selector,
+		StreamCount: len(series),
+		Labels:      []LabelSuggestion{},
+	}
+
+	for label, values := range distinctLabelValues(series) {
+		if pinned[label] {
+			continue
+		}
+
+		suggestion := LabelSuggestion{
+			Label:      label,
+			ValueCount: len(values),
+			Values:     values,
+		}
+		if len(values) > maxValues {
+			suggestion.Values = values[:maxValues]
+			suggestion.Truncated = true
+		}
+		result.Labels = append(result.Labels, suggestion)
+	}
+
+	sort.Slice(result.Labels, func(i, j int) bool {
+		return result.Labels[i].Label < result.Labels[j].Label
+	})
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(result, notice))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// distinctLabelValues collects the sorted distinct values of every label across the given series.
+func distinctLabelValues(series []map[string]string) map[string][]string {
+	sets := make(map[string]map[string]bool)
+	for _, labels := range series {
+		for name, value := range labels {
+			if sets[name] == nil {
+				sets[name] = make(map[string]bool)
+			}
+			sets[name][value] = true
+		}
+	}
+
+	result := make(map[string][]string, len(sets))
+	for name, set := range sets {
+		values := make([]string, 0, len(set))
+		for v := range set {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		result[name] = values
+	}
+
+	return result
+}
+
+func newSuggestLabelsTool() mcp.Tool {
+	return mcp.NewTool(
+		"suggest_loki_labels",
+		mcp.WithDescription("Suggests how to narrow a partial LogQL stream selector. "+
+			"Finds the streams matching the selector within the time range and returns, for each label not already pinned by an '=' matcher, "+
+			"the distinct values it takes across those streams. "+
+			"Use this while building a query to pick valid label values (e.g., selector '{namespace=\"prod\"}' might suggest app and pod values). "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+"."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Loki datasource to query; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+		mcp.WithString("selector",
+			mcp.Description("Partial LogQL stream selector (e.g., '{namespace=\"prod\"}')"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("maxValues",
+			mcp.Description("Maximum number of values listed per label (default: 20, max: 100)"),
+		),
+	)
+}
+
+// RegisterSuggestLabels registers the suggest_loki_labels tool with the MCP server.
+func RegisterSuggestLabels(s grafana.ToolRegistry) {
+	s.AddTool(newSuggestLabelsTool(), suggestLabelsHandler)
+}
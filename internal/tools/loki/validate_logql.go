@@ -0,0 +1,127 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// parseErrorPosition matches the position Loki reports in LogQL parse errors.
+var parseErrorPosition = regexp.MustCompile(`line (\d+), col (\d+)`)
+
+// ValidationResult is the output of validate_logql.
+type ValidationResult struct {
+	Valid     bool   `json:"valid"`
+	Formatted string `json:"formatted,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+}
+
+type validateLogQLParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Query         string `json:"query"`
+}
+
+// formatQueryResponse represents the JSON response from Loki's format_query endpoint.
+type formatQueryResponse struct {
+	Status string `json:"status"`
+	Data   string `json:"data"`
+}
+
+// validateQuery asks Loki to parse and pretty-print a LogQL query without executing it.
+// A 400 response is a parse error and is reported in the result rather than as an error.
+func (c *client) validateQuery(ctx context.Context, query string) (*ValidationResult, error) {
+	params := url.Values{}
+	params.Add("query", query)
+
+	statusCode, bodyBytes, err := c.doRequest(ctx, "GET", "/loki/api/v1/format_query", params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch statusCode {
+	case http.StatusOK:
+		var response formatQueryResponse
+		if err := json.Unmarshal(bodyBytes, &response); err != nil {
+			return nil, fmt.Errorf("unmarshalling format_query response: %w", err)
+		}
+		return &ValidationResult{Valid: true, Formatted: response.Data}, nil
+
+	case http.StatusBadRequest:
+		// Newer Loki versions return a JSON error envelope, older ones plain text
+		message := string(bodyBytes)
+		var envelope struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(bodyBytes, &envelope); err == nil && envelope.Message != "" {
+			message = envelope.Message
+		}
+
+		result := &ValidationResult{Valid: false, Error: message}
+		if m := parseErrorPosition.FindStringSubmatch(message); m != nil {
+			result.Line, _ = strconv.Atoi(m[1])
+			result.Column, _ = strconv.Atoi(m[2])
+		}
+		return result, nil
+
+	default:
+		return nil, grafana.StatusError("loki API", statusCode, bodyBytes)
+	}
+}
+
+func validateLogQLHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params validateLogQLParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Loki client: %v", err)), nil
+	}
+
+	result, err := c.validateQuery(ctx, params.Query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newValidateLogQLTool() mcp.Tool {
+	return mcp.NewTool(
+		"validate_logql",
+		mcp.WithDescription("Syntax-checks a LogQL query using Loki's format_query endpoint without executing it. "+
+			"Returns {valid: true, formatted} with the pretty-printed query, or {valid: false, error, line, column} with the parse error and its position. "+
+			"Run this on a generated query before query_loki_logs to avoid wasted calls on malformed LogQL."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Loki datasource to validate against; defaults to GRAFANA_DEFAULT_LOKI_UID or the default Loki datasource"),
+		),
+		mcp.WithString("query",
+			mcp.Description("The LogQL query to validate (e.g., '{app=\"api\"} |= \"error\" | json')"),
+			mcp.Required(),
+		),
+	)
+}
+
+// RegisterValidateLogQL registers the validate_logql tool with the MCP server.
+func RegisterValidateLogQL(s grafana.ToolRegistry) {
+	s.AddTool(newValidateLogQLTool(), validateLogQLHandler)
+}
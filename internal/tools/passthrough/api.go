@@ -0,0 +1,185 @@
+package passthrough
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type apiParams struct {
+	Method string         `json:"method,omitempty"`
+	Path   string         `json:"path"`
+	Query  map[string]any `json:"query,omitempty"`
+	Body   string         `json:"body,omitempty"`
+}
+
+func apiHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params apiParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	method, err := validateMethod(params.Method)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	apiPath, err := cleanPath(params.Path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !strings.HasPrefix(apiPath, "/api/") {
+		return mcp.NewToolResultError(fmt.Sprintf("path must start with /api/: %s", params.Path)), nil
+	}
+	if err := checkDatasourcePath(apiPath); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating passthrough client: %v", err)), nil
+	}
+
+	resp, err := c.do(ctx, method, apiPath, queryValues(params.Query), params.Body)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if method == http.MethodGet && resp.StatusCode == http.StatusOK && datasourceRestricted() && isDatasourceList(strings.ToLower(apiPath)) {
+		if err := filterDatasourceList(resp); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	jsonData, err := grafana.MarshalJSON(resp)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	if resp.StatusCode >= 400 {
+		return mcp.NewToolResultError(string(jsonData)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// Datasource restriction checks, swapped out in tests.
+var (
+	datasourceRestricted = grafana.DatasourceRestricted
+	datasourcePermitted  = grafana.DatasourcePermitted
+	checkDatasource      = grafana.CheckDatasource
+)
+
+// checkDatasourcePath applies the datasource allowlist/denylist to Grafana API paths that reach a datasource.
+// Routes addressing a datasource by UID are checked against that UID. Routes addressing one any other way
+// (numeric ID, name, or a query body) cannot be checked, so they are refused outright while a restriction
+// is configured. The datasource list itself is allowed and filtered by filterDatasourceList.
+func checkDatasourcePath(apiPath string) error {
+	if !datasourceRestricted() {
+		return nil
+	}
+
+	// Grafana decodes the path before routing, so match against the decoded form
+	decoded, err := url.PathUnescape(apiPath)
+	if err != nil {
+		return fmt.Errorf("invalid path %s: %w", apiPath, err)
+	}
+	lower := strings.ToLower(decoded)
+
+	if strings.HasPrefix(lower, "/api/ds/") {
+		return datasourceRouteRefused(apiPath)
+	}
+	if lower == "/api/datasources" || lower == "/api/datasources/" {
+		return nil
+	}
+	if !strings.HasPrefix(lower, "/api/datasources/") {
+		return nil
+	}
+
+	rest := decoded[len("/api/datasources/"):]
+	for _, prefix := range []string{"proxy/uid/", "uid/"} {
+		if strings.HasPrefix(strings.ToLower(rest), prefix) {
+			uid, _, _ := strings.Cut(rest[len(prefix):], "/")
+			return checkDatasource(uid)
+		}
+	}
+	return datasourceRouteRefused(apiPath)
+}
+
+// datasourceRouteRefused explains why a datasource route that can't be checked was refused.
+func datasourceRouteRefused(apiPath string) error {
+	return fmt.Errorf("datasource not permitted: %s is unavailable while a datasource allowlist/denylist is configured; "+
+		"address the datasource by UID (/api/datasources/uid/{uid}/...) or use grafana_datasource_proxy instead", apiPath)
+}
+
+// isDatasourceList reports whether apiPath is Grafana's datasource list endpoint.
+func isDatasourceList(apiPath string) bool {
+	return apiPath == "/api/datasources" || apiPath == "/api/datasources/"
+}
+
+// filterDatasourceList drops datasources the allowlist/denylist forbids from a /api/datasources response.
+// A truncated body can't be filtered reliably, so it is refused.
+func filterDatasourceList(resp *Response) error {
+	if resp.Truncated {
+		return fmt.Errorf("datasource list exceeds %d bytes and cannot be filtered by the datasource allowlist/denylist; "+
+			"read the grafana://datasources resource instead", MaxResponseBytes)
+	}
+
+	var datasources []json.RawMessage
+	if err := json.Unmarshal([]byte(resp.Body), &datasources); err != nil {
+		return fmt.Errorf("unmarshalling datasource list: %w", err)
+	}
+
+	permitted := make([]json.RawMessage, 0, len(datasources))
+	for _, raw := range datasources {
+		var ds struct {
+			UID string `json:"uid"`
+		}
+		if err := json.Unmarshal(raw, &ds); err != nil {
+			return fmt.Errorf("unmarshalling datasource: %w", err)
+		}
+		if datasourcePermitted(ds.UID) {
+			permitted = append(permitted, raw)
+		}
+	}
+
+	body, err := json.Marshal(permitted)
+	if err != nil {
+		return fmt.Errorf("marshalling datasource list: %w", err)
+	}
+	resp.Body = string(body)
+	return nil
+}
+
+func newAPITool() mcp.Tool {
+	return mcp.NewTool(
+		"grafana_api",
+		mcp.WithDescription("Escape hatch for Grafana HTTP API endpoints without a dedicated tool (e.g., teams, users, orgs, folders): "+
+			"issues an authenticated request against an /api/... path on the Grafana instance and returns the status code, content type, "+
+			"and raw body (truncated at 1 MiB). Only GET and HEAD are allowed unless the server was started with GRAFANA_ALLOW_WRITE=1. "+
+			"Use grafana_datasource_proxy to reach a datasource's own API."),
+		mcp.WithString("path",
+			mcp.Description("Grafana API path starting with /api/ (e.g., '/api/org', '/api/folders', '/api/teams/search')"),
+			mcp.Required(),
+		),
+		mcp.WithString("method",
+			mcp.Description("HTTP method (default: GET). Non-GET methods require GRAFANA_ALLOW_WRITE=1."),
+		),
+		mcp.WithObject("query",
+			mcp.Description("Query parameters as an object; use an array value for repeated parameters (e.g., {\"query\": \"ops\", \"perpage\": 50})"),
+		),
+		mcp.WithString("body",
+			mcp.Description("Optional JSON request body (only meaningful for write methods)"),
+		),
+	)
+}
+
+// RegisterAPI registers the grafana_api tool.
+func RegisterAPI(s grafana.ToolRegistry) {
+	s.AddTool(newAPITool(), apiHandler)
+}
@@ -0,0 +1,85 @@
+package passthrough
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// withDenylist restricts datasources to everything but denied for the duration of a test.
+func withDenylist(t *testing.T, denied ...string) {
+	t.Helper()
+	deny := make(map[string]bool)
+	for _, uid := range denied {
+		deny[uid] = true
+	}
+
+	savedRestricted, savedPermitted, savedCheck := datasourceRestricted, datasourcePermitted, checkDatasource
+	datasourceRestricted = func() bool { return true }
+	datasourcePermitted = func(uid string) bool { return !deny[uid] }
+	checkDatasource = func(uid string) error {
+		if deny[uid] {
+			return fmt.Errorf("datasource not permitted: %q", uid)
+		}
+		return nil
+	}
+	t.Cleanup(func() {
+		datasourceRestricted, datasourcePermitted, checkDatasource = savedRestricted, savedPermitted, savedCheck
+	})
+}
+
+func TestCheckDatasourcePath(t *testing.T) {
+	withDenylist(t, "secret")
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "/api/org"},
+		{path: "/api/datasources"},
+		{path: "/api/datasources/"},
+		{path: "/api/datasources/uid/public"},
+		{path: "/api/datasources/uid/public/health"},
+		{path: "/api/datasources/proxy/uid/public/api/v1/query"},
+		{path: "/api/datasources/uid/secret", wantErr: true},
+		{path: "/api/datasources/uid/secret/health", wantErr: true},
+		{path: "/api/datasources/uid/secret/resources/api/v1/labels", wantErr: true},
+		{path: "/api/datasources/proxy/uid/secret/api/v1/query", wantErr: true},
+		{path: "/api/datasources/UID/secret", wantErr: true},
+		{path: "/api/datasources/uid/%73ecret", wantErr: true},
+		{path: "/api/datasources/7", wantErr: true},
+		{path: "/api/datasources/7/resources/api/v1/labels", wantErr: true},
+		{path: "/api/datasources/proxy/7/api/v1/query", wantErr: true},
+		{path: "/api/datasources/name/secret", wantErr: true},
+		{path: "/api/ds/query", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := checkDatasourcePath(tt.path)
+			if tt.wantErr && err == nil {
+				t.Fatalf("checkDatasourcePath(%q) = nil, want an error", tt.path)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("checkDatasourcePath(%q) error: %v", tt.path, err)
+			}
+		})
+	}
+}
+
+func TestFilterDatasourceList(t *testing.T) {
+	withDenylist(t, "secret")
+
+	resp := &Response{Body: `[{"id":1,"uid":"public","type":"loki"},{"id":2,"uid":"secret","type":"prometheus"}]`}
+	if err := filterDatasourceList(resp); err != nil {
+		t.Fatalf("filterDatasourceList() error: %v", err)
+	}
+	if want := `[{"id":1,"uid":"public","type":"loki"}]`; resp.Body != want {
+		t.Errorf("filterDatasourceList() body = %s, want %s", resp.Body, want)
+	}
+
+	truncated := &Response{Body: `[{"id":1,"uid":"public"`, Truncated: true}
+	if err := filterDatasourceList(truncated); err == nil || !strings.Contains(err.Error(), "cannot be filtered") {
+		t.Errorf("filterDatasourceList() on a truncated body error = %v, want a cannot be filtered error", err)
+	}
+}
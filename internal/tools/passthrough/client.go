@@ -0,0 +1,149 @@
+// Package passthrough provides escape-hatch MCP tools that forward raw requests to the
+// Grafana API or a datasource proxy for endpoints that lack a dedicated tool.
+package passthrough
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+const (
+	// MaxResponseBytes caps how much of a passthrough response body is returned.
+	MaxResponseBytes = 1024 * 1024
+
+	// allowWriteEnv enables non-GET methods for passthrough tools when set to "1" or "true".
+	allowWriteEnv = "GRAFANA_ALLOW_WRITE"
+)
+
+// readOnlyMethods are always permitted; other methods require GRAFANA_ALLOW_WRITE.
+var readOnlyMethods = map[string]bool{
+	http.MethodGet:  true,
+	http.MethodHead: true,
+}
+
+// writeMethods are permitted only when GRAFANA_ALLOW_WRITE is enabled.
+var writeMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// client provides methods for issuing raw requests against Grafana.
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// newClient creates a new passthrough client.
+func newClient() (*client, error) {
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		httpClient: httpClient,
+		baseURL:    grafanaURL,
+	}, nil
+}
+
+// Response is the raw result of a passthrough request.
+type Response struct {
+	StatusCode  int    `json:"statusCode"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body"`
+	Truncated   bool   `json:"truncated,omitempty"`
+}
+
+// do performs a raw HTTP request and returns the response, whatever its status.
+func (c *client) do(ctx context.Context, method, reqPath string, params url.Values, body string) (*Response, error) {
+	req := grafana.Request{
+		Method:   method,
+		URL:      c.baseURL + reqPath,
+		Params:   params,
+		MaxBytes: MaxResponseBytes,
+		Truncate: true,
+	}
+	if body != "" {
+		req.Body, req.ContentType = []byte(body), "application/json"
+	}
+
+	resp, err := grafana.Send(ctx, c.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(resp.Body),
+		Truncated:   resp.Truncated,
+	}, nil
+}
+
+// writesAllowed reports whether GRAFANA_ALLOW_WRITE enables non-GET methods.
+func writesAllowed() bool {
+	v := strings.ToLower(os.Getenv(allowWriteEnv))
+	return v == "1" || v == "true"
+}
+
+// validateMethod normalizes the method and checks it against the read-only gate.
+func validateMethod(method string) (string, error) {
+	method = strings.ToUpper(method)
+	if method == "" {
+		return http.MethodGet, nil
+	}
+
+	if readOnlyMethods[method] {
+		return method, nil
+	}
+	if !writeMethods[method] {
+		return "", fmt.Errorf("unsupported method: %s", method)
+	}
+	if !writesAllowed() {
+		return "", fmt.Errorf("method %s is not permitted: this server is read-only unless %s=1 is set", method, allowWriteEnv)
+	}
+	return method, nil
+}
+
+// cleanPath validates a caller-supplied relative path and rejects traversal out of its prefix.
+func cleanPath(p string) (string, error) {
+	if !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("path must start with '/': %s", p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("path must not contain '..' segments: %s", p)
+		}
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned, nil
+}
+
+// queryValues converts a JSON object of query parameters into url.Values.
+// Values may be strings, numbers, booleans, or arrays of those for repeated parameters.
+func queryValues(query map[string]any) url.Values {
+	params := url.Values{}
+	for key, raw := range query {
+		switch v := raw.(type) {
+		case []any:
+			for _, item := range v {
+				params.Add(key, fmt.Sprint(item))
+			}
+		default:
+			params.Add(key, fmt.Sprint(v))
+		}
+	}
+	return params
+}
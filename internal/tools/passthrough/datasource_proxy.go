@@ -0,0 +1,101 @@
+package passthrough
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type datasourceProxyParams struct {
+	DatasourceUID string         `json:"datasourceUid"`
+	Method        string         `json:"method,omitempty"`
+	Path          string         `json:"path"`
+	Query         map[string]any `json:"query,omitempty"`
+	Body          string         `json:"body,omitempty"`
+}
+
+func datasourceProxyHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params datasourceProxyParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.DatasourceUID == "" {
+		return mcp.NewToolResultError("datasourceUid is required"), nil
+	}
+	// The UID is required, so this only turns a numeric datasource ID into its UID
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	method, err := validateMethod(params.Method)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	relPath, err := cleanPath(params.Path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating passthrough client: %v", err)), nil
+	}
+
+	proxyPath := fmt.Sprintf("/api/datasources/proxy/uid/%s%s", url.PathEscape(datasourceUID), relPath)
+	resp, err := c.do(ctx, method, proxyPath, queryValues(params.Query), params.Body)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(resp)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	if resp.StatusCode >= 400 {
+		return mcp.NewToolResultError(string(jsonData)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newDatasourceProxyTool() mcp.Tool {
+	return mcp.NewTool(
+		"grafana_datasource_proxy",
+		mcp.WithDescription("Escape hatch for datasource endpoints without a dedicated tool: forwards a raw request through "+
+			"Grafana's datasource proxy (/api/datasources/proxy/uid/{uid}{path}) and returns the status code, content type, and raw body "+
+			"(truncated at 1 MiB). Only GET and HEAD are allowed unless the server was started with GRAFANA_ALLOW_WRITE=1. "+
+			"Prefer the dedicated Loki, Prometheus, and Tempo tools when they cover the endpoint."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID (or numeric ID) of the datasource to proxy to"),
+			mcp.Required(),
+		),
+		mcp.WithString("path",
+			mcp.Description("Backend path relative to the datasource, starting with '/' (e.g., '/api/v1/status/buildinfo', '/loki/api/v1/status/buildinfo')"),
+			mcp.Required(),
+		),
+		mcp.WithString("method",
+			mcp.Description("HTTP method (default: GET). Non-GET methods require GRAFANA_ALLOW_WRITE=1."),
+		),
+		mcp.WithObject("query",
+			mcp.Description("Query parameters as an object; use an array value for repeated parameters (e.g., {\"match[]\": [\"up\", \"process_start_time_seconds\"]})"),
+		),
+		mcp.WithString("body",
+			mcp.Description("Optional JSON request body (only meaningful for write methods)"),
+		),
+	)
+}
+
+// RegisterDatasourceProxy registers the grafana_datasource_proxy tool.
+func RegisterDatasourceProxy(s grafana.ToolRegistry) {
+	s.AddTool(newDatasourceProxyTool(), datasourceProxyHandler)
+}
@@ -5,9 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/krmcbride/mcp-grafana/internal/grafana"
@@ -21,14 +22,37 @@ const (
 	DefaultStepSeconds = 60
 )
 
+// defaultWindow is how far back queries reach when no start time is given.
+// Override with PROM_DEFAULT_WINDOW (e.g., "15m", "6h").
+var defaultWindow = grafana.DurationFromEnv("PROM_DEFAULT_WINDOW", time.Hour)
+
+// defaultLimit is the number of list results returned when no limit is given.
+// Override with PROM_DEFAULT_LIMIT.
+var defaultLimit = grafana.IntFromEnv("PROM_DEFAULT_LIMIT", DefaultLimit)
+
+// maxRange caps how long a time range queries may cover. Set with PROM_MAX_RANGE (e.g., "720h");
+// unset means no limit.
+var maxRange = grafana.NewRangeLimit("PROM_MAX_RANGE")
+
 // client provides methods for interacting with Prometheus via Grafana's datasource proxy.
 type client struct {
 	httpClient *http.Client
 	baseURL    string
+	headers    http.Header // Extra headers sent with every request, e.g., X-Scope-OrgID
+
+	datasourceUID string // Resolved UID, after falling back to the default datasource
 }
 
 // newClient creates a new Prometheus client for the given datasource UID.
-func newClient(datasourceUID string) (*client, error) {
+func newClient(ctx context.Context, datasourceUID string) (*client, error) {
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, "prometheus")
+	if err != nil {
+		return nil, err
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return nil, err
+	}
+
 	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
 	if err != nil {
 		return nil, err
@@ -36,41 +60,37 @@ func newClient(datasourceUID string) (*client, error) {
 
 	baseURL := fmt.Sprintf("%s/api/datasources/proxy/uid/%s", grafanaURL, datasourceUID)
 	return &client{
-		httpClient: httpClient,
-		baseURL:    baseURL,
+		httpClient:    httpClient,
+		baseURL:       baseURL,
+		datasourceUID: datasourceUID,
 	}, nil
 }
 
 // makeRequest performs an HTTP request and returns the response body.
 func (c *client) makeRequest(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
-	reqURL := c.baseURL + path
-	if len(params) > 0 {
-		reqURL += "?" + params.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
+	statusCode, bodyBytes, err := c.doRequest(ctx, method, path, params)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
 	}
 
 	return bodyBytes, nil
 }
 
+// doRequest performs an HTTP request and returns the status code and body without
+// treating non-200 responses as errors, for callers that interpret the status themselves.
+func (c *client) doRequest(ctx context.Context, method, path string, params url.Values) (int, []byte, error) {
+	return grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method: method,
+		URL:    c.baseURL + path,
+		Params: params,
+		Header: c.headers,
+	})
+}
+
 // response represents the standard Prometheus API response wrapper.
 type response struct {
 	Status string          `json:"status"`
@@ -93,8 +113,12 @@ func parseResponse(bodyBytes []byte) (json.RawMessage, error) {
 }
 
 // fetchLabels fetches label names from Prometheus.
-func (c *client) fetchLabels(ctx context.Context, startRFC3339, endRFC3339 string) ([]string, error) {
+// Optional match selectors restrict the names to labels present on series matching any of them.
+func (c *client) fetchLabels(ctx context.Context, startRFC3339, endRFC3339 string, match []string) ([]string, error) {
 	params := url.Values{}
+	for _, selector := range match {
+		params.Add("match[]", selector)
+	}
 
 	if startRFC3339 != "" {
 		startTime, err := time.Parse(time.RFC3339, startRFC3339)
@@ -131,8 +155,12 @@ func (c *client) fetchLabels(ctx context.Context, startRFC3339, endRFC3339 strin
 }
 
 // fetchLabelValues fetches values for a specific label from Prometheus.
-func (c *client) fetchLabelValues(ctx context.Context, labelName, startRFC3339, endRFC3339 string) ([]string, error) {
+// Optional match selectors restrict the values to series matching any of them.
+func (c *client) fetchLabelValues(ctx context.Context, labelName, startRFC3339, endRFC3339 string, match []string) ([]string, error) {
 	params := url.Values{}
+	for _, selector := range match {
+		params.Add("match[]", selector)
+	}
 
 	if startRFC3339 != "" {
 		startTime, err := time.Parse(time.RFC3339, startRFC3339)
@@ -171,8 +199,52 @@ func (c *client) fetchLabelValues(ctx context.Context, labelName, startRFC3339,
 
 // QueryResult represents a query result from Prometheus.
 type QueryResult struct {
-	ResultType string `json:"resultType"`
-	Result     any    `json:"result"`
+	ResultType string   `json:"resultType"`
+	Result     any      `json:"result"`
+	Warnings   []string `json:"warnings,omitempty"` // Added locally, e.g., about the step of a subquery range query
+}
+
+// SimpleValue is a scalar or string result reduced from Prometheus' [unixSeconds, "value"] pair.
+// Scalar values are numbers, except NaN and ±Inf, which JSON cannot represent and stay strings.
+type SimpleValue struct {
+	Value     any     `json:"value"`
+	Timestamp float64 `json:"timestamp"` // Unix seconds
+}
+
+// simplify replaces a scalar or string result's [unixSeconds, "value"] pair with a SimpleValue.
+// Vector and matrix results, and pairs in an unexpected shape, are left untouched.
+func (r *QueryResult) simplify() {
+	if r.ResultType != "scalar" && r.ResultType != "string" {
+		return
+	}
+	pair, ok := r.Result.([]any)
+	if !ok || len(pair) != 2 {
+		return
+	}
+	ts, ok := pair[0].(float64)
+	raw, ok2 := pair[1].(string)
+	if !ok || !ok2 {
+		return
+	}
+
+	value := SimpleValue{Value: raw, Timestamp: ts}
+	if r.ResultType == "scalar" {
+		if f, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			value.Value = f
+		}
+	}
+	r.Result = value
+}
+
+// resultCount returns the number of series in a vector or matrix result, or 1 for scalar and string results.
+func (r *QueryResult) resultCount() int {
+	if series, ok := r.Result.([]any); ok {
+		return len(series)
+	}
+	if r.Result == nil {
+		return 0
+	}
+	return 1
 }
 
 // query executes a PromQL query against Prometheus.
@@ -202,6 +274,7 @@ func (c *client) query(ctx context.Context, expr string, timeRFC3339 string) (*Q
 	if err := json.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("unmarshalling query result: %w", err)
 	}
+	result.simplify()
 
 	return &result, nil
 }
@@ -243,22 +316,42 @@ func (c *client) queryRange(ctx context.Context, expr, startRFC3339, endRFC3339
 	return &result, nil
 }
 
-// getDefaultTimeRange returns default start/end times if not specified (last 1 hour).
+// getDefaultTimeRange returns default start/end times if not specified (last defaultWindow, 1 hour unless overridden).
 func getDefaultTimeRange(startRFC3339, endRFC3339 string) (string, string) {
 	now := time.Now().UTC()
 	if endRFC3339 == "" {
 		endRFC3339 = now.Format(time.RFC3339)
 	}
 	if startRFC3339 == "" {
-		startRFC3339 = now.Add(-1 * time.Hour).Format(time.RFC3339)
+		startRFC3339 = now.Add(-defaultWindow).Format(time.RFC3339)
 	}
 	return startRFC3339, endRFC3339
 }
 
+// alignTimeRange rounds RFC3339 start and end times down to multiples of the step since the
+// Unix epoch, as Grafana does for range queries, so samples fall on the same timestamps.
+func alignTimeRange(startRFC3339, endRFC3339 string, stepSeconds int) (string, string, error) {
+	start, err := time.Parse(time.RFC3339, startRFC3339)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing start time: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, endRFC3339)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing end time: %w", err)
+	}
+
+	step := int64(stepSeconds)
+	align := func(t time.Time) string {
+		unix := t.Unix()
+		return time.Unix(unix-((unix%step)+step)%step, 0).UTC().Format(time.RFC3339)
+	}
+	return align(start), align(end), nil
+}
+
 // enforceLimit ensures the limit doesn't exceed the maximum.
 func enforceLimit(requestedLimit, maxLimit int) int {
 	if requestedLimit <= 0 {
-		return DefaultLimit
+		return defaultLimit
 	}
 	if maxLimit > 0 && requestedLimit > maxLimit {
 		return maxLimit
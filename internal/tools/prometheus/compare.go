@@ -0,0 +1,232 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultCompareOffset is how far back compare_prometheus_query looks when no offset is given.
+const DefaultCompareOffset = "1w"
+
+type compareParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Expr          string `json:"expr"`
+	Offset        string `json:"offset,omitempty"`
+	TimeRFC3339   string `json:"timeRfc3339,omitempty"`
+}
+
+// ComparedSeries holds one series' value now and at the offset. Current or Past is nil when the
+// series only exists on one side, and "+Inf" or "-Inf" as a string when the value is infinite.
+// Change and PercentChange are nil when they cannot be computed (missing or zero past value) or
+// would not be finite.
+type ComparedSeries struct {
+	Labels        map[string]string `json:"labels"`
+	Current       any               `json:"current"`
+	Past          any               `json:"past"`
+	Change        *float64          `json:"change,omitempty"`
+	PercentChange *float64          `json:"percentChange,omitempty"`
+}
+
+// CompareResult is the outcome of comparing an instant query with itself at an offset.
+type CompareResult struct {
+	Expr     string           `json:"expr"`
+	PastExpr string           `json:"pastExpr"`
+	Offset   string           `json:"offset"`
+	Series   []ComparedSeries `json:"series"`
+}
+
+func compareHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params compareParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Expr == "" {
+		return mcp.NewToolResultError("expr (PromQL expression) is required"), nil
+	}
+
+	offset := params.Offset
+	if offset == "" {
+		offset = DefaultCompareOffset
+	}
+
+	modifiers, err := buildModifiers(offset, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pastExpr, err := applyModifiers(params.Expr, modifiers)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
+	}
+
+	current, err := c.query(ctx, params.Expr, params.TimeRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("executing current query: %v", err)), nil
+	}
+	past, err := c.query(ctx, pastExpr, params.TimeRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("executing past query: %v", err)), nil
+	}
+
+	for _, r := range []*QueryResult{current, past} {
+		if r.ResultType != "vector" {
+			return mcp.NewToolResultError(fmt.Sprintf("expected a vector result, got %s", r.ResultType)), nil
+		}
+	}
+
+	result := CompareResult{
+		Expr:     params.Expr,
+		PastExpr: pastExpr,
+		Offset:   offset,
+		Series:   compareVectors(current, past),
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(result, len(result.Series)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// compareVectors pairs series from two vector results by label set, ordered by the size of
+// the relative change (largest first), with series lacking a percent change last.
+func compareVectors(current, past *QueryResult) []ComparedSeries {
+	type pair struct {
+		labels        map[string]string
+		current, past *float64
+	}
+	byKey := map[string]*pair{}
+	var order []string
+
+	collect := func(r *QueryResult, isCurrent bool) {
+		series, _ := r.Result.([]any)
+		for _, s := range series {
+			value, ok := vectorValue(s)
+			if !ok {
+				continue
+			}
+			labels := seriesLabels(s)
+			key := labelKey(labels)
+			entry, exists := byKey[key]
+			if !exists {
+				entry = &pair{labels: labels}
+				byKey[key] = entry
+				order = append(order, key)
+			}
+			if isCurrent {
+				entry.current = &value
+			} else {
+				entry.past = &value
+			}
+		}
+	}
+	collect(current, true)
+	collect(past, false)
+
+	compared := make([]ComparedSeries, 0, len(order))
+	for _, key := range order {
+		entry := byKey[key]
+		series := ComparedSeries{Labels: entry.labels}
+		if entry.current != nil {
+			series.Current = jsonValue(*entry.current)
+		}
+		if entry.past != nil {
+			series.Past = jsonValue(*entry.past)
+		}
+		if entry.current != nil && entry.past != nil {
+			change := *entry.current - *entry.past
+			if isFinite(change) {
+				series.Change = &change
+			}
+			if *entry.past != 0 {
+				if pct := change / math.Abs(*entry.past) * 100; isFinite(pct) {
+					series.PercentChange = &pct
+				}
+			}
+		}
+		compared = append(compared, series)
+	}
+
+	sort.SliceStable(compared, func(i, j int) bool {
+		pi, pj := compared[i].PercentChange, compared[j].PercentChange
+		if pi == nil || pj == nil {
+			return pi != nil && pj == nil
+		}
+		return math.Abs(*pi) > math.Abs(*pj)
+	})
+
+	return compared
+}
+
+// isFinite reports whether v is neither NaN nor ±Inf.
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// seriesLabels extracts the label set of a vector or matrix series.
+func seriesLabels(s any) map[string]string {
+	labels := map[string]string{}
+	if m, ok := s.(map[string]any); ok {
+		if metric, ok := m["metric"].(map[string]any); ok {
+			for name, v := range metric {
+				labels[name] = fmt.Sprint(v)
+			}
+		}
+	}
+	return labels
+}
+
+// labelKey renders a label set as a stable string for matching series across results.
+func labelKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%q,", name, labels[name])
+	}
+	return b.String()
+}
+
+func newCompareTool() mcp.Tool {
+	return mcp.NewTool(
+		"compare_prometheus_query",
+		mcp.WithDescription("Compares an instant PromQL query with the same query at an offset (default one week earlier), "+
+			"e.g., to answer 'is this metric up or down versus last week'. "+
+			"Returns, per series, the current value, the past value, the absolute change, and the percent change, "+
+			"ordered by the largest relative change. Series present on only one side have a null current or past value."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Prometheus datasource to query; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
+		),
+		mcp.WithString("expr",
+			mcp.Description("PromQL expression to compare, without an offset or @ modifier (e.g., 'sum by (job) (rate(http_requests_total[5m]))')"),
+			mcp.Required(),
+		),
+		mcp.WithString("offset",
+			mcp.Description(fmt.Sprintf("How far back to compare against, as a Prometheus duration (e.g., '1d', '1w', default: %s)", DefaultCompareOffset)),
+		),
+		mcp.WithString("timeRfc3339",
+			mcp.Description("Evaluation time in RFC3339 format (defaults to now); the past value is taken at this time minus the offset"),
+		),
+	)
+}
+
+// RegisterCompare registers the compare_prometheus_query tool.
+func RegisterCompare(s grafana.ToolRegistry) {
+	s.AddTool(newCompareTool(), compareHandler)
+}
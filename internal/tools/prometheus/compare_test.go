@@ -0,0 +1,60 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+func TestCompareVectorsNonFinite(t *testing.T) {
+	const current = `{"resultType":"vector","result":[
+		{"metric":{"job":"finite"},"value":[1700000000,"150"]},
+		{"metric":{"job":"zero-past"},"value":[1700000000,"5"]},
+		{"metric":{"job":"inf-current"},"value":[1700000000,"+Inf"]},
+		{"metric":{"job":"inf-both"},"value":[1700000000,"+Inf"]},
+		{"metric":{"job":"overflow"},"value":[1700000000,"1e308"]}
+	]}`
+	const past = `{"resultType":"vector","result":[
+		{"metric":{"job":"finite"},"value":[1699395200,"100"]},
+		{"metric":{"job":"zero-past"},"value":[1699395200,"0"]},
+		{"metric":{"job":"inf-current"},"value":[1699395200,"10"]},
+		{"metric":{"job":"inf-both"},"value":[1699395200,"+Inf"]},
+		{"metric":{"job":"overflow"},"value":[1699395200,"1e-10"]}
+	]}`
+
+	var currentResult, pastResult QueryResult
+	if err := json.Unmarshal([]byte(current), &currentResult); err != nil {
+		t.Fatalf("unmarshalling current result: %v", err)
+	}
+	if err := json.Unmarshal([]byte(past), &pastResult); err != nil {
+		t.Fatalf("unmarshalling past result: %v", err)
+	}
+
+	compared := compareVectors(&currentResult, &pastResult)
+
+	if _, err := grafana.MarshalJSON(compared); err != nil {
+		t.Fatalf("marshalling compared series: %v", err)
+	}
+
+	byJob := map[string]ComparedSeries{}
+	for _, series := range compared {
+		byJob[series.Labels["job"]] = series
+	}
+
+	if got := byJob["finite"]; got.Change == nil || *got.Change != 50 || got.PercentChange == nil || *got.PercentChange != 50 {
+		t.Errorf("finite = %+v, want change 50 and percentChange 50", got)
+	}
+	if got := byJob["zero-past"]; got.Change == nil || *got.Change != 5 || got.PercentChange != nil {
+		t.Errorf("zero-past = %+v, want change 5 and no percentChange", got)
+	}
+	if got := byJob["inf-current"]; got.Current != "+Inf" || got.Past != 10.0 || got.Change != nil || got.PercentChange != nil {
+		t.Errorf("inf-current = %+v, want current +Inf, past 10, and no change", got)
+	}
+	if got := byJob["inf-both"]; got.Current != "+Inf" || got.Past != "+Inf" || got.Change != nil || got.PercentChange != nil {
+		t.Errorf("inf-both = %+v, want +Inf on both sides and no change", got)
+	}
+	if got := byJob["overflow"]; got.Change == nil || got.PercentChange != nil {
+		t.Errorf("overflow = %+v, want a change but no percentChange", got)
+	}
+}
@@ -2,18 +2,18 @@ package prometheus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listLabelNamesParams struct {
-	DatasourceUID string `json:"datasourceUid"`
-	StartRFC3339  string `json:"startRfc3339,omitempty"`
-	EndRFC3339    string `json:"endRfc3339,omitempty"`
-	Limit         int    `json:"limit,omitempty"`
+	DatasourceUID string   `json:"datasourceUid"`
+	StartRFC3339  string   `json:"startRfc3339,omitempty"`
+	EndRFC3339    string   `json:"endRfc3339,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
+	Match         []string `json:"match,omitempty"`
 }
 
 func listLabelNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -22,28 +22,26 @@ func listLabelNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
 	}
 
 	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
-	labels, err := c.fetchLabels(ctx, startTime, endTime)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-
-	// Apply limit
-	limit := enforceLimit(params.Limit, 0)
-	if len(labels) > limit {
-		labels = labels[:limit]
+	labels, err := c.fetchLabels(ctx, startTime, endTime, params.Match)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	if len(labels) == 0 {
 		labels = []string{}
 	}
 
-	jsonData, err := json.MarshalIndent(labels, "", "  ")
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(grafana.LimitList(labels, enforceLimit(params.Limit, 0)), notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -56,24 +54,29 @@ func newListLabelNamesTool() mcp.Tool {
 		"list_prometheus_label_names",
 		mcp.WithDescription("Lists all available label names in a Prometheus datasource. "+
 			"Returns a list of unique label strings (e.g., [\"__name__\", \"instance\", \"job\"]). "+
-			"Defaults to the last hour if time range is not specified."),
+			"If the limit cut the list short, returns {values, _truncated: true, _totalBeforeLimit} instead. "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Prometheus datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Prometheus datasource to query; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
+		),
+		mcp.WithArray("match",
+			mcp.Description("Optional series selectors that scope the names to labels present on matching series "+
+				"(e.g., [\"http_requests_total\"] to see what a metric can be grouped by)"),
+			mcp.Items(map[string]any{"type": "string"}),
 		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
 		),
 		mcp.WithNumber("limit",
-			mcp.Description("Maximum number of label names to return (default: 100)"),
+			mcp.Description(fmt.Sprintf("Maximum number of label names to return (default: %d)", defaultLimit)),
 		),
 	)
 }
 
 // RegisterListLabelNames registers the list_prometheus_label_names tool.
-func RegisterListLabelNames(s *server.MCPServer) {
+func RegisterListLabelNames(s grafana.ToolRegistry) {
 	s.AddTool(newListLabelNamesTool(), listLabelNamesHandler)
 }
@@ -2,19 +2,27 @@ package prometheus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listLabelValuesParams struct {
-	DatasourceUID string `json:"datasourceUid"`
-	LabelName     string `json:"labelName"`
-	StartRFC3339  string `json:"startRfc3339,omitempty"`
-	EndRFC3339    string `json:"endRfc3339,omitempty"`
-	Limit         int    `json:"limit,omitempty"`
+	DatasourceUID string   `json:"datasourceUid"`
+	LabelName     string   `json:"labelName"`
+	StartRFC3339  string   `json:"startRfc3339,omitempty"`
+	EndRFC3339    string   `json:"endRfc3339,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
+	WithCount     bool     `json:"withCount,omitempty"`
+	Match         []string `json:"match,omitempty"`
+}
+
+// LabelValuesResult is the output of list_prometheus_label_values when withCount is set.
+type LabelValuesResult struct {
+	TotalCount int      `json:"totalCount"`
+	Truncated  bool     `json:"truncated"`
+	Values     []string `json:"values"`
 }
 
 func listLabelValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -27,28 +35,41 @@ func listLabelValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultError("labelName is required"), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
 	}
 
 	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
-	values, err := c.fetchLabelValues(ctx, params.LabelName, startTime, endTime)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-
-	// Apply limit
-	limit := enforceLimit(params.Limit, 0)
-	if len(values) > limit {
-		values = values[:limit]
+	values, err := c.fetchLabelValues(ctx, params.LabelName, startTime, endTime, params.Match)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	if len(values) == 0 {
 		values = []string{}
 	}
 
-	jsonData, err := json.MarshalIndent(values, "", "  ")
+	limit := enforceLimit(params.Limit, 0)
+	output := grafana.LimitList(values, limit)
+	if params.WithCount {
+		// Count before applying the limit so cardinality is reported accurately
+		totalCount := len(values)
+		if len(values) > limit {
+			values = values[:limit]
+		}
+		output = LabelValuesResult{
+			TotalCount: totalCount,
+			Truncated:  totalCount > len(values),
+			Values:     values,
+		}
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(output, notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -61,28 +82,36 @@ func newListLabelValuesTool() mcp.Tool {
 		"list_prometheus_label_values",
 		mcp.WithDescription("Retrieves all unique values for a specific label name in a Prometheus datasource. "+
 			"Returns a list of string values (e.g., for labelName=\"job\", might return [\"prometheus\", \"node-exporter\"]). "+
-			"Use __name__ as the label name to get all metric names. Defaults to the last hour if time range is not specified."),
+			"If the limit cut the list short, returns {values, _truncated: true, _totalBeforeLimit} instead. "+
+			"Use __name__ as the label name to get all metric names. Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Prometheus datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Prometheus datasource to query; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
 		),
 		mcp.WithString("labelName",
 			mcp.Description("The label name to get values for (e.g., \"job\", \"instance\", or \"__name__\" for metric names)"),
 			mcp.Required(),
 		),
+		mcp.WithArray("match",
+			mcp.Description("Optional series selectors that scope the values to matching series (e.g., [\"{job=\\\"api\\\"}\"]). "+
+				"Strongly recommended on high-cardinality labels such as instance or pod."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
 		),
 		mcp.WithNumber("limit",
-			mcp.Description("Maximum number of values to return (default: 100)"),
+			mcp.Description(fmt.Sprintf("Maximum number of values to return (default: %d)", defaultLimit)),
+		),
+		mcp.WithBoolean("withCount",
+			mcp.Description("Return {totalCount, truncated, values} instead of a bare array, where totalCount is the number of values before the limit is applied (default: false)"),
 		),
 	)
 }
 
 // RegisterListLabelValues registers the list_prometheus_label_values tool.
-func RegisterListLabelValues(s *server.MCPServer) {
+func RegisterListLabelValues(s grafana.ToolRegistry) {
 	s.AddTool(newListLabelValuesTool(), listLabelValuesHandler)
 }
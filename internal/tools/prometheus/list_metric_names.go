@@ -2,12 +2,10 @@ package prometheus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"regexp"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listMetricNamesParams struct {
@@ -24,46 +22,36 @@ func listMetricNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
 	}
 
 	startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	// Fetch all metric names using __name__ label
-	metricNames, err := c.fetchLabelValues(ctx, "__name__", startTime, endTime)
+	metricNames, err := c.fetchLabelValues(ctx, "__name__", startTime, endTime, nil)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	// Filter by regex if provided
 	if params.Regex != "" {
-		re, err := regexp.Compile(params.Regex)
+		metricNames, err = grafana.FilterByRegex(metricNames, params.Regex)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid regex: %v", err)), nil
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-
-		filtered := make([]string, 0)
-		for _, name := range metricNames {
-			if re.MatchString(name) {
-				filtered = append(filtered, name)
-			}
-		}
-		metricNames = filtered
-	}
-
-	// Apply limit
-	limit := enforceLimit(params.Limit, 0)
-	if len(metricNames) > limit {
-		metricNames = metricNames[:limit]
 	}
 
 	if len(metricNames) == 0 {
 		metricNames = []string{}
 	}
 
-	jsonData, err := json.MarshalIndent(metricNames, "", "  ")
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(grafana.LimitList(metricNames, enforceLimit(params.Limit, 0)), notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -77,27 +65,27 @@ func newListMetricNamesTool() mcp.Tool {
 		mcp.WithDescription("Lists metric names in a Prometheus datasource. "+
 			"Returns a list of metric names (e.g., [\"up\", \"node_cpu_seconds_total\"]). "+
 			"Supports filtering by regex pattern. "+
-			"Defaults to the last hour if time range is not specified."),
+			"If the limit cut the list short, returns {values, _truncated: true, _totalBeforeLimit} instead. "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Prometheus datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Prometheus datasource to query; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
 		),
 		mcp.WithString("regex",
 			mcp.Description("Optional regex pattern to filter metric names (e.g., \"node_.*\" for node exporter metrics)"),
 		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
 		),
 		mcp.WithNumber("limit",
-			mcp.Description("Maximum number of metric names to return (default: 100)"),
+			mcp.Description(fmt.Sprintf("Maximum number of metric names to return (default: %d)", defaultLimit)),
 		),
 	)
 }
 
 // RegisterListMetricNames registers the list_prometheus_metric_names tool.
-func RegisterListMetricNames(s *server.MCPServer) {
+func RegisterListMetricNames(s grafana.ToolRegistry) {
 	s.AddTool(newListMetricNamesTool(), listMetricNamesHandler)
 }
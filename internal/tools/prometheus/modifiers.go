@@ -0,0 +1,204 @@
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// promDurationPattern matches a Prometheus duration such as "1w", "90m", or "1h30m".
+var promDurationPattern = regexp.MustCompile(`^(\d+(ms|s|m|h|d|w|y))+$`)
+
+// aggregationOperators can take a by/without clause before their argument list.
+var aggregationOperators = map[string]bool{
+	"sum": true, "min": true, "max": true, "avg": true, "group": true, "stddev": true, "stdvar": true,
+	"count": true, "count_values": true, "bottomk": true, "topk": true, "quantile": true,
+	"limitk": true, "limit_ratio": true,
+}
+
+// keywords are identifiers that are never metric names.
+var keywords = map[string]bool{
+	"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+	"bool": true, "and": true, "or": true, "unless": true, "offset": true, "atan2": true,
+	"inf": true, "nan": true,
+}
+
+// labelListKeywords are followed by a parenthesized list of label names rather than an expression.
+var labelListKeywords = map[string]bool{
+	"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+}
+
+// buildModifiers validates the offset and atTimestamp params and renders them as PromQL modifiers.
+// atTimestamp accepts RFC3339 or Unix seconds. Returns "" when neither is set.
+func buildModifiers(offset, atTimestamp string) (string, error) {
+	var parts []string
+
+	if atTimestamp != "" {
+		at := atTimestamp
+		if t, err := time.Parse(time.RFC3339, atTimestamp); err == nil {
+			at = strconv.FormatInt(t.Unix(), 10)
+		} else if _, err := strconv.ParseFloat(atTimestamp, 64); err != nil {
+			return "", fmt.Errorf("invalid atTimestamp %q (expected RFC3339 or Unix seconds)", atTimestamp)
+		}
+		parts = append(parts, "@ "+at)
+	}
+
+	if offset != "" {
+		d := strings.TrimPrefix(offset, "-")
+		if !promDurationPattern.MatchString(d) {
+			return "", fmt.Errorf("invalid offset %q (expected a duration like 1h, 1d, or 1w)", offset)
+		}
+		parts = append(parts, "offset "+offset)
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// applyModifiers appends modifiers (e.g., "@ 1700000000 offset 1w") to every vector and range selector in expr.
+// Expressions that already use offset or @ are rejected rather than combined.
+func applyModifiers(expr, modifiers string) (string, error) {
+	if modifiers == "" {
+		return expr, nil
+	}
+
+	runes := []rune(expr)
+	var out strings.Builder
+	skipLabelList := false
+
+	// copyUntil copies runes up to and including the matching close bracket, honouring quoted strings.
+	copyUntil := func(i int, closer rune) int {
+		for i < len(runes) {
+			r := runes[i]
+			out.WriteRune(r)
+			i++
+			if r == '"' || r == '\'' || r == '`' {
+				i = copyString(runes, i, r, &out)
+				continue
+			}
+			if r == closer {
+				break
+			}
+		}
+		return i
+	}
+
+	// copySelectorTail copies an optional {matchers} and [range] following a selector start, then the modifiers.
+	copySelectorTail := func(i int) int {
+		j := skipSpaces(runes, i)
+		if j < len(runes) && runes[j] == '{' {
+			out.WriteString(string(runes[i:j]))
+			i = copyUntil(j, '}')
+		}
+		j = skipSpaces(runes, i)
+		if j < len(runes) && runes[j] == '[' {
+			out.WriteString(string(runes[i:j]))
+			i = copyUntil(j, ']')
+		}
+		out.WriteString(" " + modifiers)
+		return i
+	}
+
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			out.WriteRune(r)
+			i = copyString(runes, i+1, r, &out)
+
+		case r == '@':
+			return "", fmt.Errorf("expr already contains an @ modifier; remove it or omit atTimestamp and offset")
+
+		case r == '{':
+			// A selector without a metric name, e.g., {__name__=~"http_.*"}
+			i = copySelectorTail(i)
+
+		case r == '[':
+			// Subquery range after a parenthesized expression; its inner selectors carry the modifiers
+			i = copyUntil(i, ']')
+
+		case r == '(' && skipLabelList:
+			skipLabelList = false
+			i = copyUntil(i, ')')
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			// Number literal, including exponents and durations
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				out.WriteRune(runes[i])
+				i++
+			}
+
+		case unicode.IsLetter(r) || r == '_' || r == ':':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == ':') {
+				i++
+			}
+			ident := string(runes[start:i])
+			out.WriteString(ident)
+			lower := strings.ToLower(ident)
+
+			if lower == "offset" {
+				return "", fmt.Errorf("expr already contains an offset modifier; remove it or omit atTimestamp and offset")
+			}
+
+			next := skipSpaces(runes, i)
+			nextIdent := ""
+			if next < len(runes) && (unicode.IsLetter(runes[next]) || runes[next] == '_') {
+				end := next
+				for end < len(runes) && (unicode.IsLetter(runes[end]) || runes[end] == '_') {
+					end++
+				}
+				nextIdent = strings.ToLower(string(runes[next:end]))
+			}
+
+			skipLabelList = false
+			switch {
+			case labelListKeywords[lower]:
+				skipLabelList = true
+			case keywords[lower]:
+			case next < len(runes) && runes[next] == '(':
+				// Function call or aggregation
+			case aggregationOperators[lower] && (nextIdent == "by" || nextIdent == "without"):
+			default:
+				i = copySelectorTail(i)
+			}
+
+		default:
+			out.WriteRune(r)
+			i++
+		}
+	}
+
+	return out.String(), nil
+}
+
+// copyString copies a quoted string body starting after its opening quote, returning the index after the closing quote.
+func copyString(runes []rune, i int, quote rune, out *strings.Builder) int {
+	for i < len(runes) {
+		r := runes[i]
+		out.WriteRune(r)
+		i++
+		if r == '\\' && quote != '`' && i < len(runes) {
+			out.WriteRune(runes[i])
+			i++
+			continue
+		}
+		if r == quote {
+			break
+		}
+	}
+	return i
+}
+
+// skipSpaces returns the index of the next non-whitespace rune at or after i.
+func skipSpaces(runes []rune, i int) int {
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return i
+}
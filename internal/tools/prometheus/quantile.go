@@ -0,0 +1,190 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultRateWindow is the rate() window used by query_prometheus_quantile when none is given.
+const DefaultRateWindow = "5m"
+
+var (
+	// metricNamePattern matches a valid Prometheus metric name.
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+	// labelNamePattern matches a valid Prometheus label name.
+	labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Histogram types detected by query_prometheus_quantile.
+const (
+	histogramClassic = "classic"
+	histogramNative  = "native"
+)
+
+type quantileParams struct {
+	DatasourceUID string   `json:"datasourceUid"`
+	Metric        string   `json:"metric"`
+	Quantile      float64  `json:"quantile"`
+	GroupBy       []string `json:"groupBy,omitempty"`
+	Matchers      string   `json:"matchers,omitempty"`
+	RateWindow    string   `json:"rateWindow,omitempty"`
+	TimeRFC3339   string   `json:"timeRfc3339,omitempty"`
+}
+
+// QuantileResult is the output of query_prometheus_quantile.
+type QuantileResult struct {
+	Expr          string       `json:"expr"`
+	HistogramType string       `json:"histogramType"`
+	Result        *QueryResult `json:"result"`
+}
+
+// detectHistogramType checks which series exist for a histogram: native histograms are stored under the
+// base name, classic ones as <name>_bucket. Native wins when a metric is exposed both ways.
+func (c *client) detectHistogramType(ctx context.Context, metric string) (string, error) {
+	startTime, endTime := getDefaultTimeRange("", "")
+	match := []string{fmt.Sprintf(`{__name__=~"%s|%s_bucket"}`, metric, metric)}
+
+	names, err := c.fetchLabelValues(ctx, "__name__", startTime, endTime, match)
+	if err != nil {
+		return "", err
+	}
+
+	histogramType := ""
+	for _, name := range names {
+		switch name {
+		case metric:
+			return histogramNative, nil
+		case metric + "_bucket":
+			histogramType = histogramClassic
+		}
+	}
+	if histogramType == "" {
+		return "", fmt.Errorf("no %s or %s_bucket series found in the last %s", metric, metric, grafana.FormatDuration(defaultWindow))
+	}
+	return histogramType, nil
+}
+
+// buildQuantileExpr constructs the histogram_quantile expression for a classic or native histogram.
+func buildQuantileExpr(histogramType, metric string, quantile float64, groupBy []string, matchers, rateWindow string) string {
+	series := metric
+	var by []string
+	if histogramType == histogramClassic {
+		series += "_bucket"
+		by = append(by, "le")
+	}
+	by = append(by, groupBy...)
+
+	selector := fmt.Sprintf("%s{%s}", series, matchers)
+	if matchers == "" {
+		selector = series
+	}
+
+	sum := fmt.Sprintf("sum(rate(%s[%s]))", selector, rateWindow)
+	if len(by) > 0 {
+		sum = fmt.Sprintf("sum by (%s) (rate(%s[%s]))", strings.Join(by, ", "), selector, rateWindow)
+	}
+
+	return fmt.Sprintf("histogram_quantile(%g, %s)", quantile, sum)
+}
+
+func quantileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params quantileParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	metric := strings.TrimSuffix(params.Metric, "_bucket")
+	if !metricNamePattern.MatchString(metric) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid metric name %q", params.Metric)), nil
+	}
+	if params.Quantile < 0 || params.Quantile > 1 {
+		return mcp.NewToolResultError("quantile must be between 0 and 1 (e.g., 0.99 for p99)"), nil
+	}
+	for _, label := range params.GroupBy {
+		if !labelNamePattern.MatchString(label) || label == "le" {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid groupBy label %q", label)), nil
+		}
+	}
+
+	rateWindow := params.RateWindow
+	if rateWindow == "" {
+		rateWindow = DefaultRateWindow
+	}
+	if _, ok := parsePromDuration(rateWindow); !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid rateWindow %q (expected a duration like 5m)", rateWindow)), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
+	}
+
+	histogramType, err := c.detectHistogramType(ctx, metric)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("detecting histogram type: %v", err)), nil
+	}
+
+	matchers := strings.Trim(strings.TrimSpace(params.Matchers), "{}")
+	expr := buildQuantileExpr(histogramType, metric, params.Quantile, params.GroupBy, matchers, rateWindow)
+
+	result, err := c.query(ctx, expr, params.TimeRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("executing instant query: %v", err)), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(QuantileResult{
+		Expr:          expr,
+		HistogramType: histogramType,
+		Result:        result,
+	}, result.resultCount()))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newQuantileTool() mcp.Tool {
+	return mcp.NewTool(
+		"query_prometheus_quantile",
+		mcp.WithDescription("Computes a latency (or other) quantile from a Prometheus histogram, e.g., p99 request duration. "+
+			"Builds and runs histogram_quantile(q, sum by (le, ...) (rate(<metric>_bucket[5m]))) with the le grouping handled for you, "+
+			"or the simpler native-histogram form when the metric is a native histogram. "+
+			"Returns the expression that was run (reuse it in query_prometheus for a range graph), the detected histogram type, and the result."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Prometheus datasource to query; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
+		),
+		mcp.WithString("metric",
+			mcp.Description("Base histogram metric name, with or without the _bucket suffix (e.g., 'http_request_duration_seconds')"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("quantile",
+			mcp.Description("Quantile between 0 and 1 (e.g., 0.5, 0.95, 0.99)"),
+			mcp.Required(),
+		),
+		mcp.WithArray("groupBy",
+			mcp.Description("Labels to compute a separate quantile for, in addition to le (e.g., [\"service\", \"route\"])"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("matchers",
+			mcp.Description("Label matchers restricting the series (e.g., 'job=\"api\",code=~\"2..\"')"),
+		),
+		mcp.WithString("rateWindow",
+			mcp.Description(fmt.Sprintf("Window for rate() as a Prometheus duration (default: %s)", DefaultRateWindow)),
+		),
+		mcp.WithString("timeRfc3339",
+			mcp.Description("Evaluation time in RFC3339 format (defaults to now)"),
+		),
+	)
+}
+
+// RegisterQuantile registers the query_prometheus_quantile tool.
+func RegisterQuantile(s grafana.ToolRegistry) {
+	s.AddTool(newQuantileTool(), quantileHandler)
+}
@@ -2,21 +2,30 @@ package prometheus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type queryParams struct {
-	DatasourceUID string `json:"datasourceUid"`
-	Expr          string `json:"expr"`
-	QueryType     string `json:"queryType,omitempty"`    // "instant" or "range", defaults to "instant"
-	TimeRFC3339   string `json:"timeRfc3339,omitempty"`  // For instant queries
-	StartRFC3339  string `json:"startRfc3339,omitempty"` // For range queries
-	EndRFC3339    string `json:"endRfc3339,omitempty"`   // For range queries
-	StepSeconds   int    `json:"stepSeconds,omitempty"`  // For range queries
+	DatasourceUID  string            `json:"datasourceUid"`
+	Expr           string            `json:"expr"`
+	QueryType      string            `json:"queryType,omitempty"`    // "instant" or "range", defaults to "instant"
+	TimeRFC3339    string            `json:"timeRfc3339,omitempty"`  // For instant queries
+	StartRFC3339   string            `json:"startRfc3339,omitempty"` // For range queries
+	EndRFC3339     string            `json:"endRfc3339,omitempty"`   // For range queries
+	StepSeconds    int               `json:"stepSeconds,omitempty"`  // For range queries
+	AlignStep      *bool             `json:"alignStep,omitempty"`    // For range queries, defaults to true
+	Offset         string            `json:"offset,omitempty"`       // Appended as "offset <duration>" to every selector
+	AtTimestamp    string            `json:"atTimestamp,omitempty"`  // Appended as "@ <unix>" to every selector
+	Sort           string            `json:"sort,omitempty"`         // "valueAsc", "valueDesc", or "none"
+	SeriesNames    bool              `json:"seriesNames,omitempty"`
+	TimeoutSeconds int               `json:"timeoutSeconds,omitempty"`
+	PrecheckHealth *bool             `json:"precheckHealth,omitempty"`
+	DryRun         bool              `json:"dryRun,omitempty"`
+	TenantID       string            `json:"tenantId,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
 }
 
 func queryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -29,10 +38,37 @@ func queryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTo
 		return mcp.NewToolResultError("expr (PromQL expression) is required"), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	sortMode, err := validateSortMode(params.Sort)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	modifiers, err := buildModifiers(params.Offset, params.AtTimestamp)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	expr, err := applyModifiers(params.Expr, modifiers)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
 	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ctx, cancel := grafana.WithRequestTimeout(ctx, c.httpClient, params.TimeoutSeconds)
+	defer cancel()
+	if params.DryRun {
+		ctx = grafana.WithDryRun(ctx)
+	}
+
+	if err := grafana.PrecheckHealth(ctx, c.httpClient, c.datasourceUID, params.PrecheckHealth); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	queryType := params.QueryType
 	if queryType == "" {
@@ -43,29 +79,67 @@ func queryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTo
 
 	switch queryType {
 	case "instant":
-		result, err = c.query(ctx, params.Expr, params.TimeRFC3339)
+		result, err = c.query(ctx, expr, params.TimeRFC3339)
+		if params.DryRun {
+			return grafana.DryRunResult(ctx), nil
+		}
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("executing instant query: %v", err)), nil
 		}
 
 	case "range":
 		startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+		startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
+		// A subquery sets its own resolution, so default the step to it rather than DefaultStepSeconds
+		var warning string
 		stepSeconds := params.StepSeconds
+		if isSubquery, resolution := subqueryResolution(expr); isSubquery {
+			if stepSeconds > 0 {
+				warning = fmt.Sprintf("expr contains a subquery, which sets its own resolution; stepSeconds=%d also resamples it, "+
+					"so the graph may not reflect the subquery's resolution. Omit stepSeconds to step at the subquery resolution.", stepSeconds)
+			} else if resolution > 0 {
+				stepSeconds = max(int(resolution.Seconds()), 1)
+			}
+		}
 		if stepSeconds <= 0 {
 			stepSeconds = DefaultStepSeconds
 		}
 
-		result, err = c.queryRange(ctx, params.Expr, startTime, endTime, stepSeconds)
+		// Grafana aligns range queries to the step, so match it unless told otherwise
+		if params.AlignStep == nil || *params.AlignStep {
+			if startTime, endTime, err = alignTimeRange(startTime, endTime, stepSeconds); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		result, err = c.queryRange(ctx, expr, startTime, endTime, stepSeconds)
+		if params.DryRun {
+			return grafana.DryRunResult(ctx), nil
+		}
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("executing range query: %v", err)), nil
 		}
+		if warning != "" {
+			result.Warnings = append(result.Warnings, warning)
+		}
+		if notice != "" {
+			result.Warnings = append(result.Warnings, notice)
+		}
 
 	default:
 		return mcp.NewToolResultError(fmt.Sprintf("invalid queryType: %s (must be 'instant' or 'range')", queryType)), nil
 	}
 
-	jsonData, err := json.MarshalIndent(result, "", "  ")
+	sortResult(result, sortMode)
+	if params.SeriesNames {
+		nameSeries(result)
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(result, result.resultCount()))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -73,6 +147,11 @@ func queryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTo
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
+// HandleQuery runs the query_prometheus tool handler; the unified query tool routes PromQL here.
+func HandleQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return queryHandler(ctx, request)
+}
+
 func newQueryTool() mcp.Tool {
 	return mcp.NewTool(
 		"query_prometheus",
@@ -80,10 +159,10 @@ func newQueryTool() mcp.Tool {
 			"Supports both instant queries (at a single point in time) and range queries (over a time range). "+
 			"For instant queries, optionally specify timeRfc3339. "+
 			"For range queries, set queryType='range' and optionally specify startRfc3339, endRfc3339, and stepSeconds. "+
-			"Returns the query result with resultType (vector, matrix, scalar, string) and result data."),
+			"Returns the query result with resultType (vector, matrix, scalar, string) and result data; "+
+			"scalar and string results are returned as {value, timestamp}, e.g., scalar(count(up)) gives {\"value\": 42, ...}."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Prometheus datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Prometheus datasource to query; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
 		),
 		mcp.WithString("expr",
 			mcp.Description("PromQL expression to evaluate (e.g., 'up', 'rate(http_requests_total[5m])')"),
@@ -96,18 +175,55 @@ func newQueryTool() mcp.Tool {
 			mcp.Description("Evaluation time for instant queries in RFC3339 format (defaults to now)"),
 		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time for range queries in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time for range queries in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time for range queries in RFC3339 format (defaults to now)"),
 		),
+		mcp.WithBoolean("alignStep",
+			mcp.Description("For range queries, round start and end down to multiples of the step, as Grafana panels do, "+
+				"so samples land on the same timestamps as the dashboard (default: true)"),
+		),
 		mcp.WithNumber("stepSeconds",
-			mcp.Description("Step interval for range queries in seconds (default: 60)"),
+			mcp.Description("Step interval for range queries in seconds (default: 60, or the subquery resolution when expr contains a subquery such as [1h:5m])"),
+		),
+		mcp.WithString("offset",
+			mcp.Description("Shift every selector back in time by this duration, appended as 'offset <duration>' (e.g., '1d', '1w' to compare with last week). "+
+				"Rejected if expr already contains an offset or @ modifier."),
+		),
+		mcp.WithString("atTimestamp",
+			mcp.Description("Pin every selector to this evaluation time, appended as '@ <unix>' (RFC3339 or Unix seconds). "+
+				"Rejected if expr already contains an offset or @ modifier."),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Order series by value: 'valueDesc' puts the highest first, 'valueAsc' the lowest, 'none' (default) keeps Prometheus' order. "+
+				"Vector results sort by sample value, matrix results by each series' last value."),
+		),
+		mcp.WithBoolean("seriesNames",
+			mcp.Description("Replace each series' metric labels map with a readable 'series' string such as "+
+				"'http_requests_total{code=\"200\",job=\"api\"}', as Prometheus and Grafana display it (default: false)"),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Instead of running the query, return the exact upstream request(s) it would send: "+
+				"method, URL, decoded params (including the converted start/end times), and headers with the token redacted (default: false)"),
+		),
+		mcp.WithBoolean("precheckHealth",
+			mcp.Description("Check the datasource health endpoint first and fail with a clear 'datasource is unhealthy' error instead of running the query "+
+				"(default: false, or true when GRAFANA_PRECHECK_HEALTH is set)"),
+		),
+		mcp.WithNumber("timeoutSeconds",
+			mcp.Description("Override the request timeout for this call, useful for heavy range queries in seconds (default: the server-wide timeout, max: 300)"),
 		),
 	)
 }
 
 // RegisterQuery registers the query_prometheus tool.
-func RegisterQuery(s *server.MCPServer) {
+func RegisterQuery(s grafana.ToolRegistry) {
 	s.AddTool(newQueryTool(), queryHandler)
 }
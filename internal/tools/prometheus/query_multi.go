@@ -0,0 +1,159 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MaxMultiDatasources is the maximum number of datasources query_prometheus_multi fans out to.
+const MaxMultiDatasources = 20
+
+type queryMultiParams struct {
+	DatasourceUIDs []string `json:"datasourceUids"`
+	Expr           string   `json:"expr"`
+	QueryType      string   `json:"queryType,omitempty"`
+	TimeRFC3339    string   `json:"timeRfc3339,omitempty"`
+	StartRFC3339   string   `json:"startRfc3339,omitempty"`
+	EndRFC3339     string   `json:"endRfc3339,omitempty"`
+	StepSeconds    int      `json:"stepSeconds,omitempty"`
+}
+
+// DatasourceResult is the outcome of a query against one datasource; exactly one of Result and Error is set.
+type DatasourceResult struct {
+	DatasourceUID string       `json:"datasourceUid"`
+	Result        *QueryResult `json:"result,omitempty"`
+	Error         string       `json:"error,omitempty"`
+}
+
+// runQuery executes an instant or range query against a single datasource.
+func runQuery(ctx context.Context, datasourceUID string, params queryMultiParams) (*QueryResult, error) {
+	c, err := newClient(ctx, datasourceUID)
+	if err != nil {
+		return nil, fmt.Errorf("creating Prometheus client: %w", err)
+	}
+
+	if params.QueryType == "range" {
+		startTime, endTime := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+		startTime, notice, err := maxRange.ClampRFC3339(startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+
+		stepSeconds := params.StepSeconds
+		if stepSeconds <= 0 {
+			stepSeconds = DefaultStepSeconds
+		}
+
+		result, err := c.queryRange(ctx, params.Expr, startTime, endTime, stepSeconds)
+		if err != nil {
+			return nil, fmt.Errorf("executing range query: %w", err)
+		}
+		if notice != "" {
+			result.Warnings = append(result.Warnings, notice)
+		}
+		return result, nil
+	}
+
+	result, err := c.query(ctx, params.Expr, params.TimeRFC3339)
+	if err != nil {
+		return nil, fmt.Errorf("executing instant query: %w", err)
+	}
+	return result, nil
+}
+
+func queryMultiHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params queryMultiParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Expr == "" {
+		return mcp.NewToolResultError("expr (PromQL expression) is required"), nil
+	}
+	if len(params.DatasourceUIDs) == 0 {
+		return mcp.NewToolResultError("datasourceUids must contain at least one datasource UID"), nil
+	}
+	if len(params.DatasourceUIDs) > MaxMultiDatasources {
+		return mcp.NewToolResultError(fmt.Sprintf("datasourceUids may contain at most %d datasource UIDs", MaxMultiDatasources)), nil
+	}
+
+	switch params.QueryType {
+	case "", "instant", "range":
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid queryType: %s (must be 'instant' or 'range')", params.QueryType)), nil
+	}
+
+	// One backend being down should not fail the whole call, so errors are reported per datasource
+	results := make([]DatasourceResult, len(params.DatasourceUIDs))
+	sem := make(chan struct{}, grafana.MaxConcurrency())
+	var wg sync.WaitGroup
+
+	for i, uid := range params.DatasourceUIDs {
+		results[i].DatasourceUID = uid
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result, err := runQuery(ctx, uid, params)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Result = result
+		}()
+	}
+	wg.Wait()
+
+	jsonData, err := grafana.MarshalJSON(results)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newQueryMultiTool() mcp.Tool {
+	return mcp.NewTool(
+		"query_prometheus_multi",
+		mcp.WithDescription("Executes the same PromQL query against several Prometheus datasources concurrently, e.g., to compare a metric across regions in a federated setup. "+
+			"Returns one entry per datasource with either its result (resultType and result data, as in query_prometheus) or the error it returned; "+
+			"a failing datasource does not fail the whole call. "+
+			"Use the grafana://datasources resource to find datasource UIDs."),
+		mcp.WithArray("datasourceUids",
+			mcp.Description(fmt.Sprintf("UIDs of the Prometheus datasources to query (max: %d)", MaxMultiDatasources)),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithString("expr",
+			mcp.Description("PromQL expression to evaluate on every datasource (e.g., 'sum(rate(http_requests_total[5m]))')"),
+			mcp.Required(),
+		),
+		mcp.WithString("queryType",
+			mcp.Description("Query type: 'instant' (default) for a single point in time, or 'range' for a time series"),
+		),
+		mcp.WithString("timeRfc3339",
+			mcp.Description("Evaluation time for instant queries in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time for range queries in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time for range queries in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("stepSeconds",
+			mcp.Description("Step interval for range queries in seconds (default: 60)"),
+		),
+	)
+}
+
+// RegisterQueryMulti registers the query_prometheus_multi tool.
+func RegisterQueryMulti(s grafana.ToolRegistry) {
+	s.AddTool(newQueryMultiTool(), queryMultiHandler)
+}
@@ -0,0 +1,183 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rulesResponse is the response of the Prometheus /api/v1/rules API, as served by Prometheus, Mimir, Cortex, and Thanos.
+type rulesResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Groups []struct {
+			Name     string  `json:"name"`
+			File     string  `json:"file"`
+			Interval float64 `json:"interval"` // Seconds
+			Rules    []struct {
+				Name           string            `json:"name"`
+				Type           string            `json:"type"` // "alerting" or "recording"
+				Query          string            `json:"query"`
+				Duration       float64           `json:"duration"` // Seconds, alerting rules only
+				State          string            `json:"state"`
+				Health         string            `json:"health"`
+				LastError      string            `json:"lastError"`
+				LastEvaluation string            `json:"lastEvaluation"`
+				Labels         map[string]string `json:"labels"`
+				Annotations    map[string]string `json:"annotations"`
+				Alerts         []json.RawMessage `json:"alerts"`
+			} `json:"rules"`
+		} `json:"groups"`
+	} `json:"data"`
+}
+
+// RuleGroup is a rule group evaluated by the datasource.
+type RuleGroup struct {
+	Name     string  `json:"name"`
+	File     string  `json:"file,omitempty"` // Namespace in Mimir/Cortex
+	Interval float64 `json:"intervalSeconds,omitempty"`
+	Rules    []Rule  `json:"rules"`
+}
+
+// Rule is an alerting or recording rule with its evaluation status.
+type Rule struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Query          string            `json:"query"`
+	State          string            `json:"state,omitempty"` // firing, pending, or inactive for alerting rules
+	Health         string            `json:"health"`
+	LastError      string            `json:"lastError,omitempty"`
+	LastEvaluation string            `json:"lastEvaluation,omitempty"`
+	ForSeconds     float64           `json:"forSeconds,omitempty"`
+	ActiveAlerts   int               `json:"activeAlerts,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+}
+
+type getRulesParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Type          string `json:"type,omitempty"`
+	Query         string `json:"query,omitempty"`
+	State         string `json:"state,omitempty"`
+}
+
+// fetchRules gets the rule groups evaluated by the datasource. ruleType is "alert", "record", or empty for both.
+func (c *client) fetchRules(ctx context.Context, ruleType string) (*rulesResponse, error) {
+	params := url.Values{}
+	if ruleType != "" {
+		params.Set("type", ruleType)
+	}
+
+	bodyBytes, err := c.makeRequest(ctx, "GET", "/api/v1/rules", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp rulesResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshalling rules response: %w", err)
+	}
+	return &resp, nil
+}
+
+// ruleGroups converts a rules response into groups, keeping rules of the requested type whose name contains
+// query (case-insensitive) and, for alerting rules, whose state matches. Groups left empty are dropped.
+func ruleGroups(resp *rulesResponse, ruleType, query, state string) []RuleGroup {
+	wantType := map[string]string{"alert": "alerting", "record": "recording"}[ruleType]
+	query = strings.ToLower(query)
+
+	groups := []RuleGroup{}
+	for _, g := range resp.Data.Groups {
+		group := RuleGroup{Name: g.Name, File: g.File, Interval: g.Interval, Rules: []Rule{}}
+		for _, r := range g.Rules {
+			// Not every backend honours the type filter, so check again
+			if wantType != "" && r.Type != wantType {
+				continue
+			}
+			if query != "" && !strings.Contains(strings.ToLower(r.Name), query) {
+				continue
+			}
+			if state != "" && !strings.EqualFold(r.State, state) {
+				continue
+			}
+			group.Rules = append(group.Rules, Rule{
+				Name:           r.Name,
+				Type:           r.Type,
+				Query:          r.Query,
+				State:          r.State,
+				Health:         r.Health,
+				LastError:      r.LastError,
+				LastEvaluation: r.LastEvaluation,
+				ForSeconds:     r.Duration,
+				ActiveAlerts:   len(r.Alerts),
+				Labels:         r.Labels,
+				Annotations:    r.Annotations,
+			})
+		}
+		if len(group.Rules) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+func getRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params getRulesParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Type != "" && params.Type != "alert" && params.Type != "record" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid type: %s (must be 'alert' or 'record')", params.Type)), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
+	}
+
+	resp, err := c.fetchRules(ctx, params.Type)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	groups := ruleGroups(resp, params.Type, params.Query, params.State)
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(groups, len(groups)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newGetRulesTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_prometheus_rules",
+		mcp.WithDescription("Gets the alerting and recording rule groups evaluated by a Prometheus, Mimir, Cortex, or Thanos "+
+			"datasource from its own /api/v1/rules endpoint, with each rule's expression, state, health, last error, "+
+			"and active alert count. Use this for datasource-managed rules; list_alert_rules covers Grafana-managed rules."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Prometheus datasource whose rules to get; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Only return 'alert' (alerting) or 'record' (recording) rules (default: both)"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Case-insensitive substring to match against rule names"),
+		),
+		mcp.WithString("state",
+			mcp.Description("Only return alerting rules in this state: 'firing', 'pending', or 'inactive'"),
+		),
+	)
+}
+
+// RegisterGetRules registers the get_prometheus_rules tool.
+func RegisterGetRules(s grafana.ToolRegistry) {
+	s.AddTool(newGetRulesTool(), getRulesHandler)
+}
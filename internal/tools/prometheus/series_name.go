@@ -0,0 +1,56 @@
+package prometheus
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+// seriesName renders a label set the way Prometheus and Grafana display series, e.g., up{instance="a:9100",job="node"}.
+// Labels are sorted by name; a set without __name__ renders as just the braces.
+func seriesName(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if name != "__name__" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(labels["__name__"])
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(grafana.QuoteLabelValue(labels[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// nameSeries replaces each vector or matrix series' metric labels map with a "series" string from seriesName.
+// Scalar and string results are left untouched.
+func nameSeries(result *QueryResult) {
+	if result == nil || (result.ResultType != "vector" && result.ResultType != "matrix") {
+		return
+	}
+
+	series, ok := result.Result.([]any)
+	if !ok {
+		return
+	}
+
+	for _, s := range series {
+		m, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		m["series"] = seriesName(seriesLabels(s))
+		delete(m, "metric")
+	}
+}
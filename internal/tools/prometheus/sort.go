@@ -0,0 +1,111 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Sort modes for vector and matrix query results.
+const (
+	SortNone      = "none"
+	SortValueAsc  = "valueAsc"
+	SortValueDesc = "valueDesc"
+)
+
+// validateSortMode normalizes an empty mode to SortNone and rejects unknown modes.
+func validateSortMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return SortNone, nil
+	case SortNone, SortValueAsc, SortValueDesc:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid sort: %s (must be '%s', '%s', or '%s')", mode, SortValueAsc, SortValueDesc, SortNone)
+	}
+}
+
+// sortResult orders vector results by sample value and matrix results by each series' last value.
+// Series without a parseable value (including NaN) sort last in either direction.
+// Scalar and string results are left untouched.
+func sortResult(result *QueryResult, mode string) {
+	if result == nil || mode == SortNone {
+		return
+	}
+
+	series, ok := result.Result.([]any)
+	if !ok {
+		return
+	}
+
+	var key func(any) (float64, bool)
+	switch result.ResultType {
+	case "vector":
+		key = vectorValue
+	case "matrix":
+		key = matrixLastValue
+	default:
+		return
+	}
+
+	sort.SliceStable(series, func(i, j int) bool {
+		vi, okI := key(series[i])
+		vj, okJ := key(series[j])
+		if !okI || !okJ {
+			return okI && !okJ
+		}
+		if mode == SortValueDesc {
+			return vi > vj
+		}
+		return vi < vj
+	})
+}
+
+// vectorValue extracts the sample value from a vector series ({"metric": ..., "value": [ts, "v"]}).
+func vectorValue(s any) (float64, bool) {
+	m, ok := s.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	return sampleValue(m["value"])
+}
+
+// matrixLastValue extracts the last sample value from a matrix series ({"metric": ..., "values": [[ts, "v"], ...]}).
+func matrixLastValue(s any) (float64, bool) {
+	m, ok := s.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	values, ok := m["values"].([]any)
+	if !ok || len(values) == 0 {
+		return 0, false
+	}
+	return sampleValue(values[len(values)-1])
+}
+
+// sampleValue parses a [timestamp, "value"] pair; Prometheus encodes sample values as strings.
+func sampleValue(sample any) (float64, bool) {
+	pair, ok := sample.([]any)
+	if !ok || len(pair) != 2 {
+		return 0, false
+	}
+	str, ok := pair[1].(string)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(v) {
+		return 0, false
+	}
+	return v, true
+}
+
+// jsonValue returns v for JSON output: the number itself, or for NaN and ±Inf, which JSON cannot
+// represent, the string Prometheus uses for them ("NaN", "+Inf", "-Inf").
+func jsonValue(v float64) any {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return v
+}
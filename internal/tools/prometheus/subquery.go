@@ -0,0 +1,58 @@
+package prometheus
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// subqueryPattern matches a subquery range such as [1h:5m] or [30m:], capturing the resolution.
+var subqueryPattern = regexp.MustCompile(`\[\s*[0-9a-z]+\s*:\s*([0-9a-z]*)\s*\]`)
+
+// promDurationUnitPattern splits a Prometheus duration into its number/unit parts.
+var promDurationUnitPattern = regexp.MustCompile(`(\d+)(ms|s|m|h|d|w|y)`)
+
+// promDurationUnits maps Prometheus duration units to their length; d, w, and y are fixed-length in PromQL.
+var promDurationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// parsePromDuration parses a Prometheus duration such as "5m" or "1h30m".
+func parsePromDuration(s string) (time.Duration, bool) {
+	if !promDurationPattern.MatchString(s) {
+		return 0, false
+	}
+
+	var d time.Duration
+	for _, m := range promDurationUnitPattern.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		d += time.Duration(n) * promDurationUnits[m[2]]
+	}
+	return d, true
+}
+
+// subqueryResolution reports whether expr contains a subquery and, if any subquery sets an explicit
+// resolution, the finest one. A zero resolution means the subqueries use the global evaluation interval.
+func subqueryResolution(expr string) (bool, time.Duration) {
+	matches := subqueryPattern.FindAllStringSubmatch(expr, -1)
+	if len(matches) == 0 {
+		return false, 0
+	}
+
+	var finest time.Duration
+	for _, m := range matches {
+		if d, ok := parsePromDuration(m[1]); ok && (finest == 0 || d < finest) {
+			finest = d
+		}
+	}
+	return true, finest
+}
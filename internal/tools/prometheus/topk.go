@@ -0,0 +1,138 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultTopK is the default number of series returned by query_prometheus_topk.
+	DefaultTopK = 10
+
+	// MaxTopK is the maximum number of series query_prometheus_topk may return.
+	MaxTopK = 100
+)
+
+type topKParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Expr          string `json:"expr"`
+	K             int    `json:"k,omitempty"`
+	Direction     string `json:"direction,omitempty"` // "top" or "bottom", defaults to "top"
+	TimeRFC3339   string `json:"timeRfc3339,omitempty"`
+}
+
+// RankedSeries is a single entry in a topk/bottomk ranking.
+type RankedSeries struct {
+	Rank   int               `json:"rank"`
+	Labels map[string]string `json:"labels"`
+	Value  any               `json:"value"` // A number, or "+Inf"/"-Inf" as a string
+}
+
+func topKHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params topKParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Expr == "" {
+		return mcp.NewToolResultError("expr (PromQL expression) is required"), nil
+	}
+
+	k := params.K
+	if k == 0 {
+		k = DefaultTopK
+	}
+	if k < 0 || k > MaxTopK {
+		return mcp.NewToolResultError(fmt.Sprintf("k must be between 1 and %d", MaxTopK)), nil
+	}
+
+	var aggregator, sortMode string
+	switch params.Direction {
+	case "", "top":
+		aggregator, sortMode = "topk", SortValueDesc
+	case "bottom":
+		aggregator, sortMode = "bottomk", SortValueAsc
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid direction: %s (must be 'top' or 'bottom')", params.Direction)), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Prometheus client: %v", err)), nil
+	}
+
+	expr := fmt.Sprintf("%s(%d, %s)", aggregator, k, params.Expr)
+	result, err := c.query(ctx, expr, params.TimeRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("executing instant query: %v", err)), nil
+	}
+
+	if result.ResultType != "vector" {
+		return mcp.NewToolResultError(fmt.Sprintf("expected a vector result, got %s", result.ResultType)), nil
+	}
+
+	ranked := rankSeries(result, sortMode)
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(ranked, len(ranked)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// rankSeries orders a vector result by value and numbers its series from 1. Series without a
+// parseable value (including NaN) are skipped.
+func rankSeries(result *QueryResult, sortMode string) []RankedSeries {
+	// topk/bottomk do not guarantee output order, so rank explicitly
+	sortResult(result, sortMode)
+
+	ranked := []RankedSeries{}
+	series, _ := result.Result.([]any)
+	for _, s := range series {
+		value, ok := vectorValue(s)
+		if !ok {
+			continue
+		}
+
+		ranked = append(ranked, RankedSeries{
+			Rank:   len(ranked) + 1,
+			Labels: seriesLabels(s),
+			Value:  jsonValue(value),
+		})
+	}
+	return ranked
+}
+
+func newTopKTool() mcp.Tool {
+	return mcp.NewTool(
+		"query_prometheus_topk",
+		mcp.WithDescription("Ranks series by value at a single point in time: wraps the expression in topk(k, ...) or bottomk(k, ...), "+
+			"runs it as an instant query, and returns a ranked list of {rank, labels, value}. "+
+			"Use this for questions like 'top 5 pods by memory' instead of hand-writing the wrapper in query_prometheus."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Prometheus datasource to query; defaults to GRAFANA_DEFAULT_PROMETHEUS_UID or the default Prometheus datasource"),
+		),
+		mcp.WithString("expr",
+			mcp.Description("PromQL expression to rank, without the topk/bottomk wrapper (e.g., 'sum by (pod) (container_memory_working_set_bytes)')"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("k",
+			mcp.Description(fmt.Sprintf("Number of series to return (default: %d, max: %d)", DefaultTopK, MaxTopK)),
+		),
+		mcp.WithString("direction",
+			mcp.Description("'top' (default) for the highest values, or 'bottom' for the lowest"),
+		),
+		mcp.WithString("timeRfc3339",
+			mcp.Description("Evaluation time in RFC3339 format (defaults to now)"),
+		),
+	)
+}
+
+// RegisterTopK registers the query_prometheus_topk tool.
+func RegisterTopK(s grafana.ToolRegistry) {
+	s.AddTool(newTopKTool(), topKHandler)
+}
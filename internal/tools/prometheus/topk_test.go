@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+func TestRankSeriesNonFinite(t *testing.T) {
+	const body = `{"resultType":"vector","result":[
+		{"metric":{"pod":"a"},"value":[1700000000,"3"]},
+		{"metric":{"pod":"b"},"value":[1700000000,"+Inf"]},
+		{"metric":{"pod":"c"},"value":[1700000000,"NaN"]},
+		{"metric":{"pod":"d"},"value":[1700000000,"-Inf"]}
+	]}`
+
+	var result QueryResult
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("unmarshalling result: %v", err)
+	}
+
+	ranked := rankSeries(&result, SortValueDesc)
+
+	if _, err := grafana.MarshalJSON(ranked); err != nil {
+		t.Fatalf("marshalling ranked series: %v", err)
+	}
+
+	want := []struct {
+		pod   string
+		value any
+	}{
+		{pod: "b", value: "+Inf"},
+		{pod: "a", value: 3.0},
+		{pod: "d", value: "-Inf"},
+	}
+	if len(ranked) != len(want) {
+		t.Fatalf("got %d ranked series, want %d: %+v", len(ranked), len(want), ranked)
+	}
+	for i, w := range want {
+		got := ranked[i]
+		if got.Rank != i+1 || got.Labels["pod"] != w.pod || got.Value != w.value {
+			t.Errorf("ranked[%d] = %+v, want rank %d pod %s value %v", i, got, i+1, w.pod, w.value)
+		}
+	}
+}
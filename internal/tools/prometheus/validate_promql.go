@@ -0,0 +1,81 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// ValidationResult is the output of validate_promql.
+type ValidationResult struct {
+	Valid     bool   `json:"valid"`
+	Type      string `json:"type,omitempty"` // The expression's result type: vector, matrix, scalar, or string
+	Formatted string `json:"formatted,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+}
+
+type validatePromQLParams struct {
+	Expr string `json:"expr"`
+}
+
+// validateExpr parses and pretty-prints an expression with the Prometheus PromQL parser,
+// without a round trip to the datasource. A parse error is reported in the result, with the
+// line and column of the first error.
+func validateExpr(expr string) *ValidationResult {
+	parsed, err := parser.ParseExpr(expr)
+	if err == nil {
+		return &ValidationResult{Valid: true, Type: string(parsed.Type()), Formatted: parser.Prettify(parsed)}
+	}
+
+	result := &ValidationResult{Valid: false, Error: err.Error()}
+	var parseErrs parser.ParseErrors
+	if errors.As(err, &parseErrs) && len(parseErrs) > 0 {
+		first := parseErrs[0]
+		result.Error = first.Err.Error()
+		_, _ = fmt.Sscanf(first.PositionRange.StartPosInput(expr, 0), "%d:%d", &result.Line, &result.Column)
+	}
+	return result
+}
+
+func validatePromQLHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params validatePromQLParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Expr == "" {
+		return mcp.NewToolResultError("expr (PromQL expression) is required"), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(validateExpr(params.Expr))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newValidatePromQLTool() mcp.Tool {
+	return mcp.NewTool(
+		"validate_promql",
+		mcp.WithDescription("Syntax-checks a PromQL expression with the Prometheus PromQL parser, locally and without evaluating it. "+
+			"Returns {valid: true, type, formatted} with the expression's result type (vector, matrix, scalar, or string) and the pretty-printed expression, "+
+			"or {valid: false, error, line, column} with the parse error and its position. "+
+			"Run this on a generated expression before query_prometheus to avoid cryptic upstream 400 errors."),
+		mcp.WithString("expr",
+			mcp.Description("PromQL expression to validate (e.g., 'sum by (job) (rate(http_requests_total[5m]))')"),
+			mcp.Required(),
+		),
+	)
+}
+
+// RegisterValidatePromQL registers the validate_promql tool.
+func RegisterValidatePromQL(s grafana.ToolRegistry) {
+	s.AddTool(newValidatePromQLTool(), validatePromQLHandler)
+}
@@ -0,0 +1,46 @@
+package prometheus
+
+import "testing"
+
+func TestValidateExpr(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		wantValid bool
+		wantType  string
+		wantLine  int
+		wantCol   int
+	}{
+		{name: "instant vector", expr: `sum by (job) (rate(http_requests_total[5m]))`, wantValid: true, wantType: "vector"},
+		{name: "range vector", expr: `http_requests_total[5m]`, wantValid: true, wantType: "matrix"},
+		{name: "scalar", expr: `1 + 2`, wantValid: true, wantType: "scalar"},
+		{name: "string", expr: `"up"`, wantValid: true, wantType: "string"},
+		{name: "unclosed paren", expr: `sum(rate(up[5m])`, wantLine: 1},
+		{name: "error on second line", expr: "sum(\n  rate(up[5m]) +)", wantLine: 2},
+		{name: "unknown function", expr: `not_a_function(up)`, wantLine: 1, wantCol: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateExpr(tt.expr)
+			if got.Valid != tt.wantValid {
+				t.Fatalf("valid = %v, want %v (error: %s)", got.Valid, tt.wantValid, got.Error)
+			}
+			if tt.wantValid {
+				if got.Type != tt.wantType || got.Formatted == "" {
+					t.Errorf("type = %q, formatted = %q; want type %q and a formatted expression", got.Type, got.Formatted, tt.wantType)
+				}
+				return
+			}
+			if got.Error == "" {
+				t.Error("expected an error message")
+			}
+			if got.Line != tt.wantLine {
+				t.Errorf("line = %d, want %d (error: %s)", got.Line, tt.wantLine, got.Error)
+			}
+			if tt.wantCol != 0 && got.Column != tt.wantCol {
+				t.Errorf("column = %d, want %d", got.Column, tt.wantCol)
+			}
+		})
+	}
+}
@@ -2,39 +2,111 @@
 package tools
 
 import (
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/krmcbride/mcp-grafana/internal/tools/access"
 	"github.com/krmcbride/mcp-grafana/internal/tools/alerting"
 	"github.com/krmcbride/mcp-grafana/internal/tools/dashboard"
+	"github.com/krmcbride/mcp-grafana/internal/tools/datasource"
+	"github.com/krmcbride/mcp-grafana/internal/tools/drilldown"
+	"github.com/krmcbride/mcp-grafana/internal/tools/elasticsearch"
+	"github.com/krmcbride/mcp-grafana/internal/tools/influxdb"
 	"github.com/krmcbride/mcp-grafana/internal/tools/loki"
+	"github.com/krmcbride/mcp-grafana/internal/tools/passthrough"
 	"github.com/krmcbride/mcp-grafana/internal/tools/prometheus"
+	"github.com/krmcbride/mcp-grafana/internal/tools/router"
+	"github.com/krmcbride/mcp-grafana/internal/tools/sql"
 	"github.com/krmcbride/mcp-grafana/internal/tools/tempo"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func RegisterMCPTools(s *server.MCPServer) {
+func RegisterMCPTools(srv *server.MCPServer) {
+	// Tools the operator has not enabled are dropped as they are registered, and tools that
+	// dispatch to other tools' handlers check the same filter before calling them
+	s := newToolFilter(srv)
+	grafana.SetToolEnabled(s.enabled)
+
+	// Register datasource discovery tools
+	datasource.RegisterListTypes(s)
+	datasource.RegisterSelfTest(s)
+	datasource.RegisterClearCache(s)
+
 	// Register Loki query tools
 	loki.RegisterListLabelNames(s)
 	loki.RegisterListLabelValues(s)
 	loki.RegisterQueryStats(s)
 	loki.RegisterQueryLogs(s)
+	loki.RegisterLogContext(s)
+	loki.RegisterQueryMetric(s)
+	loki.RegisterAggregateLogs(s)
+	loki.RegisterSuggestLabels(s)
+	loki.RegisterLabelCardinality(s)
+	loki.RegisterBuildInfo(s)
+	loki.RegisterValidateLogQL(s)
 
 	// Register Prometheus query tools
+	prometheus.RegisterCompare(s)
 	prometheus.RegisterListLabelNames(s)
 	prometheus.RegisterListLabelValues(s)
 	prometheus.RegisterListMetricNames(s)
 	prometheus.RegisterQuery(s)
+	prometheus.RegisterQueryMulti(s)
+	prometheus.RegisterQuantile(s)
+	prometheus.RegisterTopK(s)
+	prometheus.RegisterValidatePromQL(s)
+	prometheus.RegisterGetRules(s)
 
 	// Register Tempo tracing tools
 	tempo.RegisterListTagNames(s)
 	tempo.RegisterListTagValues(s)
 	tempo.RegisterSearchTraces(s)
+	tempo.RegisterBuildTraceQL(s)
 	tempo.RegisterGetTrace(s)
+	tempo.RegisterGetTraces(s)
+	tempo.RegisterCheckMetricsGenerator(s)
+	tempo.RegisterAttributeHistogram(s)
+	tempo.RegisterExplainTrace(s)
+
+	// Register Elasticsearch/OpenSearch log tools
+	elasticsearch.RegisterQueryLogs(s)
+
+	// Register InfluxDB query tools
+	influxdb.RegisterQuery(s)
+
+	// Register SQL (PostgreSQL/MySQL) query tools
+	sql.RegisterQuery(s)
 
 	// Register Dashboard tools
 	dashboard.RegisterSearch(s)
 	dashboard.RegisterGetSummary(s)
+	dashboard.RegisterGetDashboard(s)
 	dashboard.RegisterGetPanelQueries(s)
+	dashboard.RegisterGetPanelData(s)
+	dashboard.RegisterRenderPanel(s)
 
 	// Register Alerting tools
 	alerting.RegisterListRules(s)
 	alerting.RegisterGetRuleByUID(s)
+	alerting.RegisterGetRuleGroup(s)
+	alerting.RegisterSearchRules(s)
+	alerting.RegisterListRecordingRules(s)
+	alerting.RegisterListMuteTimings(s)
+
+	// Register access review tools
+	access.RegisterListTeams(s)
+	access.RegisterListOrgUsers(s)
+	access.RegisterGetFolderPermissions(s)
+
+	// Register the datasource-routing query tool
+	router.RegisterQuery(s)
+
+	// Register cross-datasource drilldown tools
+	drilldown.RegisterExemplar(s)
+	drilldown.RegisterRecentErrors(s)
+	drilldown.RegisterDiscoverLabels(s)
+
+	// Register raw passthrough tools
+	passthrough.RegisterDatasourceProxy(s)
+	passthrough.RegisterAPI(s)
+
+	s.report()
 }
@@ -0,0 +1,208 @@
+// Package router provides an MCP tool that routes a query to the right backend tool
+// based on the type of the target datasource.
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/krmcbride/mcp-grafana/internal/tools/loki"
+	"github.com/krmcbride/mcp-grafana/internal/tools/prometheus"
+	"github.com/krmcbride/mcp-grafana/internal/tools/tempo"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type queryParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Query         string `json:"query"`
+	StartRFC3339  string `json:"startRfc3339,omitempty"`
+	EndRFC3339    string `json:"endRfc3339,omitempty"`
+	QueryType     string `json:"queryType,omitempty"`
+	StepSeconds   int    `json:"stepSeconds,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+}
+
+// RoutedResult is the result of the backend tool a query was routed to.
+type RoutedResult struct {
+	Backend        string          `json:"backend"` // Name of the tool that ran the query
+	DatasourceType string          `json:"datasourceType"`
+	Result         json.RawMessage `json:"result"`
+}
+
+// route describes how a datasource type maps onto a backend tool.
+type route struct {
+	tool    string
+	handler server.ToolHandlerFunc
+	args    func(p queryParams) map[string]any
+}
+
+// routes maps Grafana datasource plugin types to the tools that query them.
+var routes = map[string]route{
+	"prometheus": {
+		tool:    "query_prometheus",
+		handler: prometheus.HandleQuery,
+		args: func(p queryParams) map[string]any {
+			args := map[string]any{"expr": p.Query, "queryType": p.QueryType, "stepSeconds": p.StepSeconds}
+			if p.QueryType == "range" {
+				args["startRfc3339"], args["endRfc3339"] = p.StartRFC3339, p.EndRFC3339
+			} else {
+				args["timeRfc3339"] = p.EndRFC3339
+			}
+			return args
+		},
+	},
+	"loki": {
+		tool:    "query_loki_logs",
+		handler: loki.HandleQueryLogs,
+		args: func(p queryParams) map[string]any {
+			return map[string]any{"logql": p.Query, "startRfc3339": p.StartRFC3339, "endRfc3339": p.EndRFC3339, "limit": p.Limit}
+		},
+	},
+	"tempo": {
+		tool:    "search_tempo_traces",
+		handler: tempo.HandleSearchTraces,
+		args: func(p queryParams) map[string]any {
+			return map[string]any{"query": p.Query, "startRfc3339": p.StartRFC3339, "endRfc3339": p.EndRFC3339, "limit": p.Limit}
+		},
+	},
+}
+
+// getDatasourceType looks up the plugin type of a datasource by UID.
+func getDatasourceType(ctx context.Context, uid string) (string, error) {
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return "", err
+	}
+
+	reqURL := fmt.Sprintf("%s/api/datasources/uid/%s", grafanaURL, url.PathEscape(uid))
+	statusCode, bodyBytes, err := grafana.Do(ctx, httpClient, grafana.Request{Method: "GET", URL: reqURL})
+	if err != nil {
+		return "", err
+	}
+
+	if statusCode != http.StatusOK {
+		return "", grafana.StatusError("API", statusCode, bodyBytes)
+	}
+
+	var ds struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(bodyBytes, &ds); err != nil {
+		return "", fmt.Errorf("unmarshalling datasource: %w", err)
+	}
+
+	return ds.Type, nil
+}
+
+func queryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params queryParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.DatasourceUID == "" {
+		return mcp.NewToolResultError("datasourceUid is required"), nil
+	}
+	if params.Query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+	// The UID is required, so this only turns a numeric datasource ID into its UID
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dsType, err := getDatasourceType(ctx, datasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("looking up datasource %s: %v", datasourceUID, err)), nil
+	}
+
+	r, ok := routes[dsType]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported datasource type %q (supported: prometheus, loki, tempo)", dsType)), nil
+	}
+	if !grafana.ToolEnabled(r.tool) {
+		return mcp.NewToolResultError(fmt.Sprintf("tool disabled: %s datasources are queried with %s, which is disabled on this server", dsType, r.tool)), nil
+	}
+
+	args := r.args(params)
+	args["datasourceUid"] = datasourceUID
+
+	backendRequest := mcp.CallToolRequest{}
+	backendRequest.Params.Name = r.tool
+	backendRequest.Params.Arguments = args
+
+	result, err := r.handler(ctx, backendRequest)
+	if err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		return result, nil
+	}
+
+	// The backend tools return a single JSON text content; nest it under the routing metadata
+	var text string
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text = tc.Text
+			break
+		}
+	}
+
+	jsonData, err := grafana.MarshalJSON(RoutedResult{
+		Backend:        r.tool,
+		DatasourceType: dsType,
+		Result:         json.RawMessage(text),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newQueryTool() mcp.Tool {
+	return mcp.NewTool(
+		"query",
+		mcp.WithDescription("Runs a query against any Prometheus, Loki, or Tempo datasource: looks up the datasource type "+
+			"and routes PromQL to query_prometheus, LogQL to query_loki_logs, and TraceQL to search_tempo_traces. "+
+			"Returns {backend, datasourceType, result} where result is that tool's output. "+
+			"Use the per-backend tools directly for their advanced options."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID (or numeric ID) of the datasource to query"),
+			mcp.Required(),
+		),
+		mcp.WithString("query",
+			mcp.Description("PromQL, LogQL, or TraceQL expression matching the datasource type"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to the backend's default window, 1 hour unless overridden; ignored by Prometheus instant queries)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now; the evaluation time of Prometheus instant queries)"),
+		),
+		mcp.WithString("queryType",
+			mcp.Description("Prometheus only: 'instant' (default) or 'range'"),
+		),
+		mcp.WithNumber("stepSeconds",
+			mcp.Description("Prometheus range queries only: step interval in seconds (default: 60)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Loki and Tempo only: maximum number of log lines or traces to return"),
+		),
+	)
+}
+
+// RegisterQuery registers the query tool.
+func RegisterQuery(s grafana.ToolRegistry) {
+	s.AddTool(newQueryTool(), queryHandler)
+}
@@ -0,0 +1,197 @@
+// Package sql provides MCP tools for running read-only queries against Grafana's SQL datasources
+// (PostgreSQL and MySQL) through Grafana's query API.
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+const (
+	// DefaultRowLimit is the default number of rows returned per table if not specified.
+	DefaultRowLimit = 100
+
+	// MaxRowLimit is the maximum number of rows that can be requested per table.
+	MaxRowLimit = 1000
+)
+
+// sqlTypes lists the datasource plugin types query_sql accepts.
+var sqlTypes = map[string]bool{
+	"grafana-postgresql-datasource": true,
+	"postgres":                      true, // Plugin ID before Grafana 10.4
+	"mysql":                         true,
+}
+
+// defaultWindow is the time range substituted into Grafana time macros when no start time is given.
+// Override with SQL_DEFAULT_WINDOW (e.g., "15m", "6h").
+var defaultWindow = grafana.DurationFromEnv("SQL_DEFAULT_WINDOW", time.Hour)
+
+// client provides methods for querying SQL datasources via Grafana's /api/ds/query endpoint.
+type client struct {
+	httpClient    *http.Client
+	grafanaURL    string
+	datasourceUID string
+}
+
+// newClient creates a SQL client for the specified datasource UID, falling back to the default
+// PostgreSQL or MySQL datasource when it is empty.
+func newClient(ctx context.Context, datasourceUID string) (*client, error) {
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, "postgres", "grafana-postgresql-datasource", "mysql")
+	if err != nil {
+		return nil, err
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return nil, err
+	}
+
+	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		httpClient:    httpClient,
+		grafanaURL:    grafanaURL,
+		datasourceUID: datasourceUID,
+	}, nil
+}
+
+// getType returns the datasource's plugin type, failing unless it is a supported SQL datasource.
+func (c *client) getType(ctx context.Context) (string, error) {
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method: "GET",
+		URL:    fmt.Sprintf("%s/api/datasources/uid/%s", c.grafanaURL, url.PathEscape(c.datasourceUID)),
+	})
+	if err != nil {
+		return "", err
+	}
+	if statusCode != http.StatusOK {
+		return "", grafana.StatusError("API", statusCode, bodyBytes)
+	}
+
+	var ds struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(bodyBytes, &ds); err != nil {
+		return "", fmt.Errorf("unmarshalling datasource: %w", err)
+	}
+	if !sqlTypes[ds.Type] {
+		return "", fmt.Errorf("datasource %s is of type %q, not a PostgreSQL or MySQL datasource", c.datasourceUID, ds.Type)
+	}
+
+	return ds.Type, nil
+}
+
+// frame is a Grafana data frame as returned by /api/ds/query, stored column-wise.
+type frame struct {
+	Schema struct {
+		Name   string `json:"name"`
+		Fields []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"fields"`
+	} `json:"schema"`
+	Data struct {
+		Values [][]any `json:"values"`
+	} `json:"data"`
+}
+
+// dsQueryResponse represents the response from Grafana's /api/ds/query endpoint.
+type dsQueryResponse struct {
+	Results map[string]struct {
+		Status int     `json:"status"`
+		Error  string  `json:"error,omitempty"`
+		Frames []frame `json:"frames"`
+	} `json:"results"`
+}
+
+// refID identifies the single query sent in each /api/ds/query request.
+const refID = "A"
+
+// query runs rawSQL as a table query through /api/ds/query. Grafana expands its time macros
+// ($__timeFilter, $__timeFrom, ...) from start and end.
+func (c *client) query(ctx context.Context, dsType, rawSQL string, start, end time.Time) ([]frame, error) {
+	body, err := json.Marshal(map[string]any{
+		"queries": []map[string]any{{
+			"refId":      refID,
+			"datasource": map[string]string{"uid": c.datasourceUID, "type": dsType},
+			"rawSql":     rawSQL,
+			"rawQuery":   true,
+			"editorMode": "code",
+			"format":     "table",
+		}},
+		"from": strconv.FormatInt(start.UnixMilli(), 10),
+		"to":   strconv.FormatInt(end.UnixMilli(), 10),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	statusCode, bodyBytes, err := grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method:      "POST",
+		URL:         c.grafanaURL + "/api/ds/query",
+		Body:        body,
+		ContentType: "application/json",
+		MaxBytes:    grafana.MaxResponseBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A failing query comes back as a non-200 with the database's error in the query's result
+	var resp dsQueryResponse
+	if jsonErr := json.Unmarshal(bodyBytes, &resp); jsonErr == nil {
+		if result, ok := resp.Results[refID]; ok && result.Error != "" {
+			return nil, fmt.Errorf("query error: %s", result.Error)
+		}
+	}
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
+	}
+	if resp.Results == nil {
+		return nil, fmt.Errorf("unmarshalling query response: unexpected body")
+	}
+
+	return resp.Results[refID].Frames, nil
+}
+
+// getDefaultTimeRange parses optional RFC3339 bounds, defaulting to the last defaultWindow.
+func getDefaultTimeRange(startRFC3339, endRFC3339 string) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if endRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, endRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing end time: %w", err)
+		}
+		end = t
+	}
+
+	start := end.Add(-defaultWindow)
+	if startRFC3339 != "" {
+		t, err := time.Parse(time.RFC3339, startRFC3339)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing start time: %w", err)
+		}
+		start = t
+	}
+
+	return start, end, nil
+}
+
+// enforceRowLimit ensures the per-table row limit is within acceptable bounds.
+func enforceRowLimit(requestedLimit int) int {
+	if requestedLimit <= 0 {
+		return DefaultRowLimit
+	}
+	if requestedLimit > MaxRowLimit {
+		return MaxRowLimit
+	}
+	return requestedLimit
+}
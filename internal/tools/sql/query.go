@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Column describes one column of a query result.
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // Grafana field type, e.g., string, number, time
+}
+
+// Table is one data frame of a query result, turned from columns into rows.
+type Table struct {
+	Name      string   `json:"name,omitempty"`
+	Columns   []Column `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+type queryParams struct {
+	DatasourceUID  string `json:"datasourceUid"`
+	Query          string `json:"query"`
+	StartRFC3339   string `json:"startRfc3339,omitempty"`
+	EndRFC3339     string `json:"endRfc3339,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"`
+}
+
+// frameTable turns a column-wise data frame into a table of at most limit rows.
+// Time columns, which Grafana sends as Unix milliseconds, are rendered as RFC3339.
+func frameTable(f frame, limit int) Table {
+	t := Table{Name: f.Schema.Name, Columns: make([]Column, len(f.Schema.Fields)), Rows: [][]any{}}
+	for i, field := range f.Schema.Fields {
+		t.Columns[i] = Column{Name: field.Name, Type: field.Type}
+	}
+
+	rowCount := 0
+	if len(f.Data.Values) > 0 {
+		rowCount = len(f.Data.Values[0])
+	}
+	if rowCount > limit {
+		rowCount, t.Truncated = limit, true
+	}
+
+	for r := 0; r < rowCount; r++ {
+		row := make([]any, len(f.Data.Values))
+		for c, values := range f.Data.Values {
+			if r >= len(values) {
+				continue
+			}
+			row[c] = values[r]
+			if ms, ok := values[r].(float64); ok && c < len(t.Columns) && t.Columns[c].Type == "time" {
+				row[c] = time.UnixMilli(int64(ms)).UTC().Format(time.RFC3339Nano)
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	return t
+}
+
+func queryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params queryParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if strings.TrimSpace(params.Query) == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+
+	start, end, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating SQL client: %v", err)), nil
+	}
+
+	ctx, cancel := grafana.WithRequestTimeout(ctx, c.httpClient, params.TimeoutSeconds)
+	defer cancel()
+
+	dsType, err := c.getType(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := checkReadOnly(params.Query, dsType); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	frames, err := c.query(ctx, dsType, params.Query, start, end)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	limit := enforceRowLimit(params.Limit)
+	tables := make([]Table, len(frames))
+	for i, f := range frames {
+		tables[i] = frameTable(f, limit)
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(tables, len(tables)))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newQueryTool() mcp.Tool {
+	return mcp.NewTool(
+		"query_sql",
+		mcp.WithDescription("Executes a read-only SQL query against a PostgreSQL or MySQL datasource through Grafana's query API "+
+			"and returns the result as tables of columns and rows, e.g., to run a dashboard SQL panel's query. "+
+			"Only a single SELECT, WITH, SHOW, EXPLAIN, or DESCRIBE statement is accepted; statements that write or change state are rejected. "+
+			"Grafana time macros such as $__timeFilter(time_column), $__timeFrom(), and $__timeGroup(time_column, '5m') "+
+			"are expanded from startRfc3339/endRfc3339."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the PostgreSQL or MySQL datasource to query; defaults to GRAFANA_DEFAULT_POSTGRES_UID or the default PostgreSQL/MySQL datasource"),
+		),
+		mcp.WithString("query",
+			mcp.Description("The SQL statement to execute (e.g., 'SELECT $__time(created_at), count(*) FROM orders WHERE $__timeFilter(created_at) GROUP BY 1')"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time substituted into time macros, in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time substituted into time macros, in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of rows to return per table (default: %d, max: %d)", DefaultRowLimit, MaxRowLimit)),
+		),
+		mcp.WithNumber("timeoutSeconds",
+			mcp.Description("Override the request timeout for this call in seconds (default: the server-wide timeout, max: 300)"),
+		),
+	)
+}
+
+// RegisterQuery registers the query_sql tool with the MCP server.
+func RegisterQuery(s grafana.ToolRegistry) {
+	s.AddTool(newQueryTool(), queryHandler)
+}
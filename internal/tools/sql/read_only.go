@@ -0,0 +1,205 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// readStatements lists the keywords a read query may start with.
+var readStatements = map[string]bool{
+	"select":   true,
+	"with":     true,
+	"show":     true,
+	"explain":  true,
+	"describe": true,
+	"desc":     true,
+}
+
+// writeStatements lists keywords that begin a statement that writes or changes state. They are only
+// checked where a statement begins, so functions and columns sharing a name (replace(), a column named
+// load) are allowed.
+var writeStatements = map[string]bool{
+	"insert": true, "update": true, "delete": true, "merge": true, "upsert": true, "replace": true,
+	"create": true, "alter": true, "drop": true, "truncate": true, "rename": true,
+	"grant": true, "revoke": true, "copy": true, "load": true,
+	"call": true, "exec": true, "execute": true, "do": true, "set": true, "lock": true,
+	"vacuum": true, "reindex": true, "cluster": true, "refresh": true, "handler": true,
+}
+
+// lockingClauses lists the words following FOR in a SELECT that locks the rows it reads
+// (FOR UPDATE, FOR NO KEY UPDATE, FOR SHARE, FOR KEY SHARE).
+var lockingClauses = map[string]bool{"update": true, "no": true, "share": true, "key": true}
+
+// sqlTokens returns the lowercased words of statement outside string literals, quoted identifiers,
+// and comments, with parentheses and commas as tokens of their own, and whether it has anything but
+// whitespace after a semicolon (a second statement). PostgreSQL dollar-quoted strings are skipped;
+// Grafana macros such as $__timeFilter are kept as words. Backslash escapes (outside PostgreSQL E'...'
+// strings) and # comments are only MySQL syntax, so they are honoured only when mysql is set; elsewhere
+// they could hide a second statement from the check.
+func sqlTokens(statement string, mysql bool) ([]string, bool) {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, strings.ToLower(word.String()))
+			word.Reset()
+		}
+	}
+
+	s := []rune(statement)
+	afterSemicolon, multiple := false, false
+	for i := 0; i < len(s); i++ {
+		r := s[i]
+		if afterSemicolon && !unicode.IsSpace(r) && r != ';' {
+			multiple = true
+		}
+
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			// PostgreSQL E'...' strings take backslash escapes too
+			escapes := r == '\'' && (mysql || strings.EqualFold(word.String(), "e"))
+			flush()
+			for i++; i < len(s); i++ {
+				if escapes && s[i] == '\\' {
+					i++ // MySQL backslash escape
+				} else if s[i] == r {
+					if i+1 < len(s) && s[i+1] == r {
+						i++ // Doubled quote escape
+					} else {
+						break
+					}
+				}
+			}
+		case r == '-' && i+1 < len(s) && s[i+1] == '-', mysql && r == '#':
+			flush()
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(s) && s[i+1] == '*':
+			flush()
+			i = skipPast(s, i+2, "*/")
+		case r == '$':
+			flush()
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(s[j]) || unicode.IsDigit(s[j])) {
+				j++
+			}
+			if j < len(s) && s[j] == '$' {
+				// Dollar-quoted string: skip to the matching $tag$
+				i = skipPast(s, j+1, string(s[i:j+1]))
+			} else {
+				word.WriteString(string(s[i+1 : j]))
+				flush()
+				i = j - 1
+			}
+		case r == ';':
+			flush()
+			afterSemicolon = true
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		case r == '(' || r == ')' || r == ',':
+			flush()
+			words = append(words, string(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return words, multiple
+}
+
+// skipPast returns the index of the last rune of the first occurrence of end in s at or after from,
+// or len(s) if there is none.
+func skipPast(s []rune, from int, end string) int {
+	e := []rune(end)
+	for i := from; i+len(e) <= len(s); i++ {
+		if string(s[i:i+len(e)]) == end {
+			return i + len(e) - 1
+		}
+	}
+	return len(s)
+}
+
+// checkReadOnly rejects anything but a single read query (SELECT, WITH, SHOW, EXPLAIN, DESCRIBE).
+// Write keywords are rejected where a statement begins: the query itself, the statement EXPLAIN
+// describes (EXPLAIN ANALYZE runs it), a CTE body (WITH d AS (DELETE ...)), and the statement a WITH
+// clause precedes. SELECT ... INTO and locking reads (FOR UPDATE, LOCK IN SHARE MODE) are rejected too.
+// It is a guard against accidents, not a security boundary: the datasource's database user should
+// still only have read access.
+func checkReadOnly(statement, dsType string) error {
+	tokens, multiple := sqlTokens(statement, dsType == "mysql")
+	if len(tokens) == 0 {
+		return fmt.Errorf("query is empty")
+	}
+	if multiple {
+		return fmt.Errorf("only a single statement is allowed")
+	}
+	if !readStatements[tokens[0]] {
+		return fmt.Errorf("only read queries are allowed (SELECT, WITH, SHOW, EXPLAIN, DESCRIBE), got %s", strings.ToUpper(tokens[0]))
+	}
+
+	// awaiting holds the parenthesis depths where an EXPLAIN or WITH header has yet to reach the
+	// statement it precedes
+	awaiting := map[int]bool{}
+	depth := 0
+	for i, token := range tokens {
+		var prev, next string
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		switch token {
+		case "(":
+			depth++
+			continue
+		case ")":
+			delete(awaiting, depth)
+			depth--
+			continue
+		case ",":
+			continue
+		}
+
+		start := i == 0 || (prev == "(" && (token == "with" || isCTEBody(tokens, i-1)))
+		if awaiting[depth] && isStatementKeyword(token) && next != "as" && next != "(" {
+			start = true
+			delete(awaiting, depth)
+		}
+		if start {
+			if writeStatements[token] {
+				return fmt.Errorf("only read queries are allowed; %s is not permitted", strings.ToUpper(token))
+			}
+			switch token {
+			case "explain", "describe", "desc", "with":
+				awaiting[depth] = true
+			}
+		}
+
+		switch {
+		case token == "into":
+			return fmt.Errorf("only read queries are allowed; SELECT ... INTO is not permitted")
+		case token == "for" && lockingClauses[next]:
+			return fmt.Errorf("only read queries are allowed; FOR %s is not permitted", strings.ToUpper(next))
+		case token == "lock" && next == "in":
+			return fmt.Errorf("only read queries are allowed; LOCK IN SHARE MODE is not permitted")
+		}
+	}
+	return nil
+}
+
+// isStatementKeyword reports whether token can begin the statement an EXPLAIN or WITH header precedes.
+// SET is left out, as it also appears in a CTE's SEARCH and CYCLE clauses.
+func isStatementKeyword(token string) bool {
+	return token != "set" && (readStatements[token] || writeStatements[token])
+}
+
+// isCTEBody reports whether the parenthesis at tokens[open] opens a CTE body: AS (...), or
+// AS [NOT] MATERIALIZED (...) in PostgreSQL.
+func isCTEBody(tokens []string, open int) bool {
+	return open > 0 && (tokens[open-1] == "as" || tokens[open-1] == "materialized")
+}
@@ -0,0 +1,57 @@
+package sql
+
+import "testing"
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		dsType  string
+		wantErr bool
+	}{
+		{name: "select", query: `SELECT * FROM users WHERE $__timeFilter(created_at)`},
+		{name: "replace function", query: `SELECT replace(name, 'a', 'b') FROM users`},
+		{name: "columns named like keywords", query: `SELECT cluster, load, handler, "update" FROM nodes ORDER BY load`},
+		{name: "keyword in a string", query: `SELECT * FROM audit WHERE action = 'DELETE'`},
+		{name: "cte", query: `WITH recent AS (SELECT * FROM events) SELECT count(*) FROM recent`},
+		{name: "cte with column list", query: `WITH t(n) AS (SELECT 1), u AS NOT MATERIALIZED (SELECT 2) SELECT * FROM t, u`},
+		{name: "cte named like a keyword", query: `WITH load AS (SELECT 1) SELECT * FROM load`},
+		{name: "recursive cte cycle clause", query: `WITH RECURSIVE t AS (SELECT 1 AS id) CYCLE id SET is_cycle USING path SELECT * FROM t`},
+		{name: "subquery alias", query: `WITH a AS (SELECT 1) SELECT * FROM a JOIN (SELECT 2) cluster ON true`},
+		{name: "explain", query: `EXPLAIN (ANALYZE, FORMAT JSON) SELECT * FROM users`},
+		{name: "show create table", query: `SHOW CREATE TABLE users`, dsType: "mysql"},
+		{name: "describe", query: `DESCRIBE users`, dsType: "mysql"},
+		{name: "substring for", query: `SELECT substring(name FROM 1 FOR 3) FROM users`},
+
+		{name: "empty", query: `  -- nothing`, wantErr: true},
+		{name: "insert", query: `INSERT INTO users VALUES (1)`, wantErr: true},
+		{name: "second statement", query: `SELECT 1; DROP TABLE users`, wantErr: true},
+		{name: "data-modifying cte", query: `WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d`, wantErr: true},
+		{name: "materialized data-modifying cte", query: `WITH d AS MATERIALIZED (UPDATE users SET a = 1 RETURNING *) SELECT * FROM d`, wantErr: true},
+		{name: "cte before delete", query: `WITH old AS (SELECT id FROM users) DELETE FROM users WHERE id IN (SELECT id FROM old)`, wantErr: true},
+		{name: "explain analyze delete", query: `EXPLAIN ANALYZE DELETE FROM users`, wantErr: true},
+		{name: "explain analyze cte before update", query: `EXPLAIN ANALYZE WITH a AS (SELECT 1) UPDATE users SET a = 1`, wantErr: true},
+		{name: "select into", query: `SELECT * INTO backup FROM users`, wantErr: true},
+		{name: "select into outfile", query: `SELECT * FROM users INTO OUTFILE '/tmp/users'`, dsType: "mysql", wantErr: true},
+		{name: "for update", query: `SELECT * FROM users FOR UPDATE`, wantErr: true},
+		{name: "for no key update", query: `SELECT * FROM users FOR NO KEY UPDATE`, wantErr: true},
+		{name: "lock in share mode", query: `SELECT * FROM users LOCK IN SHARE MODE`, dsType: "mysql", wantErr: true},
+		{name: "mysql comment hides nothing elsewhere", query: "SELECT 1 # ; DROP TABLE users", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsType := tt.dsType
+			if dsType == "" {
+				dsType = "grafana-postgresql-datasource"
+			}
+			err := checkReadOnly(tt.query, dsType)
+			if tt.wantErr && err == nil {
+				t.Fatalf("checkReadOnly(%q) = nil, want an error", tt.query)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("checkReadOnly(%q) error: %v", tt.query, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultHistogramTraceLimit is the default number of traces sampled by tempo_attribute_histogram.
+	DefaultHistogramTraceLimit = 100
+
+	// DefaultSpansPerSpanSet is the default number of matched spans returned per trace by tempo_attribute_histogram.
+	DefaultSpansPerSpanSet = 20
+)
+
+type attributeHistogramParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+	Query         string `json:"query"`
+	Attribute     string `json:"attribute"`
+	StartRFC3339  string `json:"startRfc3339,omitempty"`
+	EndRFC3339    string `json:"endRfc3339,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+}
+
+// AttributeValueCount is the number of matched spans carrying one attribute value.
+type AttributeValueCount struct {
+	Value   string  `json:"value"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// AttributeHistogram is the frequency distribution of an attribute across matched spans.
+type AttributeHistogram struct {
+	Attribute          string                `json:"attribute"`
+	TracesSearched     int                   `json:"tracesSearched"`
+	SpansMatched       int                   `json:"spansMatched"`
+	SpansWithAttribute int                   `json:"spansWithAttribute"`
+	Values             []AttributeValueCount `json:"values"`
+}
+
+// attributeKey returns the key Tempo uses for an attribute in search results,
+// which omits the span./resource. scope prefix.
+func attributeKey(attribute string) string {
+	for _, prefix := range []string{"span.", "resource.", "."} {
+		if strings.HasPrefix(attribute, prefix) {
+			return strings.TrimPrefix(attribute, prefix)
+		}
+	}
+	return attribute
+}
+
+// attributeValueString decodes an OTLP-encoded search attribute value to a string.
+func attributeValueString(raw any) string {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Sprint(raw)
+	}
+	var v otlpAnyValue
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Sprint(raw)
+	}
+	if decoded := v.value(); decoded != nil {
+		return fmt.Sprint(decoded)
+	}
+	return fmt.Sprint(raw)
+}
+
+// buildAttributeHistogram counts attribute values across all matched spans, most frequent first.
+func buildAttributeHistogram(resp *SearchResponse, attribute string) *AttributeHistogram {
+	key := attributeKey(attribute)
+	hist := &AttributeHistogram{
+		Attribute:      attribute,
+		TracesSearched: len(resp.Traces),
+		Values:         []AttributeValueCount{},
+	}
+
+	counts := make(map[string]int)
+	for _, t := range resp.Traces {
+		for _, ss := range t.SpanSets {
+			for _, span := range ss.Spans {
+				hist.SpansMatched++
+				for _, attr := range span.Attributes {
+					if attr.Key != key && attr.Key != attribute {
+						continue
+					}
+					counts[attributeValueString(attr.Value)]++
+					hist.SpansWithAttribute++
+					break
+				}
+			}
+		}
+	}
+
+	for value, count := range counts {
+		hist.Values = append(hist.Values, AttributeValueCount{
+			Value:   value,
+			Count:   count,
+			Percent: float64(count) * 100 / float64(hist.SpansWithAttribute),
+		})
+	}
+	sort.Slice(hist.Values, func(i, j int) bool {
+		if hist.Values[i].Count != hist.Values[j].Count {
+			return hist.Values[i].Count > hist.Values[j].Count
+		}
+		return hist.Values[i].Value < hist.Values[j].Value
+	})
+
+	return hist
+}
+
+func attributeHistogramHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params attributeHistogramParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if params.Query == "" {
+		return mcp.NewToolResultError("query (TraceQL selector) is required"), nil
+	}
+	if params.Attribute == "" {
+		return mcp.NewToolResultError("attribute is required"), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
+	}
+
+	startUnix, endUnix, notice, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultHistogramTraceLimit
+	}
+	limit = enforceTraceLimit(limit)
+
+	// select() makes Tempo return the attribute on every matched span
+	query := fmt.Sprintf("%s | select(%s)", params.Query, params.Attribute)
+	resp, err := c.searchTraces(ctx, query, startUnix, endUnix, limit, searchOptions{SpansPerSpanSet: DefaultSpansPerSpanSet})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	hist := buildAttributeHistogram(resp, params.Attribute)
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(grafana.WrapResult(hist, len(hist.Values)), notice))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newAttributeHistogramTool() mcp.Tool {
+	return mcp.NewTool(
+		"tempo_attribute_histogram",
+		mcp.WithDescription("Builds a frequency distribution of one attribute's values across the spans matched by a TraceQL selector, "+
+			"e.g., which http.status_code values an endpoint returns or which db.system values a service uses. "+
+			"Samples recent matching traces and returns each value with its span count and percentage, most frequent first. "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Tempo datasource to query; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
+		),
+		mcp.WithString("query",
+			mcp.Description("TraceQL spanset selector choosing the spans to count (e.g., '{span.http.route=\"/api/orders\"}')"),
+			mcp.Required(),
+		),
+		mcp.WithString("attribute",
+			mcp.Description("Attribute whose values to count, with scope (e.g., 'span.http.status_code', 'resource.service.name')"),
+			mcp.Required(),
+		),
+		mcp.WithString("startRfc3339",
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
+		),
+		mcp.WithString("endRfc3339",
+			mcp.Description("End time in RFC3339 format (defaults to now)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of traces to sample (default: %d, max: %d); up to %d spans are counted per trace",
+				DefaultHistogramTraceLimit, maxTraceLimit, DefaultSpansPerSpanSet)),
+		),
+	)
+}
+
+// RegisterAttributeHistogram registers the tempo_attribute_histogram tool.
+func RegisterAttributeHistogram(s grafana.ToolRegistry) {
+	s.AddTool(newAttributeHistogramTool(), attributeHistogramHandler)
+}
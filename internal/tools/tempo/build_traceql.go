@@ -0,0 +1,228 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// traceQLIntrinsics are span fields addressed without a scope prefix.
+var traceQLIntrinsics = map[string]bool{
+	"name": true, "status": true, "statusMessage": true, "duration": true, "kind": true,
+	"rootName": true, "rootServiceName": true, "traceDuration": true,
+}
+
+// traceQLKeywords are the enum values of the status and kind intrinsics, written unquoted.
+var traceQLKeywords = map[string]map[string]bool{
+	"status": {"error": true, "ok": true, "unset": true},
+	"kind":   {"unspecified": true, "internal": true, "server": true, "client": true, "producer": true, "consumer": true},
+}
+
+// traceQLAttributePattern matches attribute names that need no quoting, optionally with a leading dot.
+var traceQLAttributePattern = regexp.MustCompile(`^\.?[A-Za-z_][A-Za-z0-9_.\-/]*$`)
+
+// traceQLOperators are the comparison operators a condition may use.
+var traceQLOperators = map[string]bool{"=": true, "!=": true, "=~": true, "!~": true, ">": true, ">=": true, "<": true, "<=": true}
+
+// traceQLCondition is one attribute comparison, e.g., {attribute: "http.status_code", operator: ">=", value: 500}.
+type traceQLCondition struct {
+	Attribute string `json:"attribute"`
+	Operator  string `json:"operator,omitempty"`
+	Value     any    `json:"value"`
+}
+
+type buildTraceQLParams struct {
+	ServiceName string             `json:"serviceName,omitempty"`
+	SpanName    string             `json:"spanName,omitempty"`
+	Status      string             `json:"status,omitempty"`
+	MinDuration string             `json:"minDuration,omitempty"`
+	MaxDuration string             `json:"maxDuration,omitempty"`
+	Conditions  []traceQLCondition `json:"conditions,omitempty"`
+}
+
+// BuiltTraceQL is the output of build_traceql.
+type BuiltTraceQL struct {
+	TraceQL string `json:"traceql"`
+}
+
+// traceQLField returns the TraceQL field for an attribute name. Intrinsics and scoped names
+// (resource., span., or a leading dot) are kept; a bare name gets a leading dot to match any scope.
+func traceQLField(attribute string) (string, error) {
+	attribute = strings.TrimSpace(attribute)
+	if traceQLIntrinsics[attribute] {
+		return attribute, nil
+	}
+	if !traceQLAttributePattern.MatchString(attribute) {
+		return "", fmt.Errorf("invalid attribute %q (expected a name like 'http.status_code' or 'resource.service.name')", attribute)
+	}
+	if strings.HasPrefix(attribute, ".") || traceQLScoped(attribute) {
+		return attribute, nil
+	}
+	return "." + attribute, nil
+}
+
+// traceQLScoped reports whether an attribute name starts with an explicit scope such as "resource.".
+func traceQLScoped(attribute string) bool {
+	for _, scope := range []string{"resource.", "span.", "event.", "link.", "instrumentation."} {
+		if strings.HasPrefix(attribute, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// traceQLValue renders a condition value for field and operator: numbers and booleans bare, durations
+// bare for duration fields, status and kind keywords bare, and everything else as a quoted string.
+func traceQLValue(field, operator string, value any) (string, error) {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		if operator == "=~" || operator == "!~" {
+			if _, err := regexp.Compile(v); err != nil {
+				return "", fmt.Errorf("invalid regex %q for %s: %v", v, field, err)
+			}
+			return grafana.QuoteLabelValue(v), nil
+		}
+		if field == "duration" || field == "traceDuration" {
+			if _, err := time.ParseDuration(v); err != nil {
+				return "", fmt.Errorf("invalid duration %q for %s (expected e.g. 500ms, 2s)", v, field)
+			}
+			return v, nil
+		}
+		if keywords, ok := traceQLKeywords[field]; ok {
+			if !keywords[strings.ToLower(v)] {
+				return "", fmt.Errorf("invalid %s value %q", field, v)
+			}
+			return strings.ToLower(v), nil
+		}
+		// A numeric string compared with an ordering operator is almost certainly meant as a number
+		if strings.ContainsAny(operator, "<>") {
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return v, nil
+			}
+		}
+		return grafana.QuoteLabelValue(v), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v for %s (expected a string, number, or boolean)", value, field)
+	}
+}
+
+// buildTraceQL combines the structured filters into a single spanset selector joined with &&.
+func buildTraceQL(params buildTraceQLParams) (string, error) {
+	conditions := make([]traceQLCondition, 0, len(params.Conditions)+5)
+	if params.ServiceName != "" {
+		conditions = append(conditions, traceQLCondition{Attribute: "resource.service.name", Value: params.ServiceName})
+	}
+	if params.SpanName != "" {
+		conditions = append(conditions, traceQLCondition{Attribute: "name", Value: params.SpanName})
+	}
+	if params.Status != "" {
+		conditions = append(conditions, traceQLCondition{Attribute: "status", Value: params.Status})
+	}
+	if params.MinDuration != "" {
+		conditions = append(conditions, traceQLCondition{Attribute: "duration", Operator: ">=", Value: params.MinDuration})
+	}
+	if params.MaxDuration != "" {
+		conditions = append(conditions, traceQLCondition{Attribute: "duration", Operator: "<=", Value: params.MaxDuration})
+	}
+	conditions = append(conditions, params.Conditions...)
+
+	if len(conditions) == 0 {
+		return "{}", nil
+	}
+
+	parts := make([]string, 0, len(conditions))
+	for _, cond := range conditions {
+		operator := cond.Operator
+		if operator == "" {
+			operator = "="
+		}
+		if !traceQLOperators[operator] {
+			return "", fmt.Errorf("invalid operator %q (must be one of =, !=, =~, !~, >, >=, <, <=)", operator)
+		}
+
+		field, err := traceQLField(cond.Attribute)
+		if err != nil {
+			return "", err
+		}
+		value, err := traceQLValue(field, operator, cond.Value)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", field, operator, value))
+	}
+	return "{" + strings.Join(parts, " && ") + "}", nil
+}
+
+func buildTraceQLHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params buildTraceQLParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	traceql, err := buildTraceQL(params)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(BuiltTraceQL{TraceQL: traceql})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newBuildTraceQLTool() mcp.Tool {
+	return mcp.NewTool(
+		"build_traceql",
+		mcp.WithDescription("Builds a valid TraceQL span selector from structured filters, handling quoting, scopes, and operators, "+
+			"e.g., serviceName 'api' with status 'error' and minDuration '1s' gives "+
+			"'{resource.service.name = \"api\" && status = error && duration >= 1s}'. "+
+			"All filters must match the same span. Pass the result as the query of search_tempo_traces. Makes no request to Tempo."),
+		mcp.WithString("serviceName",
+			mcp.Description("Match spans of this service (resource.service.name)"),
+		),
+		mcp.WithString("spanName",
+			mcp.Description("Match spans with this name (operation), e.g., 'GET /api/users'"),
+		),
+		mcp.WithString("status",
+			mcp.Description("Match spans with this status: 'error', 'ok', or 'unset'"),
+		),
+		mcp.WithString("minDuration",
+			mcp.Description("Match spans at least this long, e.g., '500ms', '2s'"),
+		),
+		mcp.WithString("maxDuration",
+			mcp.Description("Match spans at most this long, e.g., '5s'"),
+		),
+		mcp.WithArray("conditions",
+			mcp.Description("Additional attribute conditions, each {attribute, operator, value}. "+
+				"attribute is a name like 'http.status_code' (any scope), 'span.http.route', or 'resource.k8s.namespace.name', or an intrinsic like 'kind'. "+
+				"operator is one of =, !=, =~, !~, >, >=, <, <= (default: =). value is a string, number, or boolean, "+
+				"e.g., [{\"attribute\": \"http.status_code\", \"operator\": \">=\", \"value\": 500}]"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"attribute": map[string]any{"type": "string"},
+					"operator":  map[string]any{"type": "string"},
+					"value":     map[string]any{"type": []string{"string", "number", "boolean"}},
+				},
+				"required": []string{"attribute", "value"},
+			}),
+		),
+	)
+}
+
+// RegisterBuildTraceQL registers the build_traceql tool.
+func RegisterBuildTraceQL(s grafana.ToolRegistry) {
+	s.AddTool(newBuildTraceQLTool(), buildTraceQLHandler)
+}
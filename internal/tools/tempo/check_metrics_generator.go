@@ -0,0 +1,110 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// metricsProbeQuery is a cheap TraceQL metrics query used to detect metrics support.
+const metricsProbeQuery = "{} | rate()"
+
+// MetricsCapability reports whether TraceQL metrics are available on a Tempo datasource.
+type MetricsCapability struct {
+	Available  bool   `json:"available"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Message    string `json:"message"`
+}
+
+type checkMetricsGeneratorParams struct {
+	DatasourceUID string `json:"datasourceUid"`
+}
+
+// checkMetricsCapability probes the TraceQL metrics endpoint with a short, cheap query
+// and interprets the response status. Tools that depend on the metrics-generator can call
+// this to turn a raw failure into a friendly explanation.
+func (c *client) checkMetricsCapability(ctx context.Context) (*MetricsCapability, error) {
+	now := time.Now().UTC()
+	params := url.Values{}
+	params.Add("q", metricsProbeQuery)
+	params.Add("start", fmt.Sprintf("%d", now.Add(-5*time.Minute).Unix()))
+	params.Add("end", fmt.Sprintf("%d", now.Unix()))
+	params.Add("step", "60")
+
+	statusCode, bodyBytes, err := c.doRequest(ctx, "GET", "/api/metrics/query_range", params)
+	if err != nil {
+		return nil, err
+	}
+
+	capability := &MetricsCapability{StatusCode: statusCode}
+	switch {
+	case statusCode == http.StatusOK:
+		capability.Available = true
+		capability.Message = "TraceQL metrics are available"
+	case statusCode == http.StatusNotFound || statusCode == http.StatusNotImplemented:
+		// Some Tempo versions and gateways answer 501 rather than 404 for the missing endpoint
+		capability.Message = "TraceQL metrics endpoint not found; this Tempo version likely predates TraceQL metrics (2.4+)"
+	case generatorDisabled(bodyBytes):
+		capability.Message = "TraceQL metrics are not enabled on this Tempo deployment; enable the metrics-generator local-blocks processor"
+	default:
+		// Auth failures, overloads, and query errors say nothing about the metrics-generator
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
+	}
+
+	return capability, nil
+}
+
+// generatorDisabled reports whether an error body is Tempo saying the metrics-generator is not enabled.
+func generatorDisabled(body []byte) bool {
+	msg := strings.ToLower(string(body))
+	return strings.Contains(msg, "metrics generator not enabled") || strings.Contains(msg, "metrics-generator not enabled")
+}
+
+func checkMetricsGeneratorHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params checkMetricsGeneratorParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
+	}
+
+	capability, err := c.checkMetricsCapability(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(capability)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newCheckMetricsGeneratorTool() mcp.Tool {
+	return mcp.NewTool(
+		"check_tempo_metrics_generator",
+		mcp.WithDescription("Checks whether TraceQL metrics (backed by Tempo's metrics-generator) are available on a Tempo datasource. "+
+			"Issues a cheap metrics query over the last 5 minutes and returns {available, statusCode, message}, "+
+			"where message explains why metrics are unavailable (older Tempo version or metrics-generator disabled). "+
+			"Any other failure, such as access denied, is returned as an error. "+
+			"Run this before relying on TraceQL metrics or service graph data."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Tempo datasource to check; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
+		),
+	)
+}
+
+// RegisterCheckMetricsGenerator registers the check_tempo_metrics_generator tool.
+func RegisterCheckMetricsGenerator(s grafana.ToolRegistry) {
+	s.AddTool(newCheckMetricsGeneratorTool(), checkMetricsGeneratorHandler)
+}
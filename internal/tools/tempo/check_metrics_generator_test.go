@@ -0,0 +1,56 @@
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckMetricsCapability(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantAvailable bool
+		wantErr       bool
+	}{
+		{name: "available", status: http.StatusOK, body: `{"series":[]}`, wantAvailable: true},
+		{name: "endpoint missing", status: http.StatusNotFound, body: "404 page not found"},
+		{name: "generator disabled", status: http.StatusBadRequest, body: "metrics generator not enabled for tenant"},
+		{name: "access denied", status: http.StatusForbidden, body: "forbidden", wantErr: true},
+		{name: "server error", status: http.StatusInternalServerError, body: "too many outstanding requests", wantErr: true},
+		{name: "not implemented", status: http.StatusNotImplemented, body: "unimplemented"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/metrics/query_range" {
+					t.Errorf("unexpected path %q", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := &client{httpClient: srv.Client(), baseURL: srv.URL}
+			capability, err := c.checkMetricsCapability(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", capability)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if capability.Available != tt.wantAvailable {
+				t.Errorf("available = %v, want %v", capability.Available, tt.wantAvailable)
+			}
+			if capability.StatusCode != tt.status {
+				t.Errorf("statusCode = %d, want %d", capability.StatusCode, tt.status)
+			}
+		})
+	}
+}
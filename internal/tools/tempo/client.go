@@ -4,8 +4,8 @@ package tempo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -19,16 +19,47 @@ const (
 
 	// MaxTraceLimit is the maximum number of traces that can be requested.
 	MaxTraceLimit = 100
+
+	// MaxSpansPerSpanSet is the maximum number of matched spans per trace a search may request.
+	MaxSpansPerSpanSet = 100
+
+	// DefaultMaxTraceBytes is the default size above which get_tempo_trace returns an overview instead of the trace.
+	DefaultMaxTraceBytes = 256 * 1024
 )
 
+// defaultWindow is how far back queries reach when no start time is given.
+// Override with TEMPO_DEFAULT_WINDOW (e.g., "15m", "6h").
+var defaultWindow = grafana.DurationFromEnv("TEMPO_DEFAULT_WINDOW", time.Hour)
+
+// maxRange caps how long a time range searches may cover. Set with TEMPO_MAX_RANGE (e.g., "168h");
+// unset means no limit.
+var maxRange = grafana.NewRangeLimit("TEMPO_MAX_RANGE")
+
+// maxTraceLimit caps the number of traces per search. Override with TEMPO_MAX_TRACE_LIMIT.
+var maxTraceLimit = grafana.IntFromEnv("TEMPO_MAX_TRACE_LIMIT", MaxTraceLimit)
+
+// maxTraceBytes caps the JSON size of a get_tempo_trace result. Override with TEMPO_MAX_TRACE_BYTES.
+var maxTraceBytes = grafana.IntFromEnv("TEMPO_MAX_TRACE_BYTES", DefaultMaxTraceBytes)
+
 // client provides methods for interacting with Tempo via Grafana's datasource proxy.
 type client struct {
 	httpClient *http.Client
 	baseURL    string
+	headers    http.Header // Extra headers sent with every request, e.g., X-Scope-OrgID
+
+	datasourceUID string // Resolved UID, after falling back to the default datasource
 }
 
 // newClient creates a new Tempo client for the given datasource UID.
-func newClient(datasourceUID string) (*client, error) {
+func newClient(ctx context.Context, datasourceUID string) (*client, error) {
+	datasourceUID, err := grafana.ResolveDatasourceUID(ctx, datasourceUID, "tempo")
+	if err != nil {
+		return nil, err
+	}
+	if err := grafana.CheckDatasource(datasourceUID); err != nil {
+		return nil, err
+	}
+
 	httpClient, grafanaURL, err := grafana.GetHTTPClientForGrafana()
 	if err != nil {
 		return nil, err
@@ -36,45 +67,48 @@ func newClient(datasourceUID string) (*client, error) {
 
 	baseURL := fmt.Sprintf("%s/api/datasources/proxy/uid/%s", grafanaURL, datasourceUID)
 	return &client{
-		httpClient: httpClient,
-		baseURL:    baseURL,
+		httpClient:    httpClient,
+		baseURL:       baseURL,
+		datasourceUID: datasourceUID,
 	}, nil
 }
 
 // makeRequest performs an HTTP request and returns the response body.
 func (c *client) makeRequest(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
-	reqURL := c.baseURL + path
-	if len(params) > 0 {
-		reqURL += "?" + params.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
+	statusCode, bodyBytes, err := c.doRequest(ctx, method, path, params)
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
 	}
 
 	return bodyBytes, nil
 }
 
+// doRequest performs an HTTP request and returns the status code and body without
+// treating non-200 responses as errors, for callers that interpret the status themselves.
+func (c *client) doRequest(ctx context.Context, method, path string, params url.Values) (int, []byte, error) {
+	return grafana.Do(ctx, c.httpClient, grafana.Request{
+		Method: method,
+		URL:    c.baseURL + path,
+		Params: params,
+		Header: c.headers,
+	})
+}
+
 // tagsResponse represents the response from the tags endpoint.
+// The v1 endpoint populates TagNames; the v2 endpoint groups tags into Scopes.
 type tagsResponse struct {
-	TagNames []string `json:"tagNames"`
-	Scopes   []string `json:"scopes,omitempty"`
+	TagNames []string   `json:"tagNames"`
+	Scopes   []tagScope `json:"scopes,omitempty"`
+}
+
+// tagScope represents a group of tag names sharing a scope (resource, span, intrinsic, ...).
+type tagScope struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
 }
 
 // fetchTagNames fetches tag names from Tempo.
@@ -104,6 +138,54 @@ func (c *client) fetchTagNames(ctx context.Context, scope, startUnix, endUnix st
 	return resp.TagNames, nil
 }
 
+// errTagsV2Unsupported is returned by fetchScopedTagNames when the Tempo version has no v2 tags endpoint.
+var errTagsV2Unsupported = errors.New("tempo has no v2 tags endpoint")
+
+// tagsV2Unsupported records the datasources whose v2 tags endpoint returned 404, so later listings go
+// straight to the v1 endpoint.
+var tagsV2Unsupported = grafana.NewCache[bool]("tempo_tags_v2_unsupported", 0)
+
+// fetchScopedTagNames fetches tag names grouped by scope from Tempo's v2 tags endpoint.
+// A 404 means the endpoint does not exist and comes back as errTagsV2Unsupported.
+func (c *client) fetchScopedTagNames(ctx context.Context, startUnix, endUnix string) (map[string][]string, error) {
+	params := url.Values{}
+
+	if startUnix != "" {
+		params.Add("start", startUnix)
+	}
+	if endUnix != "" {
+		params.Add("end", endUnix)
+	}
+
+	statusCode, bodyBytes, err := c.doRequest(ctx, "GET", "/api/v2/search/tags", params)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode == http.StatusNotFound {
+		tagsV2Unsupported.Set(c.datasourceUID, true)
+		return nil, errTagsV2Unsupported
+	}
+	if statusCode != http.StatusOK {
+		return nil, grafana.StatusError("API", statusCode, bodyBytes)
+	}
+
+	var resp tagsResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshalling tags response: %w", err)
+	}
+
+	grouped := make(map[string][]string, len(resp.Scopes))
+	for _, scope := range resp.Scopes {
+		tags := scope.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		grouped[scope.Name] = tags
+	}
+
+	return grouped, nil
+}
+
 // tagValuesResponse represents the response from the tag values endpoint.
 type tagValuesResponse struct {
 	TagValues []string `json:"tagValues"`
@@ -134,10 +216,52 @@ func (c *client) fetchTagValues(ctx context.Context, tagName, startUnix, endUnix
 	return resp.TagValues, nil
 }
 
+// TypedTagValue represents a tag value with its type, as returned by the v2 tag values endpoint.
+type TypedTagValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// tagValuesV2Response represents the response from the v2 tag values endpoint.
+type tagValuesV2Response struct {
+	TagValues []TypedTagValue `json:"tagValues"`
+}
+
+// fetchTagValuesV2 fetches typed values for a tag from Tempo's v2 endpoint,
+// optionally scoped to spans matching a TraceQL filter.
+func (c *client) fetchTagValuesV2(ctx context.Context, tagName, query, startUnix, endUnix string) ([]TypedTagValue, error) {
+	params := url.Values{}
+
+	if query != "" {
+		params.Add("q", query)
+	}
+	if startUnix != "" {
+		params.Add("start", startUnix)
+	}
+	if endUnix != "" {
+		params.Add("end", endUnix)
+	}
+
+	path := fmt.Sprintf("/api/v2/search/tag/%s/values", url.PathEscape(tagName))
+	bodyBytes, err := c.makeRequest(ctx, "GET", path, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp tagValuesV2Response
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshalling tag values response: %w", err)
+	}
+
+	return resp.TagValues, nil
+}
+
 // SearchResponse represents the response from the search endpoint.
 type SearchResponse struct {
-	Traces  []TraceSearchResult `json:"traces"`
-	Metrics *SearchMetrics      `json:"metrics,omitempty"`
+	Traces          []TraceSearchResult `json:"traces"`
+	Metrics         *SearchMetrics      `json:"metrics,omitempty"`
+	DurationSummary *DurationSummary    `json:"durationSummary,omitempty"` // Computed locally, not part of Tempo's response
+	Warnings        []string            `json:"warnings,omitempty"`        // Added locally, e.g., about sub-windows an auto-sharded search skipped
 }
 
 // TraceSearchResult represents a single trace in search results.
@@ -180,8 +304,16 @@ type SearchMetrics struct {
 	InspectedBytes  grafana.Uint64String `json:"inspectedBytes,omitempty"`  // uint64 in proto → JSON string
 }
 
-// searchTraces searches for traces using TraceQL.
-func (c *client) searchTraces(ctx context.Context, query, startUnix, endUnix string, limit int) (*SearchResponse, error) {
+// searchOptions holds the optional search parameters; zero values leave Tempo's defaults in place.
+type searchOptions struct {
+	SpansPerSpanSet int           // spss: matched spans returned per trace (Tempo defaults to 3)
+	MinDuration     time.Duration // Only traces at least this long
+	MaxDuration     time.Duration // Only traces at most this long
+}
+
+// searchTraces searches for traces using TraceQL. Tempo's v2 API only covers tag names and values
+// (see fetchScopedTagNames), so trace search has a single version, /api/search, and there is nothing to select.
+func (c *client) searchTraces(ctx context.Context, query, startUnix, endUnix string, limit int, opts searchOptions) (*SearchResponse, error) {
 	params := url.Values{}
 
 	if query != "" {
@@ -196,6 +328,15 @@ func (c *client) searchTraces(ctx context.Context, query, startUnix, endUnix str
 	if limit > 0 {
 		params.Add("limit", fmt.Sprintf("%d", limit))
 	}
+	if opts.SpansPerSpanSet > 0 {
+		params.Add("spss", fmt.Sprintf("%d", opts.SpansPerSpanSet))
+	}
+	if opts.MinDuration > 0 {
+		params.Add("minDuration", opts.MinDuration.String())
+	}
+	if opts.MaxDuration > 0 {
+		params.Add("maxDuration", opts.MaxDuration.String())
+	}
 
 	bodyBytes, err := c.makeRequest(ctx, "GET", "/api/search", params)
 	if err != nil {
@@ -207,9 +348,32 @@ func (c *client) searchTraces(ctx context.Context, query, startUnix, endUnix str
 		return nil, fmt.Errorf("unmarshalling search response: %w", err)
 	}
 
+	// Older Tempo versions ignore the duration params, so enforce them on the results as well
+	resp.Traces = filterByDuration(resp.Traces, opts.MinDuration, opts.MaxDuration)
+
 	return &resp, nil
 }
 
+// filterByDuration drops traces outside [minDuration, maxDuration]; a zero bound is open.
+func filterByDuration(traces []TraceSearchResult, minDuration, maxDuration time.Duration) []TraceSearchResult {
+	if minDuration <= 0 && maxDuration <= 0 {
+		return traces
+	}
+
+	filtered := make([]TraceSearchResult, 0, len(traces))
+	for _, t := range traces {
+		d := time.Duration(t.DurationMs) * time.Millisecond
+		if minDuration > 0 && d < minDuration {
+			continue
+		}
+		if maxDuration > 0 && d > maxDuration {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
+}
+
 // getTrace retrieves a trace by its ID.
 func (c *client) getTrace(ctx context.Context, traceID string) (any, error) {
 	path := fmt.Sprintf("/api/traces/%s", url.PathEscape(traceID))
@@ -226,33 +390,33 @@ func (c *client) getTrace(ctx context.Context, traceID string) (any, error) {
 	return trace, nil
 }
 
-// getDefaultTimeRange returns default start/end times if not specified (last 1 hour).
-// Returns Unix epoch seconds as strings.
-func getDefaultTimeRange(startRFC3339, endRFC3339 string) (string, string, error) {
-	now := time.Now().UTC()
-	var startUnix, endUnix string
-
+// getDefaultTimeRange returns default start/end times if not specified (last defaultWindow, 1 hour unless overridden),
+// with the start clamped to maxRange and a notice if it was. Returns Unix epoch seconds as strings.
+func getDefaultTimeRange(startRFC3339, endRFC3339 string) (string, string, string, error) {
+	endTime := time.Now().UTC()
 	if endRFC3339 != "" {
-		endTime, err := time.Parse(time.RFC3339, endRFC3339)
+		t, err := time.Parse(time.RFC3339, endRFC3339)
 		if err != nil {
-			return "", "", fmt.Errorf("parsing end time: %w", err)
+			return "", "", "", fmt.Errorf("parsing end time: %w", err)
 		}
-		endUnix = fmt.Sprintf("%d", endTime.Unix())
-	} else {
-		endUnix = fmt.Sprintf("%d", now.Unix())
+		endTime = t
 	}
 
+	startTime := time.Now().UTC().Add(-defaultWindow)
 	if startRFC3339 != "" {
-		startTime, err := time.Parse(time.RFC3339, startRFC3339)
+		t, err := time.Parse(time.RFC3339, startRFC3339)
 		if err != nil {
-			return "", "", fmt.Errorf("parsing start time: %w", err)
+			return "", "", "", fmt.Errorf("parsing start time: %w", err)
 		}
-		startUnix = fmt.Sprintf("%d", startTime.Unix())
-	} else {
-		startUnix = fmt.Sprintf("%d", now.Add(-1*time.Hour).Unix())
+		startTime = t
+	}
+
+	startTime, notice, err := maxRange.Clamp(startTime, endTime)
+	if err != nil {
+		return "", "", "", err
 	}
 
-	return startUnix, endUnix, nil
+	return fmt.Sprintf("%d", startTime.Unix()), fmt.Sprintf("%d", endTime.Unix()), notice, nil
 }
 
 // enforceTraceLimit ensures the limit is within bounds.
@@ -260,8 +424,8 @@ func enforceTraceLimit(requestedLimit int) int {
 	if requestedLimit <= 0 {
 		return DefaultTraceLimit
 	}
-	if requestedLimit > MaxTraceLimit {
-		return MaxTraceLimit
+	if requestedLimit > maxTraceLimit {
+		return maxTraceLimit
 	}
 	return requestedLimit
 }
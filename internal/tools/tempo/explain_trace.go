@@ -0,0 +1,238 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// statusCodeError is the OTLP status code of a failed span.
+const statusCodeError = "STATUS_CODE_ERROR"
+
+// maxExplainedErrors caps how many error spans explain_trace lists.
+const maxExplainedErrors = 10
+
+type explainTraceParams struct {
+	DatasourceUID string            `json:"datasourceUid"`
+	TraceID       string            `json:"traceId"`
+	TenantID      string            `json:"tenantId,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// RootOperation is the root span of a trace.
+type RootOperation struct {
+	Name        string `json:"name"`
+	ServiceName string `json:"serviceName,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
+// SlowestSpan is the span with the most self-time, i.e., time not spent waiting on its children.
+type SlowestSpan struct {
+	SpanID       string  `json:"spanId"`
+	Name         string  `json:"name"`
+	ServiceName  string  `json:"serviceName,omitempty"`
+	DurationMs   float64 `json:"durationMs"`
+	SelfTimeMs   float64 `json:"selfTimeMs"`
+	SharePercent float64 `json:"sharePercent"` // Self-time as a share of the trace duration
+}
+
+// ErrorSpan is a span with an error status, with the exception recorded on it if there is one.
+type ErrorSpan struct {
+	SpanID           string `json:"spanId"`
+	Name             string `json:"name"`
+	ServiceName      string `json:"serviceName,omitempty"`
+	StatusMessage    string `json:"statusMessage,omitempty"`
+	ExceptionType    string `json:"exceptionType,omitempty"`
+	ExceptionMessage string `json:"exceptionMessage,omitempty"`
+}
+
+// TraceExplanation is the output of explain_trace.
+type TraceExplanation struct {
+	TraceID        string             `json:"traceId"`
+	Summary        string             `json:"summary"`
+	DurationMs     float64            `json:"durationMs"`
+	SpanCount      int                `json:"spanCount"`
+	RootOperation  RootOperation      `json:"rootOperation"`
+	ServiceCount   int                `json:"serviceCount"`
+	Services       []string           `json:"services"`
+	SlowestSpan    *SlowestSpan       `json:"slowestSpan,omitempty"`
+	ErrorSpanCount int                `json:"errorSpanCount"`
+	ErrorSpans     []ErrorSpan        `json:"errorSpans,omitempty"` // At most maxExplainedErrors, in trace order
+	CriticalPath   []CriticalPathSpan `json:"criticalPath"`
+}
+
+// newErrorSpan describes a failed span, taking the exception from its last exception event.
+func newErrorSpan(span *TraceSpan) ErrorSpan {
+	e := ErrorSpan{
+		SpanID:        span.SpanID,
+		Name:          span.Name,
+		ServiceName:   span.ServiceName,
+		StatusMessage: span.StatusMessage,
+	}
+	for _, event := range span.Events {
+		if event.Name != "exception" {
+			continue
+		}
+		e.ExceptionType, _ = event.Attributes["exception.type"].(string)
+		e.ExceptionMessage, _ = event.Attributes["exception.message"].(string)
+	}
+	return e
+}
+
+// explainTree derives the explanation of a decoded trace.
+func explainTree(traceID string, tree *SpanTree) TraceExplanation {
+	// A trace always has a root unless its parent links form a cycle
+	candidates := tree.Roots
+	if len(candidates) == 0 {
+		candidates = tree.Spans
+	}
+	root := candidates[0]
+	for _, r := range candidates[1:] {
+		if r.endNano-r.startNano > root.endNano-root.startNano {
+			root = r
+		}
+	}
+
+	services := tree.Services()
+	result := TraceExplanation{
+		TraceID:       traceID,
+		DurationMs:    tree.DurationMs(),
+		SpanCount:     len(tree.Spans),
+		RootOperation: RootOperation{Name: root.Name, ServiceName: root.ServiceName, Status: root.Status},
+		ServiceCount:  len(services),
+		Services:      services,
+		CriticalPath:  []CriticalPathSpan{},
+	}
+
+	var slowest *TraceSpan
+	var slowestSelf int64
+	for _, span := range tree.Spans {
+		if self := span.selfTimeNano(); slowest == nil || self > slowestSelf {
+			slowest, slowestSelf = span, self
+		}
+		if span.Status == statusCodeError {
+			result.ErrorSpanCount++
+			if len(result.ErrorSpans) < maxExplainedErrors {
+				result.ErrorSpans = append(result.ErrorSpans, newErrorSpan(span))
+			}
+		}
+	}
+	if slowest != nil {
+		result.SlowestSpan = &SlowestSpan{
+			SpanID:      slowest.SpanID,
+			Name:        slowest.Name,
+			ServiceName: slowest.ServiceName,
+			DurationMs:  slowest.DurationMs,
+			SelfTimeMs:  nanosToMs(slowestSelf),
+		}
+		if total := tree.EndNano - tree.StartNano; total > 0 {
+			result.SlowestSpan.SharePercent = float64(slowestSelf) * 100 / float64(total)
+		}
+	}
+
+	for _, span := range tree.criticalPath() {
+		result.CriticalPath = append(result.CriticalPath, newCriticalPathSpan(span))
+	}
+
+	result.Summary = summarizeExplanation(result)
+	return result
+}
+
+// summarizeExplanation renders the key facts of an explanation as a short sentence or two.
+func summarizeExplanation(e TraceExplanation) string {
+	var b strings.Builder
+	root := e.RootOperation.Name
+	if e.RootOperation.ServiceName != "" {
+		root = fmt.Sprintf("%s (%s)", root, e.RootOperation.ServiceName)
+	}
+	fmt.Fprintf(&b, "%s took %.1fms across %d spans in %d services.", root, e.DurationMs, e.SpanCount, e.ServiceCount)
+
+	if e.SlowestSpan != nil {
+		fmt.Fprintf(&b, " Most time was spent in %s", e.SlowestSpan.Name)
+		if e.SlowestSpan.ServiceName != "" {
+			fmt.Fprintf(&b, " (%s)", e.SlowestSpan.ServiceName)
+		}
+		fmt.Fprintf(&b, ": %.1fms of self-time, %.0f%% of the trace.", e.SlowestSpan.SelfTimeMs, e.SlowestSpan.SharePercent)
+	}
+
+	if e.ErrorSpanCount == 0 {
+		b.WriteString(" No spans failed.")
+	} else {
+		first := e.ErrorSpans[0]
+		fmt.Fprintf(&b, " %d span(s) failed, first %s", e.ErrorSpanCount, first.Name)
+		if first.ExceptionType != "" {
+			fmt.Fprintf(&b, " with %s", first.ExceptionType)
+		}
+		if msg := first.ExceptionMessage; msg != "" {
+			fmt.Fprintf(&b, ": %s", msg)
+		} else if msg := first.StatusMessage; msg != "" {
+			fmt.Fprintf(&b, ": %s", msg)
+		}
+		b.WriteString(".")
+	}
+
+	return b.String()
+}
+
+func explainTraceHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params explainTraceParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	params.TraceID = strings.TrimSpace(params.TraceID)
+	if params.TraceID == "" {
+		return mcp.NewToolResultError("traceId is required"), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
+	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	tree, err := c.fetchSpanTree(ctx, params.TraceID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(explainTree(params.TraceID, tree))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newExplainTraceTool() mcp.Tool {
+	return mcp.NewTool(
+		"explain_trace",
+		mcp.WithDescription("Explains what happened in a trace in one call: a short summary sentence plus the total duration, root operation, "+
+			"services involved, the span with the most self-time and its share of the trace, "+
+			"error spans with their exception type and message, and the critical path. "+
+			"Use this to answer 'what happened in this request' before drilling in with get_tempo_trace."),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Tempo datasource to query; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
+		),
+		mcp.WithString("traceId",
+			mcp.Description("The trace ID to explain (32-character hex string)"),
+			mcp.Required(),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+	)
+}
+
+// RegisterExplainTrace registers the explain_trace tool.
+func RegisterExplainTrace(s grafana.ToolRegistry) {
+	s.AddTool(newExplainTraceTool(), explainTraceHandler)
+}
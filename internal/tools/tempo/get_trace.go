@@ -4,14 +4,171 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type getTraceParams struct {
-	DatasourceUID string `json:"datasourceUid"`
-	TraceID       string `json:"traceId"`
+	DatasourceUID    string            `json:"datasourceUid"`
+	TraceID          string            `json:"traceId"`
+	CriticalPathOnly bool              `json:"criticalPathOnly,omitempty"`
+	MaxDepth         int               `json:"maxDepth,omitempty"`
+	MaxSpans         int               `json:"maxSpans,omitempty"`
+	GroupBySpanName  bool              `json:"groupBySpanName,omitempty"`
+	Fields           []string          `json:"fields,omitempty"`
+	TenantID         string            `json:"tenantId,omitempty"`
+	Headers          map[string]string `json:"headers,omitempty"`
+}
+
+// CriticalPathSpan is a span on a trace's critical path.
+type CriticalPathSpan struct {
+	SpanID        string  `json:"spanId"`
+	Name          string  `json:"name"`
+	ServiceName   string  `json:"serviceName,omitempty"`
+	StartOffsetMs float64 `json:"startOffsetMs"`
+	DurationMs    float64 `json:"durationMs"`
+	Status        string  `json:"status,omitempty"`
+}
+
+// newCriticalPathSpan summarizes a span on the critical path.
+func newCriticalPathSpan(span *TraceSpan) CriticalPathSpan {
+	return CriticalPathSpan{
+		SpanID:        span.SpanID,
+		Name:          span.Name,
+		ServiceName:   span.ServiceName,
+		StartOffsetMs: span.StartOffsetMs,
+		DurationMs:    span.DurationMs,
+		Status:        span.Status,
+	}
+}
+
+// CriticalPathResult is the output of get_tempo_trace in criticalPathOnly mode.
+type CriticalPathResult struct {
+	TraceID      string             `json:"traceId"`
+	SpanCount    int                `json:"spanCount"`
+	DurationMs   float64            `json:"durationMs"`
+	CriticalPath []CriticalPathSpan `json:"criticalPath"`
+}
+
+// SpanNameSelfTime is the self-time of all spans sharing an operation name.
+type SpanNameSelfTime struct {
+	Name        string   `json:"name"`
+	Services    []string `json:"services"`
+	SpanCount   int      `json:"spanCount"`
+	SelfTimeMs  float64  `json:"selfTimeMs"`
+	SelfPercent float64  `json:"selfPercent"` // Share of the summed self-time of all spans
+	TotalTimeMs float64  `json:"totalTimeMs"` // Summed span durations, including time in children
+}
+
+// SpanNameResult is the output of get_tempo_trace in groupBySpanName mode.
+type SpanNameResult struct {
+	TraceID    string             `json:"traceId"`
+	SpanCount  int                `json:"spanCount"`
+	DurationMs float64            `json:"durationMs"`
+	Operations []SpanNameSelfTime `json:"operations"` // Highest self-time first
+}
+
+// groupSelfTimeBySpanName sums self-time per span name across the whole trace.
+func groupSelfTimeBySpanName(tree *SpanTree) []SpanNameSelfTime {
+	groups := make(map[string]*SpanNameSelfTime)
+	services := make(map[string]map[string]bool)
+	selfNanos := make(map[string]int64)
+	var totalSelf int64
+
+	for _, span := range tree.Spans {
+		g, ok := groups[span.Name]
+		if !ok {
+			g = &SpanNameSelfTime{Name: span.Name, Services: []string{}}
+			groups[span.Name] = g
+			services[span.Name] = make(map[string]bool)
+		}
+		self := span.selfTimeNano()
+		g.SpanCount++
+		g.TotalTimeMs += span.DurationMs
+		selfNanos[span.Name] += self
+		totalSelf += self
+		if span.ServiceName != "" && !services[span.Name][span.ServiceName] {
+			services[span.Name][span.ServiceName] = true
+			g.Services = append(g.Services, span.ServiceName)
+		}
+	}
+
+	operations := make([]SpanNameSelfTime, 0, len(groups))
+	for name, g := range groups {
+		g.SelfTimeMs = nanosToMs(selfNanos[name])
+		if totalSelf > 0 {
+			g.SelfPercent = float64(selfNanos[name]) * 100 / float64(totalSelf)
+		}
+		sort.Strings(g.Services)
+		operations = append(operations, *g)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].SelfTimeMs != operations[j].SelfTimeMs {
+			return operations[i].SelfTimeMs > operations[j].SelfTimeMs
+		}
+		return operations[i].Name < operations[j].Name
+	})
+
+	return operations
+}
+
+// overviewDepth is how many levels of the span tree an oversized trace's overview keeps.
+const overviewDepth = 2
+
+// SpanTreeResult is the output of get_tempo_trace when maxDepth is set.
+type SpanTreeResult struct {
+	TraceID    string       `json:"traceId"`
+	SpanCount  int          `json:"spanCount"`
+	DurationMs float64      `json:"durationMs"`
+	MaxDepth   int          `json:"maxDepth"`
+	Spans      []*TraceSpan `json:"spans"` // Root spans, with children nested up to maxDepth levels
+}
+
+// PrunedTraceResult is the output of get_tempo_trace when maxSpans is set.
+type PrunedTraceResult struct {
+	TraceID      string       `json:"traceId"`
+	SpanCount    int          `json:"spanCount"`
+	DurationMs   float64      `json:"durationMs"`
+	MaxSpans     int          `json:"maxSpans"`
+	OmittedSpans int          `json:"omittedSpans"`
+	Spans        []*TraceSpan `json:"spans"` // Root spans, with only the kept spans nested below
+}
+
+// TraceOverview replaces a trace whose JSON exceeds the size limit.
+type TraceOverview struct {
+	TraceID    string       `json:"traceId"`
+	SpanCount  int          `json:"spanCount"`
+	DurationMs float64      `json:"durationMs"`
+	SizeBytes  int          `json:"sizeBytes"`
+	Services   []string     `json:"services"`
+	Spans      []*TraceSpan `json:"spans"` // Top levels of the span tree, without attributes
+	Note       string       `json:"note"`
+}
+
+// newTraceOverview summarizes a span tree whose full output would have been sizeBytes long.
+func newTraceOverview(traceID string, tree *SpanTree, sizeBytes int) TraceOverview {
+	return TraceOverview{
+		TraceID:    traceID,
+		SpanCount:  len(tree.Spans),
+		DurationMs: tree.DurationMs(),
+		SizeBytes:  sizeBytes,
+		Services:   tree.Services(),
+		Spans:      truncateSpans(tree.Roots, overviewDepth, false),
+		Note: fmt.Sprintf("The trace is %d bytes, over the %d byte limit, so only the top %d levels of spans are shown. "+
+			"For span detail, call again with criticalPathOnly, a maxDepth, or fields to select specific span fields.",
+			sizeBytes, maxTraceBytes, overviewDepth),
+	}
+}
+
+// decodeTree converts a raw trace response into a span tree.
+func decodeTree(traceID string, trace any) (*SpanTree, error) {
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return nil, fmt.Errorf("encoding trace: %w", err)
+	}
+	return DecodeTrace(traceID, data)
 }
 
 func getTraceHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -23,22 +180,134 @@ func getTraceHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	if params.TraceID == "" {
 		return mcp.NewToolResultError("traceId is required"), nil
 	}
+	if params.MaxDepth < 0 {
+		return mcp.NewToolResultError("maxDepth must be positive"), nil
+	}
+	if params.MaxSpans < 0 {
+		return mcp.NewToolResultError("maxSpans must be positive"), nil
+	}
+	if params.MaxSpans > 0 && params.MaxDepth > 0 {
+		return mcp.NewToolResultError("maxSpans and maxDepth cannot be combined"), nil
+	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
 	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if params.GroupBySpanName {
+		tree, err := c.fetchSpanTree(ctx, params.TraceID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result := SpanNameResult{
+			TraceID:    params.TraceID,
+			SpanCount:  len(tree.Spans),
+			DurationMs: tree.DurationMs(),
+			Operations: groupSelfTimeBySpanName(tree),
+		}
+
+		projected, err := grafana.ProjectFields(result, params.Fields)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		jsonData, err := grafana.MarshalJSON(projected)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+
+	if params.CriticalPathOnly {
+		tree, err := c.fetchSpanTree(ctx, params.TraceID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result := CriticalPathResult{
+			TraceID:      params.TraceID,
+			SpanCount:    len(tree.Spans),
+			DurationMs:   tree.DurationMs(),
+			CriticalPath: []CriticalPathSpan{},
+		}
+		for _, span := range tree.criticalPath() {
+			result.CriticalPath = append(result.CriticalPath, newCriticalPathSpan(span))
+		}
+
+		projected, err := grafana.ProjectFields(result, params.Fields)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		jsonData, err := grafana.MarshalJSON(projected)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
 
 	trace, err := c.getTrace(ctx, params.TraceID)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	jsonData, err := json.MarshalIndent(trace, "", "  ")
+	var tree *SpanTree
+	var result any = trace
+	if params.MaxDepth > 0 {
+		if tree, err = decodeTree(params.TraceID, trace); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result = SpanTreeResult{
+			TraceID:    params.TraceID,
+			SpanCount:  len(tree.Spans),
+			DurationMs: tree.DurationMs(),
+			MaxDepth:   params.MaxDepth,
+			Spans:      truncateSpans(tree.Roots, params.MaxDepth, true),
+		}
+	}
+	if params.MaxSpans > 0 {
+		if tree, err = decodeTree(params.TraceID, trace); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		spans, omitted := tree.pruneSpans(params.MaxSpans)
+		result = PrunedTraceResult{
+			TraceID:      params.TraceID,
+			SpanCount:    len(tree.Spans),
+			DurationMs:   tree.DurationMs(),
+			MaxSpans:     params.MaxSpans,
+			OmittedSpans: omitted,
+			Spans:        spans,
+		}
+	}
+
+	projected, err := grafana.ProjectFields(result, params.Fields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	jsonData, err := grafana.MarshalJSON(projected)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
 
+	// A deep trace can run to megabytes; fall back to an overview rather than flooding the context
+	if len(jsonData) > maxTraceBytes {
+		if tree == nil {
+			if tree, err = decodeTree(params.TraceID, trace); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		jsonData, err = grafana.MarshalJSON(newTraceOverview(params.TraceID, tree, len(jsonData)))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+		}
+	}
+
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
@@ -47,19 +316,52 @@ func newGetTraceTool() mcp.Tool {
 		"get_tempo_trace",
 		mcp.WithDescription("Retrieves a complete trace by its trace ID from a Tempo datasource. "+
 			"Returns the full trace data including all spans, their attributes, and timing information. "+
+			"Set criticalPathOnly to instead return the total span count and only the chain of spans on the critical path "+
+			"(from the root, repeatedly following the child that finished last), which shows what actually made the request slow. "+
+			"Set groupBySpanName to instead rank operations by self-time (span duration minus time covered by its children), "+
+			"summed per span name, to see which operation the trace spends most of its time in. "+
+			"Set maxDepth to instead return the decoded span tree cut off below that many levels, "+
+			"including each span's events (logs and exceptions, with exception.type and exception.message attributes). "+
+			"Set maxSpans to instead return the span tree cut down to that many spans, keeping the critical path, then error spans, "+
+			"then the longest spans, with the number of omitted spans reported; use it for traces with thousands of spans. "+
+			"Traces whose output exceeds the size limit are replaced by an overview of the top of the span tree. "+
 			"Use search_tempo_traces first to find trace IDs of interest."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Tempo datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Tempo datasource to query; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
 		),
 		mcp.WithString("traceId",
 			mcp.Description("The trace ID to retrieve (32-character hex string)"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("criticalPathOnly",
+			mcp.Description("Return only the span count and the critical path spans instead of the full trace (default: false)"),
+		),
+		mcp.WithBoolean("groupBySpanName",
+			mcp.Description("Return self-time aggregated per span name, highest first, instead of the trace (default: false)"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Return the span tree nested at most this many levels deep (1 = root spans only), "+
+				"with each span's events and the number of omitted descendants on each cut-off span"),
+		),
+		mcp.WithNumber("maxSpans",
+			mcp.Description("Return the span tree with at most this many spans, prioritizing the critical path and error spans; "+
+				"each span reports how many spans below it were omitted (cannot be combined with maxDepth)"),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Optional list of fields to return, as top-level keys or dotted paths that descend through arrays "+
+				"(e.g., [\"batches.scopeSpans.spans.name\", \"batches.scopeSpans.spans.spanId\"])"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
 	)
 }
 
 // RegisterGetTrace registers the get_tempo_trace tool.
-func RegisterGetTrace(s *server.MCPServer) {
+func RegisterGetTrace(s grafana.ToolRegistry) {
 	s.AddTool(newGetTraceTool(), getTraceHandler)
 }
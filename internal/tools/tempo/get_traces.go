@@ -0,0 +1,168 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MaxBulkTraces is the maximum number of traces get_tempo_traces fetches in one call.
+const MaxBulkTraces = 10
+
+type getTracesParams struct {
+	DatasourceUID string            `json:"datasourceUid"`
+	TraceIDs      []string          `json:"traceIds"`
+	SummaryOnly   bool              `json:"summaryOnly,omitempty"`
+	TenantID      string            `json:"tenantId,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// BulkTraceResult is the outcome of fetching one trace; exactly one of Trace, Summary, and Error is set.
+type BulkTraceResult struct {
+	Trace   any               `json:"trace,omitempty"`   // Full trace, or a TraceOverview when over the size limit
+	Summary *TraceExplanation `json:"summary,omitempty"` // Set in summaryOnly mode
+	Error   string            `json:"error,omitempty"`
+}
+
+// fetchBulkTrace fetches one trace as a full trace or, with summaryOnly, as an explanation.
+func (c *client) fetchBulkTrace(ctx context.Context, traceID string, summaryOnly bool) BulkTraceResult {
+	if summaryOnly {
+		tree, err := c.fetchSpanTree(ctx, traceID)
+		if err != nil {
+			return BulkTraceResult{Error: err.Error()}
+		}
+		explanation := explainTree(traceID, tree)
+		return BulkTraceResult{Summary: &explanation}
+	}
+
+	trace, err := c.getTrace(ctx, traceID)
+	if err != nil {
+		return BulkTraceResult{Error: err.Error()}
+	}
+
+	// Apply the same size guard as get_tempo_trace to each trace
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return BulkTraceResult{Error: fmt.Sprintf("encoding trace: %v", err)}
+	}
+	if len(data) > maxTraceBytes {
+		tree, err := decodeTree(traceID, trace)
+		if err != nil {
+			return BulkTraceResult{Error: err.Error()}
+		}
+		return BulkTraceResult{Trace: newTraceOverview(traceID, tree, len(data))}
+	}
+	return BulkTraceResult{Trace: trace}
+}
+
+// distinctTraceIDs trims the requested IDs and drops repeats, keeping the requested order, so each
+// trace is fetched once. A blank ID is an error rather than a request for /api/traces/.
+func distinctTraceIDs(ids []string) ([]string, error) {
+	seen := make(map[string]bool, len(ids))
+	distinct := make([]string, 0, len(ids))
+	for i, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, fmt.Errorf("traceIds[%d] is empty", i)
+		}
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct, nil
+}
+
+func getTracesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params getTracesParams
+	if err := request.BindArguments(&params); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
+	}
+
+	if len(params.TraceIDs) == 0 {
+		return mcp.NewToolResultError("traceIds must contain at least one trace ID"), nil
+	}
+	if len(params.TraceIDs) > MaxBulkTraces {
+		return mcp.NewToolResultError(fmt.Sprintf("traceIds may contain at most %d trace IDs", MaxBulkTraces)), nil
+	}
+
+	traceIDs, err := distinctTraceIDs(params.TraceIDs)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	c, err := newClient(ctx, params.DatasourceUID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
+	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// One missing or oversized trace should not fail the whole call, so errors are reported per trace
+	fetched := make([]BulkTraceResult, len(traceIDs))
+	sem := make(chan struct{}, grafana.MaxConcurrency())
+	var wg sync.WaitGroup
+
+	for i, traceID := range traceIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			fetched[i] = c.fetchBulkTrace(ctx, traceID, params.SummaryOnly)
+		}()
+	}
+	wg.Wait()
+
+	results := make(map[string]BulkTraceResult, len(traceIDs))
+	for i, traceID := range traceIDs {
+		results[traceID] = fetched[i]
+	}
+
+	jsonData, err := grafana.MarshalJSON(results)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func newGetTracesTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_tempo_traces",
+		mcp.WithDescription(fmt.Sprintf("Fetches up to %d traces by ID from a Tempo datasource concurrently, "+
+			"returning a map of traceId to {trace} or {error}, so one missing trace does not fail the others. "+
+			"Each trace is the full trace as returned by get_tempo_trace, or an overview when it exceeds the size limit. "+
+			"Set summaryOnly to return each trace's explain_trace summary ({summary}) instead, which is far smaller. "+
+			"Use after search_tempo_traces to inspect the top few results in one call.", MaxBulkTraces)),
+		mcp.WithString("datasourceUid",
+			mcp.Description("The UID of the Tempo datasource to query; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
+		),
+		mcp.WithArray("traceIds",
+			mcp.Description(fmt.Sprintf("Trace IDs to fetch (32-character hex strings, at most %d)", MaxBulkTraces)),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("summaryOnly",
+			mcp.Description("Return a summary of each trace (duration, services, slowest span, errors, critical path) instead of the full trace (default: false)"),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+	)
+}
+
+// RegisterGetTraces registers the get_tempo_traces tool.
+func RegisterGetTraces(s grafana.ToolRegistry) {
+	s.AddTool(newGetTracesTool(), getTracesHandler)
+}
@@ -2,11 +2,12 @@ package tempo
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listTagNamesParams struct {
@@ -22,26 +23,36 @@ func listTagNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
 	}
 
-	startUnix, endUnix, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startUnix, endUnix, notice, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	tagNames, err := c.fetchTagNames(ctx, params.Scope, startUnix, endUnix)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
+	// Only listings over the default window are cached; an explicit range asks for those exact times
+	cacheable := params.StartRFC3339 == "" && params.EndRFC3339 == ""
+	cacheKey := c.datasourceUID + "|" + params.Scope
 
-	if len(tagNames) == 0 {
-		tagNames = []string{}
+	var names any
+	cached := false
+	if cacheable {
+		names, cached = tagNamesCache.Get(cacheKey)
+	}
+	if !cached {
+		names, err = c.listTagNames(ctx, params.Scope, startUnix, endUnix)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if cacheable {
+			tagNamesCache.Set(cacheKey, names)
+		}
 	}
 
-	jsonData, err := json.MarshalIndent(tagNames, "", "  ")
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(names, notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -49,22 +60,54 @@ func listTagNamesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
+// listTagNames returns the tag names in scope, or without a scope, tag names grouped by scope from
+// the v2 endpoint. Tempo versions without the v2 endpoint get the flat v1 list instead.
+func (c *client) listTagNames(ctx context.Context, scope, startUnix, endUnix string) (any, error) {
+	if _, unsupported := tagsV2Unsupported.Get(c.datasourceUID); scope == "" && !unsupported {
+		grouped, err := c.fetchScopedTagNames(ctx, startUnix, endUnix)
+		if err == nil && len(grouped) > 0 {
+			return grouped, nil
+		}
+		if err != nil && !errors.Is(err, errTagsV2Unsupported) {
+			return nil, err
+		}
+	}
+
+	tagNames, err := c.fetchTagNames(ctx, scope, startUnix, endUnix)
+	if err != nil {
+		return nil, err
+	}
+	if len(tagNames) == 0 {
+		tagNames = []string{}
+	}
+	return tagNames, nil
+}
+
+// tagNamesTTL is how long a tag name listing over the default window is reused.
+const tagNamesTTL = time.Minute
+
+// tagNamesCache caches tag name listings by datasource and scope. Tag names rarely change, and
+// they tend to be listed before each TraceQL query is written.
+var tagNamesCache = grafana.NewCache[any]("tempo_tag_names", tagNamesTTL)
+
 func newListTagNamesTool() mcp.Tool {
 	return mcp.NewTool(
 		"list_tempo_tag_names",
 		mcp.WithDescription("Lists all available tag names (attributes) in a Tempo datasource. "+
 			"Returns a list of tag name strings (e.g., [\"service.name\", \"http.method\", \"http.status_code\"]). "+
 			"Optionally filter by scope (resource, span, intrinsic). "+
-			"Defaults to the last hour if time range is not specified."),
+			"When no scope is given, returns an object mapping each scope to its tag names "+
+			"(e.g., {\"resource\": [\"service.name\"], \"span\": [\"http.method\"], \"intrinsic\": [\"duration\"]}) "+
+			"so resource-level and span-level tags can be told apart when writing TraceQL. "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified; listings over the default range are cached for a minute."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Tempo datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Tempo datasource to query; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
 		),
 		mcp.WithString("scope",
 			mcp.Description("Optional scope filter: 'resource', 'span', 'intrinsic', 'event', 'link', or 'instrumentation'"),
 		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
@@ -73,6 +116,6 @@ func newListTagNamesTool() mcp.Tool {
 }
 
 // RegisterListTagNames registers the list_tempo_tag_names tool.
-func RegisterListTagNames(s *server.MCPServer) {
+func RegisterListTagNames(s grafana.ToolRegistry) {
 	s.AddTool(newListTagNamesTool(), listTagNamesHandler)
 }
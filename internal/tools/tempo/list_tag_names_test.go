@@ -0,0 +1,91 @@
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestListTagNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		v2Status int
+		v2Body   string
+		want     any
+		wantErr  bool
+		wantV1   bool
+	}{
+		{
+			name:     "v2 groups by scope",
+			v2Status: http.StatusOK,
+			v2Body:   `{"scopes":[{"name":"resource","tags":["service.name"]},{"name":"span","tags":["http.method"]}]}`,
+			want:     map[string][]string{"resource": {"service.name"}, "span": {"http.method"}},
+		},
+		{
+			name:     "v2 missing falls back to v1",
+			v2Status: http.StatusNotFound,
+			v2Body:   "404 page not found",
+			want:     []string{"service.name", "http.method"},
+			wantV1:   true,
+		},
+		{
+			name:     "v2 failure is returned",
+			v2Status: http.StatusInternalServerError,
+			v2Body:   "querier unavailable",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1Called := false
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v2/search/tags":
+					w.WriteHeader(tt.v2Status)
+					_, _ = w.Write([]byte(tt.v2Body))
+				case "/api/search/tags":
+					v1Called = true
+					_, _ = w.Write([]byte(`{"tagNames":["service.name","http.method"]}`))
+				default:
+					t.Errorf("unexpected path %q", r.URL.Path)
+				}
+			}))
+			defer srv.Close()
+
+			c := &client{httpClient: srv.Client(), baseURL: srv.URL, datasourceUID: "tempo-" + tt.name}
+			got, err := c.listTagNames(context.Background(), "", "1", "2")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+			if v1Called != tt.wantV1 {
+				t.Errorf("v1 called = %v, want %v", v1Called, tt.wantV1)
+			}
+		})
+	}
+}
+
+func TestTagNamesCache(t *testing.T) {
+	if _, ok := tagNamesCache.Get("ds|"); ok {
+		t.Fatal("expected an empty cache")
+	}
+	tagNamesCache.Set("ds|", []string{"service.name"})
+	names, ok := tagNamesCache.Get("ds|")
+	if !ok || !reflect.DeepEqual(names, []string{"service.name"}) {
+		t.Errorf("got %v (%v), want the stored names", names, ok)
+	}
+	if _, ok := tagNamesCache.Get("ds|span"); ok {
+		t.Error("expected a miss for another scope")
+	}
+}
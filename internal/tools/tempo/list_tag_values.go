@@ -2,18 +2,58 @@ package tempo
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type listTagValuesParams struct {
 	DatasourceUID string `json:"datasourceUid"`
 	TagName       string `json:"tagName"`
+	Query         string `json:"query,omitempty"`
 	StartRFC3339  string `json:"startRfc3339,omitempty"`
 	EndRFC3339    string `json:"endRfc3339,omitempty"`
+	Regex         string `json:"regex,omitempty"`
+	TypedValues   bool   `json:"typedValues,omitempty"`
+}
+
+// FilteredTypedValues is the typedValues output of list_tempo_tag_values narrowed by a regex.
+type FilteredTypedValues struct {
+	TotalCount   int             `json:"totalCount"` // Before filtering
+	MatchedCount int             `json:"matchedCount"`
+	Values       []TypedTagValue `json:"values"`
+}
+
+// typedValuesResult returns typed tag values, filtered on their value when regex is set.
+func typedValuesResult(values []TypedTagValue, regex string) (any, error) {
+	if values == nil {
+		values = []TypedTagValue{}
+	}
+	if regex == "" {
+		return values, nil
+	}
+
+	strs := make([]string, len(values))
+	for i, tv := range values {
+		strs[i] = tv.Value
+	}
+	matched, err := grafana.FilterByRegex(strs, regex)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(matched))
+	for _, v := range matched {
+		keep[v] = true
+	}
+
+	filtered := []TypedTagValue{}
+	for _, tv := range values {
+		if keep[tv.Value] {
+			filtered = append(filtered, tv)
+		}
+	}
+	return FilteredTypedValues{TotalCount: len(values), MatchedCount: len(filtered), Values: filtered}, nil
 }
 
 func listTagValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -26,26 +66,64 @@ func listTagValuesHandler(ctx context.Context, request mcp.CallToolRequest) (*mc
 		return mcp.NewToolResultError("tagName is required"), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
 	}
 
-	startUnix, endUnix, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startUnix, endUnix, notice, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	tagValues, err := c.fetchTagValues(ctx, params.TagName, startUnix, endUnix)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	if params.TypedValues {
+		typed, err := c.fetchTagValuesV2(ctx, params.TagName, params.Query, startUnix, endUnix)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		output, err := typedValuesResult(typed, params.Regex)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(output, notice))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+
+	var tagValues []string
+	if params.Query != "" {
+		// Scoped lookups need the v2 endpoint, which accepts a TraceQL filter
+		typed, err := c.fetchTagValuesV2(ctx, params.TagName, params.Query, startUnix, endUnix)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, tv := range typed {
+			tagValues = append(tagValues, tv.Value)
+		}
+	} else {
+		tagValues, err = c.fetchTagValues(ctx, params.TagName, startUnix, endUnix)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
 
 	if len(tagValues) == 0 {
 		tagValues = []string{}
 	}
 
-	jsonData, err := json.MarshalIndent(tagValues, "", "  ")
+	var output any = tagValues
+	if params.Regex != "" {
+		filtered, err := grafana.FilterByRegex(tagValues, params.Regex)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		output = grafana.FilteredValues{TotalCount: len(tagValues), MatchedCount: len(filtered), Values: filtered}
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WithWarnings(output, notice))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -58,17 +136,30 @@ func newListTagValuesTool() mcp.Tool {
 		"list_tempo_tag_values",
 		mcp.WithDescription("Retrieves all unique values for a specific tag name in a Tempo datasource. "+
 			"Returns a list of string values (e.g., for tagName=\"service.name\", might return [\"api-gateway\", \"user-service\"]). "+
-			"Defaults to the last hour if time range is not specified."),
+			"Optionally scope the values with a TraceQL filter via query (e.g., values of http.route for traces where service.name=\"api\"). "+
+			"With regex, returns {totalCount, matchedCount, values} where totalCount is the count before filtering. "+
+			"Set typedValues to get {value, type} pairs instead (e.g., type 'int' for http.status_code), "+
+			"which tells whether a TraceQL comparison should be numeric ('>= 400') or a string match ('=~\"4..\"'). "+
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Tempo datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Tempo datasource to query; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
 		),
 		mcp.WithString("tagName",
 			mcp.Description("The tag name to get values for (e.g., \"service.name\", \"http.method\")"),
 			mcp.Required(),
 		),
+		mcp.WithString("query",
+			mcp.Description("Optional TraceQL filter restricting which spans contribute values (e.g., '{resource.service.name=\"api\"}'). Uses Tempo's v2 tag values API, which expects a scoped tagName such as 'span.http.route' or 'resource.service.name'."),
+		),
+		mcp.WithString("regex",
+			mcp.Description("Optional regex to filter the values client-side (e.g., '/api/v1/.*')"),
+		),
+		mcp.WithBoolean("typedValues",
+			mcp.Description("Return {value, type} pairs from Tempo's v2 tag values API instead of plain strings (default: false). "+
+				"The v2 API expects a scoped tagName such as 'span.http.status_code'"),
+		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
@@ -77,6 +168,6 @@ func newListTagValuesTool() mcp.Tool {
 }
 
 // RegisterListTagValues registers the list_tempo_tag_values tool.
-func RegisterListTagValues(s *server.MCPServer) {
+func RegisterListTagValues(s grafana.ToolRegistry) {
 	s.AddTool(newListTagValuesTool(), listTagValuesHandler)
 }
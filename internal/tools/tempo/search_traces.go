@@ -2,19 +2,31 @@ package tempo
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 type searchTracesParams struct {
-	DatasourceUID string `json:"datasourceUid"`
-	Query         string `json:"query,omitempty"`
-	StartRFC3339  string `json:"startRfc3339,omitempty"`
-	EndRFC3339    string `json:"endRfc3339,omitempty"`
-	Limit         int    `json:"limit,omitempty"`
+	DatasourceUID   string            `json:"datasourceUid"`
+	Query           string            `json:"query,omitempty"`
+	StartRFC3339    string            `json:"startRfc3339,omitempty"`
+	EndRFC3339      string            `json:"endRfc3339,omitempty"`
+	Limit           int               `json:"limit,omitempty"`
+	AutoShard       bool              `json:"autoShard,omitempty"`
+	Compact         bool              `json:"compact,omitempty"`
+	SpansPerSpanSet int               `json:"spansPerSpanset,omitempty"`
+	MinDuration     string            `json:"minDuration,omitempty"`
+	MaxDuration     string            `json:"maxDuration,omitempty"`
+	TimeoutSeconds  int               `json:"timeoutSeconds,omitempty"`
+	PrecheckHealth  *bool             `json:"precheckHealth,omitempty"`
+	DryRun          bool              `json:"dryRun,omitempty"`
+	TenantID        string            `json:"tenantId,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
 }
 
 func searchTracesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -23,24 +35,67 @@ func searchTracesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultError(fmt.Sprintf("invalid parameters: %v", err)), nil
 	}
 
-	c, err := newClient(params.DatasourceUID)
+	c, err := newClient(ctx, params.DatasourceUID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating Tempo client: %v", err)), nil
 	}
+	if c.headers, err = grafana.RequestHeaders(params.Headers, params.TenantID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	ctx, cancel := grafana.WithRequestTimeout(ctx, c.httpClient, params.TimeoutSeconds)
+	defer cancel()
+	if params.DryRun {
+		ctx = grafana.WithDryRun(ctx)
+	}
+
+	if err := grafana.PrecheckHealth(ctx, c.httpClient, c.datasourceUID, params.PrecheckHealth); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	startUnix, endUnix, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
+	startUnix, endUnix, notice, err := getDefaultTimeRange(params.StartRFC3339, params.EndRFC3339)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	limit := enforceTraceLimit(params.Limit)
 
-	searchResult, err := c.searchTraces(ctx, params.Query, startUnix, endUnix, limit)
+	if params.SpansPerSpanSet < 0 || params.SpansPerSpanSet > MaxSpansPerSpanSet {
+		return mcp.NewToolResultError(fmt.Sprintf("spansPerSpanset must be between 1 and %d", MaxSpansPerSpanSet)), nil
+	}
+	opts := searchOptions{SpansPerSpanSet: params.SpansPerSpanSet}
+	if opts.MinDuration, err = parseSearchDuration("minDuration", params.MinDuration); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if opts.MaxDuration, err = parseSearchDuration("maxDuration", params.MaxDuration); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if opts.MinDuration > 0 && opts.MaxDuration > 0 && opts.MinDuration > opts.MaxDuration {
+		return mcp.NewToolResultError("minDuration must not be greater than maxDuration"), nil
+	}
+
+	var searchResult *SearchResponse
+	if params.AutoShard {
+		searchResult, err = c.searchTracesSharded(ctx, params.Query, startUnix, endUnix, limit, opts)
+	} else {
+		searchResult, err = c.searchTraces(ctx, params.Query, startUnix, endUnix, limit, opts)
+	}
+	if params.DryRun {
+		return grafana.DryRunResult(ctx), nil
+	}
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	jsonData, err := json.MarshalIndent(searchResult, "", "  ")
+	if notice != "" {
+		searchResult.Warnings = append(searchResult.Warnings, notice)
+	}
+	searchResult.DurationSummary = summarizeDurations(searchResult.Traces)
+	if params.Compact {
+		compactTraces(searchResult.Traces)
+	}
+
+	jsonData, err := grafana.MarshalJSON(grafana.WrapResult(searchResult, len(searchResult.Traces)))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshalling result: %v", err)), nil
 	}
@@ -48,34 +103,137 @@ func searchTracesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
+// parseSearchDuration parses an optional duration param such as "500ms" or "2s"; empty means unset.
+func parseSearchDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q (expected a positive duration like 500ms, 2s, or 1m)", name, value)
+	}
+	return d, nil
+}
+
+// compactTraces drops the matched spanSets and per-service stats from search results,
+// leaving each trace's ID, root service and name, start time, and duration.
+func compactTraces(traces []TraceSearchResult) {
+	for i := range traces {
+		traces[i].SpanSets = nil
+		traces[i].ServiceStats = nil
+	}
+}
+
+// DurationSummary describes the latency distribution of a set of search results.
+type DurationSummary struct {
+	Count int `json:"count"`
+	MinMs int `json:"minMs"`
+	MaxMs int `json:"maxMs"`
+	P50Ms int `json:"p50Ms"`
+	P95Ms int `json:"p95Ms"`
+	P99Ms int `json:"p99Ms"`
+}
+
+// summarizeDurations computes min, max, and nearest-rank percentiles of trace durations.
+// Returns nil when there are no traces.
+func summarizeDurations(traces []TraceSearchResult) *DurationSummary {
+	if len(traces) == 0 {
+		return nil
+	}
+
+	durations := make([]int, len(traces))
+	for i, t := range traces {
+		durations[i] = t.DurationMs
+	}
+	sort.Ints(durations)
+
+	percentile := func(p float64) int {
+		rank := int(math.Ceil(p * float64(len(durations))))
+		if rank < 1 {
+			rank = 1
+		}
+		return durations[rank-1]
+	}
+
+	return &DurationSummary{
+		Count: len(durations),
+		MinMs: durations[0],
+		MaxMs: durations[len(durations)-1],
+		P50Ms: percentile(0.50),
+		P95Ms: percentile(0.95),
+		P99Ms: percentile(0.99),
+	}
+}
+
+// HandleSearchTraces runs the search_tempo_traces tool handler; the unified query tool routes TraceQL here.
+func HandleSearchTraces(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return searchTracesHandler(ctx, request)
+}
+
 func newSearchTracesTool() mcp.Tool {
 	return mcp.NewTool(
 		"search_tempo_traces",
 		mcp.WithDescription("Searches for traces in a Tempo datasource using TraceQL. "+
-			"Returns a list of matching traces with trace ID, root service name, root trace name, start time, and duration. "+
+			"Returns a list of matching traces with trace ID, root service name, root trace name, start time, and duration, "+
+			"plus a durationSummary (count, min, max, p50, p95, p99 in ms) across the returned traces. "+
 			"TraceQL examples: '{service.name=\"api-gateway\"}', '{http.status_code>=400}', '{duration>1s}'. "+
 			"If no query is provided, returns recent traces. "+
-			"Defaults to the last hour if time range is not specified."),
+			"Defaults to the last "+grafana.FormatDuration(defaultWindow)+" if time range is not specified."),
 		mcp.WithString("datasourceUid",
-			mcp.Description("The UID of the Tempo datasource to query"),
-			mcp.Required(),
+			mcp.Description("The UID of the Tempo datasource to query; defaults to GRAFANA_DEFAULT_TEMPO_UID or the default Tempo datasource"),
 		),
 		mcp.WithString("query",
 			mcp.Description("TraceQL query expression (e.g., '{service.name=\"api\"}', '{http.status_code>=400}'). If empty, returns recent traces."),
 		),
 		mcp.WithString("startRfc3339",
-			mcp.Description("Start time in RFC3339 format (defaults to 1 hour ago)"),
+			mcp.Description("Start time in RFC3339 format (defaults to "+grafana.FormatDuration(defaultWindow)+" ago)"),
 		),
 		mcp.WithString("endRfc3339",
 			mcp.Description("End time in RFC3339 format (defaults to now)"),
 		),
 		mcp.WithNumber("limit",
-			mcp.Description("Maximum number of traces to return (default: 20, max: 100)"),
+			mcp.Description(fmt.Sprintf("Maximum number of traces to return (default: %d, max: %d)", DefaultTraceLimit, maxTraceLimit)),
+		),
+		mcp.WithString("minDuration",
+			mcp.Description("Only return traces at least this long (e.g., '500ms', '2s'), filtered by Tempo"),
+		),
+		mcp.WithString("maxDuration",
+			mcp.Description("Only return traces at most this long (e.g., '5s'), filtered by Tempo"),
+		),
+		mcp.WithNumber("spansPerSpanset",
+			mcp.Description(fmt.Sprintf("Number of matched spans returned per trace in spanSets (Tempo's default: 3, max: %d). "+
+				"Raise it to see more of the matching spans in each trace", MaxSpansPerSpanSet)),
+		),
+		mcp.WithBoolean("compact",
+			mcp.Description("Return only each trace's traceID, rootServiceName, rootTraceName, startTimeUnixNano, and durationMs, "+
+				"dropping spanSets and serviceStats, which can make up most of the response. Use when you only need trace IDs and durations (default: false)"),
+		),
+		mcp.WithBoolean("autoShard",
+			mcp.Description(fmt.Sprintf("Split the time range into sub-windows (1 hour, or wider to stay within %d searches) and search them newest first, "+
+				"merging results until the limit is reached. A sub-window that fails twice ends the search with the traces found so far and a warning. "+
+				"Use for day-long or wider searches that would otherwise time out (default: false)", MaxShards)),
+		),
+		mcp.WithString("tenantId",
+			mcp.Description("Tenant to query in a multi-tenant Loki/Mimir/Tempo deployment, sent as the X-Scope-OrgID header"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Optional extra HTTP headers to send through the datasource proxy, e.g., {\"X-Scope-OrgID\": \"team-a\"}"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Instead of running the query, return the exact upstream request(s) it would send: "+
+				"method, URL, decoded params (including the converted start/end times), and headers with the token redacted (default: false)"),
+		),
+		mcp.WithBoolean("precheckHealth",
+			mcp.Description("Check the datasource health endpoint first and fail with a clear 'datasource is unhealthy' error instead of running the query "+
+				"(default: false, or true when GRAFANA_PRECHECK_HEALTH is set)"),
+		),
+		mcp.WithNumber("timeoutSeconds",
+			mcp.Description("Override the request timeout for this call, spanning all sub-windows when autoShard is set in seconds (default: the server-wide timeout, max: 300)"),
 		),
 	)
 }
 
 // RegisterSearchTraces registers the search_tempo_traces tool.
-func RegisterSearchTraces(s *server.MCPServer) {
+func RegisterSearchTraces(s grafana.ToolRegistry) {
 	s.AddTool(newSearchTracesTool(), searchTracesHandler)
 }
@@ -0,0 +1,85 @@
+package tempo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/krmcbride/mcp-grafana/internal/grafana"
+)
+
+const (
+	// DefaultShardSeconds is the sub-window size used when auto-sharding a search.
+	DefaultShardSeconds = 3600
+
+	// MaxShards caps how many sub-window searches a single auto-sharded search issues.
+	// Wider windows get proportionally larger sub-windows.
+	MaxShards = 48
+)
+
+// searchTracesSharded splits [startUnix, endUnix] into sub-windows and searches them
+// newest first, merging results and deduplicating by trace ID until limit traces are found.
+// Each sub-window is retried once. If the retry fails too, or ctx is done, the search stops and
+// returns the traces already collected with a warning naming the window it stopped at; it only
+// returns an error when no window succeeded, so there is nothing to keep.
+func (c *client) searchTracesSharded(ctx context.Context, query, startUnix, endUnix string, limit int, opts searchOptions) (*SearchResponse, error) {
+	start, err := strconv.ParseInt(startUnix, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing start time: %w", err)
+	}
+	end, err := strconv.ParseInt(endUnix, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing end time: %w", err)
+	}
+	if end <= start {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	shardSeconds := int64(DefaultShardSeconds)
+	if (end-start)/shardSeconds >= MaxShards {
+		shardSeconds = (end - start + MaxShards - 1) / MaxShards
+	}
+
+	merged := &SearchResponse{Traces: []TraceSearchResult{}, Metrics: &SearchMetrics{}}
+	seen := make(map[string]bool)
+
+	searched := 0
+	for shardEnd := end; shardEnd > start && len(merged.Traces) < limit; shardEnd -= shardSeconds {
+		shardStart := max(shardEnd-shardSeconds, start)
+		remaining := limit - len(merged.Traces)
+
+		err := ctx.Err()
+		var resp *SearchResponse
+		if err == nil {
+			resp, err = c.searchTraces(ctx, query, strconv.FormatInt(shardStart, 10), strconv.FormatInt(shardEnd, 10), remaining, opts)
+			// A dry run has nothing to retry, and a cancelled context would fail the retry the same way
+			if err != nil && ctx.Err() == nil && !errors.Is(err, grafana.ErrDryRun) {
+				resp, err = c.searchTraces(ctx, query, strconv.FormatInt(shardStart, 10), strconv.FormatInt(shardEnd, 10), remaining, opts)
+			}
+		}
+		if err != nil {
+			if searched == 0 {
+				return nil, fmt.Errorf("searching window %d-%d: %w", shardStart, shardEnd, err)
+			}
+			merged.Warnings = append(merged.Warnings, fmt.Sprintf("stopped at window %d-%d (Unix seconds) after a retry: %v; "+
+				"traces between %d and %d were not searched, so results may be incomplete", shardStart, shardEnd, err, start, shardEnd))
+			break
+		}
+		searched++
+
+		for _, trace := range resp.Traces {
+			if seen[trace.TraceID] || len(merged.Traces) >= limit {
+				continue
+			}
+			seen[trace.TraceID] = true
+			merged.Traces = append(merged.Traces, trace)
+		}
+		if resp.Metrics != nil {
+			merged.Metrics.InspectedTraces += resp.Metrics.InspectedTraces
+			merged.Metrics.InspectedBytes += resp.Metrics.InspectedBytes
+		}
+	}
+
+	return merged, nil
+}
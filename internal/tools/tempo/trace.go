@@ -0,0 +1,463 @@
+package tempo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// otlpAnyValue represents an OTLP attribute value; exactly one field is set.
+// int64 values are serialized as strings per the protobuf JSON mapping.
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	ArrayValue  *struct {
+		Values []otlpAnyValue `json:"values"`
+	} `json:"arrayValue,omitempty"`
+}
+
+// value converts the OTLP value to a plain Go value.
+func (v otlpAnyValue) value() any {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		if i, err := strconv.ParseInt(*v.IntValue, 10, 64); err == nil {
+			return i
+		}
+		return *v.IntValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.ArrayValue != nil:
+		values := make([]any, 0, len(v.ArrayValue.Values))
+		for _, item := range v.ArrayValue.Values {
+			values = append(values, item.value())
+		}
+		return values
+	}
+	return nil
+}
+
+// otlpKeyValue represents an OTLP attribute.
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// otlpEvent represents a span event, such as a log or a recorded exception.
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes"`
+}
+
+// otlpSpan represents a span in Tempo's OTLP JSON trace format.
+type otlpSpan struct {
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId"`
+	Name              string         `json:"name"`
+	Kind              string         `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Events            []otlpEvent    `json:"events"`
+	Status            struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+// otlpScopeSpans groups spans by instrumentation scope.
+type otlpScopeSpans struct {
+	Spans []otlpSpan `json:"spans"`
+}
+
+// otlpResourceSpans groups spans by the resource (service) that emitted them.
+// Older Tempo versions use instrumentationLibrarySpans instead of scopeSpans.
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans                  []otlpScopeSpans `json:"scopeSpans"`
+	InstrumentationLibrarySpans []otlpScopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+// otlpTrace represents the response from Tempo's trace by ID endpoint.
+type otlpTrace struct {
+	Batches       []otlpResourceSpans `json:"batches"`
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// SpanEvent is a decoded span event; exceptions carry exception.type and exception.message attributes.
+type SpanEvent struct {
+	Name       string         `json:"name"`
+	Timestamp  string         `json:"timestamp"` // RFC3339 with nanoseconds
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// TraceSpan is a decoded span positioned within its trace's span tree.
+type TraceSpan struct {
+	SpanID        string         `json:"spanId"`
+	ParentSpanID  string         `json:"parentSpanId,omitempty"`
+	Name          string         `json:"name"`
+	ServiceName   string         `json:"serviceName,omitempty"`
+	Kind          string         `json:"kind,omitempty"`
+	StartOffsetMs float64        `json:"startOffsetMs"` // Relative to the start of the trace
+	DurationMs    float64        `json:"durationMs"`
+	Status        string         `json:"status,omitempty"`
+	StatusMessage string         `json:"statusMessage,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Events        []SpanEvent    `json:"events,omitempty"`
+	Children      []*TraceSpan   `json:"children,omitempty"`
+	OmittedSpans  int            `json:"omittedSpans,omitempty"` // Descendants dropped by a depth limit
+
+	startNano int64
+	endNano   int64
+}
+
+// SpanTree is a trace decoded into linked spans.
+type SpanTree struct {
+	Roots     []*TraceSpan
+	Spans     []*TraceSpan
+	StartNano int64
+	EndNano   int64
+}
+
+// DurationMs returns the wall-clock duration of the whole trace.
+func (t *SpanTree) DurationMs() float64 {
+	return nanosToMs(t.EndNano - t.StartNano)
+}
+
+// Services returns the sorted names of the services that emitted spans in the trace.
+func (t *SpanTree) Services() []string {
+	seen := make(map[string]bool)
+	services := []string{}
+	for _, span := range t.Spans {
+		if span.ServiceName != "" && !seen[span.ServiceName] {
+			seen[span.ServiceName] = true
+			services = append(services, span.ServiceName)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// fetchSpanTree retrieves a trace by ID and decodes it into a span tree.
+func (c *client) fetchSpanTree(ctx context.Context, traceID string) (*SpanTree, error) {
+	path := fmt.Sprintf("/api/traces/%s", url.PathEscape(traceID))
+	bodyBytes, err := c.makeRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeTrace(traceID, bodyBytes)
+}
+
+// DecodeTrace decodes a Tempo trace by ID response (OTLP JSON, with either batches or resourceSpans)
+// into a span tree. It is the one OTLP decoder for traces, shared with packages such as drilldown
+// that read Tempo traces through their own clients. A trace without spans is an error.
+func DecodeTrace(traceID string, body []byte) (*SpanTree, error) {
+	var trace otlpTrace
+	if err := json.Unmarshal(body, &trace); err != nil {
+		return nil, fmt.Errorf("unmarshalling trace response: %w", err)
+	}
+
+	tree := buildSpanTree(&trace)
+	if len(tree.Spans) == 0 {
+		return nil, fmt.Errorf("trace %s contains no spans", traceID)
+	}
+
+	return tree, nil
+}
+
+// buildSpanTree decodes OTLP spans and links children to parents.
+// Spans whose parent is missing from the trace are treated as roots.
+func buildSpanTree(trace *otlpTrace) *SpanTree {
+	batches := trace.Batches
+	if len(batches) == 0 {
+		batches = trace.ResourceSpans
+	}
+
+	tree := &SpanTree{}
+	byID := make(map[string]*TraceSpan)
+
+	for _, batch := range batches {
+		serviceName := ""
+		for _, attr := range batch.Resource.Attributes {
+			if attr.Key == "service.name" {
+				if s, ok := attr.Value.value().(string); ok {
+					serviceName = s
+				}
+				break
+			}
+		}
+
+		scopes := batch.ScopeSpans
+		if len(scopes) == 0 {
+			scopes = batch.InstrumentationLibrarySpans
+		}
+
+		for _, scope := range scopes {
+			for _, raw := range scope.Spans {
+				span := &TraceSpan{
+					SpanID:        decodeSpanID(raw.SpanID),
+					ParentSpanID:  decodeSpanID(raw.ParentSpanID),
+					Name:          raw.Name,
+					ServiceName:   serviceName,
+					Kind:          raw.Kind,
+					Status:        raw.Status.Code,
+					StatusMessage: raw.Status.Message,
+				}
+				span.startNano, _ = strconv.ParseInt(raw.StartTimeUnixNano, 10, 64)
+				span.endNano, _ = strconv.ParseInt(raw.EndTimeUnixNano, 10, 64)
+
+				if len(raw.Attributes) > 0 {
+					span.Attributes = make(map[string]any, len(raw.Attributes))
+					for _, attr := range raw.Attributes {
+						span.Attributes[attr.Key] = attr.Value.value()
+					}
+				}
+
+				for _, event := range raw.Events {
+					span.Events = append(span.Events, decodeEvent(event))
+				}
+
+				tree.Spans = append(tree.Spans, span)
+				byID[span.SpanID] = span
+			}
+		}
+	}
+
+	for _, span := range tree.Spans {
+		if span.startNano > 0 && (tree.StartNano == 0 || span.startNano < tree.StartNano) {
+			tree.StartNano = span.startNano
+		}
+		if span.endNano > tree.EndNano {
+			tree.EndNano = span.endNano
+		}
+
+		if parent, ok := byID[span.ParentSpanID]; ok && span.ParentSpanID != "" && parent != span {
+			parent.Children = append(parent.Children, span)
+		} else {
+			tree.Roots = append(tree.Roots, span)
+		}
+	}
+
+	for _, span := range tree.Spans {
+		span.StartOffsetMs = nanosToMs(span.startNano - tree.StartNano)
+		span.DurationMs = nanosToMs(span.endNano - span.startNano)
+		sort.Slice(span.Children, func(i, j int) bool {
+			return span.Children[i].startNano < span.Children[j].startNano
+		})
+	}
+	sort.Slice(tree.Roots, func(i, j int) bool {
+		return tree.Roots[i].startNano < tree.Roots[j].startNano
+	})
+
+	return tree
+}
+
+// decodeEvent converts an OTLP span event, formatting its timestamp as RFC3339.
+func decodeEvent(raw otlpEvent) SpanEvent {
+	event := SpanEvent{Name: raw.Name, Timestamp: raw.TimeUnixNano}
+	if nanos, err := strconv.ParseInt(raw.TimeUnixNano, 10, 64); err == nil {
+		event.Timestamp = time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+	}
+	if len(raw.Attributes) > 0 {
+		event.Attributes = make(map[string]any, len(raw.Attributes))
+		for _, attr := range raw.Attributes {
+			event.Attributes[attr.Key] = attr.Value.value()
+		}
+	}
+	return event
+}
+
+// criticalPath walks from the longest root down to a leaf, at each step following the child
+// that finished last — the one its parent was still waiting on.
+func (t *SpanTree) criticalPath() []*TraceSpan {
+	if len(t.Roots) == 0 {
+		return nil
+	}
+
+	current := t.Roots[0]
+	for _, root := range t.Roots[1:] {
+		if root.endNano-root.startNano > current.endNano-current.startNano {
+			current = root
+		}
+	}
+
+	var path []*TraceSpan
+	for current != nil {
+		path = append(path, current)
+
+		var next *TraceSpan
+		for _, child := range current.Children {
+			if next == nil || child.endNano > next.endNano {
+				next = child
+			}
+		}
+		current = next
+	}
+
+	return path
+}
+
+// selfTimeNano returns the time span spent outside its children: its duration minus the union of its
+// children's intervals, clipped to the span, so concurrent children are not subtracted twice.
+func (span *TraceSpan) selfTimeNano() int64 {
+	type interval struct{ start, end int64 }
+	intervals := make([]interval, 0, len(span.Children))
+	for _, child := range span.Children {
+		start, end := max(child.startNano, span.startNano), min(child.endNano, span.endNano)
+		if end > start {
+			intervals = append(intervals, interval{start, end})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start < intervals[j].start })
+
+	var covered, coveredEnd int64
+	for _, iv := range intervals {
+		if iv.start > coveredEnd {
+			covered += iv.end - iv.start
+			coveredEnd = iv.end
+		} else if iv.end > coveredEnd {
+			covered += iv.end - coveredEnd
+			coveredEnd = iv.end
+		}
+	}
+
+	return max(span.endNano-span.startNano-covered, 0)
+}
+
+// truncateSpans returns copies of spans whose subtrees stop at maxDepth levels (1 keeps only the spans themselves).
+// Cut-off subtrees are replaced by a count in OmittedSpans. Attributes and events are dropped when withAttributes is false.
+func truncateSpans(spans []*TraceSpan, maxDepth int, withAttributes bool) []*TraceSpan {
+	truncated := make([]*TraceSpan, 0, len(spans))
+	for _, span := range spans {
+		spanCopy := *span
+		spanCopy.Children = nil
+		if !withAttributes {
+			spanCopy.Attributes = nil
+			spanCopy.Events = nil
+		}
+		if maxDepth > 1 {
+			spanCopy.Children = truncateSpans(span.Children, maxDepth-1, withAttributes)
+		} else {
+			spanCopy.OmittedSpans = countDescendants(span)
+		}
+		truncated = append(truncated, &spanCopy)
+	}
+	return truncated
+}
+
+// pruneSpans returns copies of the root spans keeping at most maxSpans spans, chosen in priority order:
+// the critical path, then error spans, then the longest remaining spans. A span is only kept with all of
+// its ancestors, so the result stays a connected tree. Each kept span's OmittedSpans counts the dropped
+// spans below it; the second return value is the total number of spans dropped.
+func (t *SpanTree) pruneSpans(maxSpans int) ([]*TraceSpan, int) {
+	parents := make(map[*TraceSpan]*TraceSpan, len(t.Spans))
+	for _, span := range t.Spans {
+		for _, child := range span.Children {
+			parents[child] = span
+		}
+	}
+
+	kept := make(map[*TraceSpan]bool, maxSpans)
+	// keep adds span with any ancestors not yet kept, if they all fit. Malformed traces can have parent
+	// cycles (A's parent is B, B's parent is A), so the walk stops at a span it has already visited.
+	keep := func(span *TraceSpan) {
+		var chain []*TraceSpan
+		visited := make(map[*TraceSpan]bool)
+		for s := span; s != nil && !kept[s] && !visited[s]; s = parents[s] {
+			visited[s] = true
+			chain = append(chain, s)
+		}
+		if len(kept)+len(chain) > maxSpans {
+			return
+		}
+		for _, s := range chain {
+			kept[s] = true
+		}
+	}
+
+	for _, span := range t.criticalPath() {
+		keep(span)
+	}
+	for _, span := range t.Spans {
+		if span.Status == statusCodeError {
+			keep(span)
+		}
+	}
+	byDuration := make([]*TraceSpan, len(t.Spans))
+	copy(byDuration, t.Spans)
+	sort.SliceStable(byDuration, func(i, j int) bool {
+		return byDuration[i].endNano-byDuration[i].startNano > byDuration[j].endNano-byDuration[j].startNano
+	})
+	for _, span := range byDuration {
+		if len(kept) >= maxSpans {
+			break
+		}
+		keep(span)
+	}
+
+	var copyKept func(spans []*TraceSpan) ([]*TraceSpan, int)
+	copyKept = func(spans []*TraceSpan) ([]*TraceSpan, int) {
+		copies := []*TraceSpan{}
+		omitted := 0
+		for _, span := range spans {
+			if !kept[span] {
+				omitted += 1 + countDescendants(span)
+				continue
+			}
+			spanCopy := *span
+			spanCopy.Children, spanCopy.OmittedSpans = copyKept(span.Children)
+			if len(spanCopy.Children) == 0 {
+				spanCopy.Children = nil
+			}
+			copies = append(copies, &spanCopy)
+		}
+		return copies, omitted
+	}
+
+	roots, _ := copyKept(t.Roots)
+	return roots, len(t.Spans) - len(kept)
+}
+
+// countDescendants returns the number of spans below span in the tree.
+func countDescendants(span *TraceSpan) int {
+	count := 0
+	for _, child := range span.Children {
+		count += 1 + countDescendants(child)
+	}
+	return count
+}
+
+// decodeSpanID converts Tempo's base64-encoded span IDs to the familiar hex form.
+// IDs that are already hex (or undecodable) are returned unchanged.
+func decodeSpanID(id string) string {
+	if id == "" {
+		return ""
+	}
+	if _, err := hex.DecodeString(id); err == nil && len(id) == 16 {
+		return id
+	}
+	if b, err := base64.StdEncoding.DecodeString(id); err == nil {
+		return hex.EncodeToString(b)
+	}
+	return id
+}
+
+// nanosToMs converts a nanosecond duration to fractional milliseconds.
+func nanosToMs(nanos int64) float64 {
+	return float64(nanos) / float64(time.Millisecond)
+}
@@ -0,0 +1,118 @@
+package tempo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeTrace(t *testing.T) {
+	// Two services, the child span's parent referenced by base64 ID as Tempo returns it
+	const resourceSpans = `{"resourceSpans":[
+		{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"frontend"}}]},
+		 "scopeSpans":[{"spans":[{"spanId":"AAAAAAAAAAE=","name":"GET /","startTimeUnixNano":"1000000000","endTimeUnixNano":"1500000000"}]}]},
+		{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"backend"}}]},
+		 "scopeSpans":[{"spans":[{"spanId":"AAAAAAAAAAI=","parentSpanId":"AAAAAAAAAAE=","name":"query","startTimeUnixNano":"1100000000","endTimeUnixNano":"1400000000",
+		   "attributes":[{"key":"db.rows","value":{"intValue":"42"}}]}]}]}
+	]}`
+
+	// Older Tempo versions use batches and instrumentationLibrarySpans
+	const batches = `{"batches":[
+		{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"frontend"}}]},
+		 "instrumentationLibrarySpans":[{"spans":[{"spanId":"0000000000000001","name":"GET /","startTimeUnixNano":"1000000000","endTimeUnixNano":"1500000000"}]}]}
+	]}`
+
+	t.Run("resourceSpans", func(t *testing.T) {
+		tree, err := DecodeTrace("abc", []byte(resourceSpans))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(tree.Spans) != 2 || len(tree.Roots) != 1 {
+			t.Fatalf("got %d spans and %d roots, want 2 and 1", len(tree.Spans), len(tree.Roots))
+		}
+		root := tree.Roots[0]
+		if root.SpanID != "0000000000000001" || root.ServiceName != "frontend" {
+			t.Errorf("root = %s from %s, want 0000000000000001 from frontend", root.SpanID, root.ServiceName)
+		}
+		if len(root.Children) != 1 || root.Children[0].Attributes["db.rows"] != int64(42) {
+			t.Errorf("child not linked with decoded attributes: %+v", root.Children)
+		}
+		if got := tree.Services(); !reflect.DeepEqual(got, []string{"backend", "frontend"}) {
+			t.Errorf("services = %v", got)
+		}
+		if got := tree.DurationMs(); got != 500 {
+			t.Errorf("durationMs = %v, want 500", got)
+		}
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		tree, err := DecodeTrace("abc", []byte(batches))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(tree.Spans) != 1 || tree.Roots[0].Name != "GET /" {
+			t.Errorf("unexpected tree: %+v", tree.Spans)
+		}
+	})
+
+	t.Run("no spans", func(t *testing.T) {
+		if _, err := DecodeTrace("abc", []byte(`{"batches":[]}`)); err == nil {
+			t.Error("expected an error for a trace without spans")
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if _, err := DecodeTrace("abc", []byte(`{`)); err == nil {
+			t.Error("expected an error for malformed JSON")
+		}
+	})
+}
+
+func TestPruneSpansParentCycle(t *testing.T) {
+	// Spans 2 and 3 name each other as parent, so neither is reachable from the root
+	const cycle = `{"batches":[
+		{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"frontend"}}]},
+		 "scopeSpans":[{"spans":[
+		   {"spanId":"0000000000000001","name":"root","startTimeUnixNano":"1000000000","endTimeUnixNano":"1500000000"},
+		   {"spanId":"0000000000000002","parentSpanId":"0000000000000003","name":"a","startTimeUnixNano":"1100000000","endTimeUnixNano":"1900000000"},
+		   {"spanId":"0000000000000003","parentSpanId":"0000000000000002","name":"b","startTimeUnixNano":"1200000000","endTimeUnixNano":"1800000000"}
+		 ]}]}
+	]}`
+
+	tree, err := DecodeTrace("abc", []byte(cycle))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan struct{})
+	var roots []*TraceSpan
+	go func() {
+		defer close(done)
+		roots, _ = tree.pruneSpans(2)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pruneSpans did not return on a parent cycle")
+	}
+
+	if len(roots) != 1 || roots[0].Name != "root" {
+		t.Errorf("roots = %+v, want only the root span", roots)
+	}
+}
+
+func TestDistinctTraceIDs(t *testing.T) {
+	got, err := distinctTraceIDs([]string{"abc", " def ", "abc", "def"})
+	if err != nil {
+		t.Fatalf("distinctTraceIDs() error: %v", err)
+	}
+	if want := []string{"abc", "def"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("distinctTraceIDs() = %v, want %v", got, want)
+	}
+
+	for _, ids := range [][]string{{""}, {"abc", "  "}} {
+		if _, err := distinctTraceIDs(ids); err == nil {
+			t.Errorf("distinctTraceIDs(%q) = nil error, want an error for the blank ID", ids)
+		}
+	}
+}